@@ -26,25 +26,7 @@ This command:
 4. Generates Bicep infrastructure
 5. Creates project documentation`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runQuickAction(cmd, "init", `Analyze this project directory and help me initialize it for Azure deployment.
-
-1. Scan all subdirectories to detect:
-   - Programming languages and frameworks
-   - Service types (API, web app, worker, etc.)
-   - Existing configuration files
-   - Database dependencies
-
-2. Suggest an azure.yaml configuration with:
-   - Appropriate Azure services for each component
-   - Container Apps for APIs
-   - Static Web Apps for frontends
-   - Appropriate database services
-
-3. Generate Bicep infrastructure files
-
-4. Create a README.md with deployment instructions
-
-Start by analyzing the current directory structure.`)
+			return runQuickAction(cmd, "init", initPrompt())
 		},
 	}
 }
@@ -64,29 +46,7 @@ Focus areas:
   performance - Performance bottlenecks, inefficiencies
   quality     - Code quality, maintainability, best practices`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			prompt := fmt.Sprintf(`Perform a thorough code review of this project.
-
-Focus area: %s
-
-Review the code for:
-1. Security vulnerabilities (secrets, injection, auth issues)
-2. Performance bottlenecks
-3. Code quality and maintainability
-4. Azure best practices
-5. Error handling and edge cases
-
-For each issue found:
-- Describe the problem
-- Explain the risk/impact
-- Provide a specific fix
-
-Start by exploring the codebase structure, then dive into the code.`, focus)
-
-			if path != "" && path != "." {
-				prompt += fmt.Sprintf("\n\nFocus on the path: %s", path)
-			}
-
-			return runQuickAction(cmd, "review", prompt)
+			return runQuickAction(cmd, "review", reviewPrompt(path, focus))
 		},
 	}
 
@@ -110,18 +70,7 @@ This command:
 4. Applies fixes
 5. Re-runs until passing`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runQuickAction(cmd, "fix", `Fix all build errors and test failures in this project.
-
-1. First, detect the project type and find the build/test commands
-2. Run the build command and capture any errors
-3. For each error:
-   - Analyze the root cause
-   - Apply the fix
-   - Verify the fix works
-4. Run tests and fix any failures
-5. Continue until build succeeds and all tests pass
-
-Be thorough - fix one issue at a time and verify before moving on.`)
+			return runQuickAction(cmd, "fix", fixPrompt())
 		},
 	}
 }
@@ -140,37 +89,7 @@ Focus areas:
   performance - Improve application performance
   both        - Optimize both cost and performance`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			prompt := fmt.Sprintf(`Optimize this Azure application for %s.
-
-`, focus)
-
-			if focus == "cost" || focus == "both" {
-				prompt += `For COST optimization:
-1. Analyze the Bicep/infrastructure files
-2. Identify over-provisioned resources
-3. Suggest right-sizing (smaller SKUs, reserved instances)
-4. Find unused or orphaned resources
-5. Recommend auto-scaling configurations
-6. Calculate estimated monthly savings
-
-`
-			}
-
-			if focus == "performance" || focus == "both" {
-				prompt += `For PERFORMANCE optimization:
-1. Review application code for bottlenecks
-2. Check database queries and indexes
-3. Analyze caching opportunities
-4. Review network configuration
-5. Check for N+1 query problems
-6. Suggest async/parallel processing improvements
-
-`
-			}
-
-			prompt += `Provide specific, actionable recommendations with code changes.`
-
-			return runQuickAction(cmd, "optimize", prompt)
+			return runQuickAction(cmd, "optimize", optimizePrompt(focus))
 		},
 	}
 
@@ -195,7 +114,116 @@ This command helps identify and fix:
 - Connectivity issues
 - Configuration problems`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			prompt := `Diagnose issues with this Azure deployment.
+			return runQuickAction(cmd, "diagnose", diagnosePrompt(resource, logs))
+		},
+	}
+
+	cmd.Flags().StringVar(&resource, "resource", "", "Specific resource to diagnose")
+	cmd.Flags().BoolVar(&logs, "logs", false, "Include log analysis")
+
+	return cmd
+}
+
+// Quick action prompts are shared by the CLI commands above and the MCP
+// prompts registered in mcp_prompts.go
+
+func initPrompt() string {
+	return `Analyze this project directory and help me initialize it for Azure deployment.
+
+1. Scan all subdirectories to detect:
+   - Programming languages and frameworks
+   - Service types (API, web app, worker, etc.)
+   - Existing configuration files
+   - Database dependencies
+
+2. Suggest an azure.yaml configuration with:
+   - Appropriate Azure services for each component
+   - Container Apps for APIs
+   - Static Web Apps for frontends
+   - Appropriate database services
+
+3. Generate Bicep infrastructure files
+
+4. Create a README.md with deployment instructions
+
+Start by analyzing the current directory structure.`
+}
+
+func reviewPrompt(path, focus string) string {
+	prompt := fmt.Sprintf(`Perform a thorough code review of this project.
+
+Focus area: %s
+
+Review the code for:
+1. Security vulnerabilities (secrets, injection, auth issues)
+2. Performance bottlenecks
+3. Code quality and maintainability
+4. Azure best practices
+5. Error handling and edge cases
+
+For each issue found:
+- Describe the problem
+- Explain the risk/impact
+- Provide a specific fix
+
+Start by exploring the codebase structure, then dive into the code.`, focus)
+
+	if path != "" && path != "." {
+		prompt += fmt.Sprintf("\n\nFocus on the path: %s", path)
+	}
+	return prompt
+}
+
+func fixPrompt() string {
+	return `Fix all build errors and test failures in this project.
+
+1. First, detect the project type and find the build/test commands
+2. Run the build command and capture any errors
+3. For each error:
+   - Analyze the root cause
+   - Apply the fix
+   - Verify the fix works
+4. Run tests and fix any failures
+5. Continue until build succeeds and all tests pass
+
+Be thorough - fix one issue at a time and verify before moving on.`
+}
+
+func optimizePrompt(focus string) string {
+	prompt := fmt.Sprintf(`Optimize this Azure application for %s.
+
+`, focus)
+
+	if focus == "cost" || focus == "both" {
+		prompt += `For COST optimization:
+1. Analyze the Bicep/infrastructure files
+2. Identify over-provisioned resources
+3. Suggest right-sizing (smaller SKUs, reserved instances)
+4. Find unused or orphaned resources
+5. Recommend auto-scaling configurations
+6. Calculate estimated monthly savings
+
+`
+	}
+
+	if focus == "performance" || focus == "both" {
+		prompt += `For PERFORMANCE optimization:
+1. Review application code for bottlenecks
+2. Check database queries and indexes
+3. Analyze caching opportunities
+4. Review network configuration
+5. Check for N+1 query problems
+6. Suggest async/parallel processing improvements
+
+`
+	}
+
+	prompt += `Provide specific, actionable recommendations with code changes.`
+	return prompt
+}
+
+func diagnosePrompt(resource string, logs bool) string {
+	prompt := `Diagnose issues with this Azure deployment.
 
 1. Check the deployment status and identify any failures
 2. Analyze error messages and logs
@@ -204,26 +232,18 @@ This command helps identify and fix:
 5. Verify the fix resolves the issue
 
 `
-			if resource != "" {
-				prompt += fmt.Sprintf("Focus on resource: %s\n", resource)
-			}
+	if resource != "" {
+		prompt += fmt.Sprintf("Focus on resource: %s\n", resource)
+	}
 
-			if logs {
-				prompt += `Include log analysis:
+	if logs {
+		prompt += `Include log analysis:
 - Check application logs
 - Review Azure Monitor logs
 - Analyze Container App/App Service logs
 `
-			}
-
-			return runQuickAction(cmd, "diagnose", prompt)
-		},
 	}
-
-	cmd.Flags().StringVar(&resource, "resource", "", "Specific resource to diagnose")
-	cmd.Flags().BoolVar(&logs, "logs", false, "Include log analysis")
-
-	return cmd
+	return prompt
 }
 
 // runQuickAction executes a quick action by launching Copilot with a specific prompt
@@ -240,8 +260,9 @@ func runQuickAction(cmd *cobra.Command, action string, prompt string) error {
 	}
 
 	// Launch Copilot with the specific prompt
-	return copilot.Launch(cmd.Context(), copilot.Options{
-		Prompt: prompt,
-		Agent:  "azure-manager",
+	return launchSession(cmd.Context(), copilot.Options{
+		Command: cmd.CommandPath(),
+		Prompt:  prompt,
+		Agent:   "azure-manager",
 	})
 }
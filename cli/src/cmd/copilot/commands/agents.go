@@ -5,6 +5,7 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/jongio/azd-copilot/cli/src/internal/assets"
@@ -14,7 +15,7 @@ import (
 )
 
 // NewAgentsCommand creates the 'agents' subcommand for listing and managing Azure agents.
-func NewAgentsCommand() *cobra.Command {
+func NewAgentsCommand(outputFormat *string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "agents",
 		Short: "List and manage Azure agents",
@@ -36,6 +37,10 @@ Each agent is an expert in a specific domain of Azure development:
 
 	cmd.AddCommand(newAgentsListCommand())
 	cmd.AddCommand(newAgentsShowCommand())
+	cmd.AddCommand(newValidateCommand(outputFormat, "agents", assets.ValidateAgents))
+	cmd.AddCommand(newAgentsGraphCommand(outputFormat))
+	cmd.AddCommand(newToggleCommand("agent", true, assets.EnableAgent))
+	cmd.AddCommand(newToggleCommand("agent", false, assets.DisableAgent))
 
 	return cmd
 }
@@ -61,8 +66,70 @@ func newAgentsShowCommand() *cobra.Command {
 	}
 }
 
+func newAgentsGraphCommand(outputFormat *string) *cobra.Command {
+	var (
+		format   string
+		noSkills bool
+		strict   bool
+	)
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Show the agent delegation graph and check it for consistency",
+		Long: `Parse agent bodies for delegations (task calls, "Agent" table columns, and
+"delegate to ` + "`name`" + `" prose) and skill references ("Skill" table columns and
+skill calls), then print the graph as Mermaid, DOT, or JSON.
+
+Issues are printed to stderr so the graph can be redirected to a file:
+  orphan-agent    no agent delegates to it (except ` + assets.EntryAgent + `)
+  cycle           agents delegate to each other in a loop
+  missing-agent   delegation to an agent that does not exist
+  missing-skill   reference to a skill that does not exist
+  unused-skill    no agent mentions the skill`,
+		Example: `  azd copilot agents graph > agents.mmd
+  azd copilot agents graph --format dot | dot -Tsvg > agents.svg
+  azd copilot agents graph --no-skills --strict`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g, err := assets.BuildAgentGraph("")
+			if err != nil {
+				return fmt.Errorf("failed to build agent graph: %w", err)
+			}
+
+			if *outputFormat == "json" {
+				format = "json"
+			}
+			switch format {
+			case "mermaid":
+				fmt.Print(g.Mermaid(!noSkills))
+			case "dot":
+				fmt.Print(g.DOT(!noSkills))
+			case "json":
+				if err := cliout.PrintJSON(g); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("invalid --format %q (use mermaid, dot, or json)", format)
+			}
+
+			if format != "json" {
+				for _, issue := range g.Issues {
+					fmt.Fprintf(os.Stderr, "%s: %s\n", issue.Kind, issue.Message)
+				}
+			}
+			if strict && len(g.Issues) > 0 {
+				return fmt.Errorf("agent graph has %d issues", len(g.Issues))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "mermaid", "Graph format: mermaid, dot, or json")
+	cmd.Flags().BoolVar(&noSkills, "no-skills", false, "Show only agent delegations")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Exit with an error when any issue is found")
+	return cmd
+}
+
 func listAgents() error {
-	agents, err := assets.ListAgents()
+	agents, err := assets.ListLayeredAgents("")
 	if err != nil {
 		return fmt.Errorf("failed to list agents: %w", err)
 	}
@@ -70,12 +137,11 @@ func listAgents() error {
 	cliout.Section("🤖", fmt.Sprintf("Available Agents (%d)", len(agents)))
 	cliout.Newline()
 
-	// Calculate max name length for alignment
-	maxLen := 0
+	// Calculate max name and layer length for alignment
+	maxLen, maxLayer := 0, 0
 	for _, agent := range agents {
-		if len(agent.Name) > maxLen {
-			maxLen = len(agent.Name)
-		}
+		maxLen = max(maxLen, len(agent.Name))
+		maxLayer = max(maxLayer, len(stateLabel(agent.Layer, agent.Shadows, agent.Merged, agent.Disabled)))
 	}
 
 	// Print agents
@@ -84,15 +150,43 @@ func listAgents() error {
 		if desc == "" {
 			desc = "(no description)"
 		}
-		fmt.Printf("  %s%-*s%s  %s\n", cliout.Cyan, maxLen, agent.Name, cliout.Reset, desc)
+		color := cliout.Cyan
+		if agent.Disabled {
+			color = cliout.Gray
+		}
+		fmt.Printf("  %s%-*s%s  %-*s  %s\n", color, maxLen, agent.Name, cliout.Reset,
+			maxLayer, stateLabel(agent.Layer, agent.Shadows, agent.Merged, agent.Disabled), desc)
 	}
 
 	cliout.Newline()
 	cliout.Hint("Use 'azd copilot run --agent <name>' to use a specific agent")
+	cliout.Hint("Override agents in ~/.azd/copilot/custom/agents or .azd/copilot/agents")
+	cliout.Hint("Turn agents off for this project with 'azd copilot agents disable <name>'")
 
 	return nil
 }
 
+// stateLabel is the layer label, marked when the asset is disabled
+func stateLabel(layer assets.Layer, shadows []assets.Layer, merged, disabled bool) string {
+	label := layerLabel(layer, shadows, merged)
+	if disabled {
+		label += " · disabled"
+	}
+	return label
+}
+
+// layerLabel describes where an asset comes from, e.g. "project (extends embedded)"
+func layerLabel(layer assets.Layer, shadows []assets.Layer, merged bool) string {
+	if len(shadows) == 0 {
+		return string(layer)
+	}
+	verb := "shadows"
+	if merged {
+		verb = "extends"
+	}
+	return fmt.Sprintf("%s (%s %s)", layer, verb, shadows[len(shadows)-1])
+}
+
 func showAgent(name string) error {
 	agent, err := assets.GetAgent(name)
 	if err != nil {
@@ -109,6 +203,10 @@ func showAgent(name string) error {
 	if len(agent.Tools) > 0 {
 		cliout.Label("Tools", strings.Join(agent.Tools, ", "))
 	}
+	cliout.Label("Layer", layerLabel(agent.Layer, agent.Shadows, agent.Merged))
+	if agent.Disabled {
+		cliout.Label("State", "disabled in this project")
+	}
 
 	cliout.Newline()
 	cliout.Hint(fmt.Sprintf("Usage: azd copilot run --agent %s", name))
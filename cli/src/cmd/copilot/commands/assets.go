@@ -0,0 +1,199 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/assets"
+	"github.com/jongio/azd-core/cliout"
+
+	"github.com/spf13/cobra"
+)
+
+// NewAssetsCommand creates the 'assets' command for inspecting agents and skills together
+func NewAssetsCommand(outputFormat *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "assets",
+		Short: "Inspect agents and skills together",
+	}
+	cmd.AddCommand(newAssetsBudgetCommand(outputFormat))
+	return cmd
+}
+
+func newAssetsBudgetCommand(outputFormat *string) *cobra.Command {
+	var (
+		agent       string
+		fileTokens  int
+		agentTokens int
+		baseline    string
+		top         int
+	)
+	cmd := &cobra.Command{
+		Use:   "budget",
+		Short: "Estimate the context tokens agents and skills consume",
+		Long: `Estimate the token count of every agent, skill, and skill reference file in
+the resolved layers, and the context each agent loads when it runs: the agent
+file, the skill catalog (names and descriptions), and the SKILL.md of every
+skill it references. Reference files are counted separately, since they are
+read only on demand.
+
+Counts use a local approximation of BPE tokenizers, typically within 20%.
+
+Files over the file limit and agents over the agent limit are reported as
+warnings. Set the limits in .copilot.json:
+
+  "budget": { "fileTokens": 8000, "agentTokens": 40000 }
+
+Each extension version records its embedded budget in
+~/.azd/copilot/` + assets.BudgetHistoryFile + `, and the report compares against the previous
+version, or the one given with --baseline.`,
+		Example: `  azd copilot assets budget
+  azd copilot assets budget --agent azure-architect
+  azd copilot assets budget --agent-tokens 30000 --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limits := assets.LoadBudgetLimits()
+			if cmd.Flags().Changed("file-tokens") {
+				limits.FileTokens = fileTokens
+			}
+			if cmd.Flags().Changed("agent-tokens") {
+				limits.AgentTokens = agentTokens
+			}
+
+			report, err := assets.AnalyzeBudget("", limits)
+			if err != nil {
+				return fmt.Errorf("failed to analyze budget: %w", err)
+			}
+			// Best effort: history only feeds the version diff
+			_ = assets.RecordBudget()
+			if report.Baseline, err = assets.DiffBudget(baseline); err != nil {
+				return err
+			}
+
+			var selected *assets.AgentBudget
+			if agent != "" {
+				if selected = report.Agent(agent); selected == nil {
+					return fmt.Errorf("agent %q not found", agent)
+				}
+			}
+
+			if *outputFormat == "json" {
+				if selected != nil {
+					return cliout.PrintJSON(selected)
+				}
+				return cliout.PrintJSON(report)
+			}
+			if selected != nil {
+				printAgentBudget(report, selected)
+				return nil
+			}
+			printBudget(report, top)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&agent, "agent", "", "Show the context loaded for one agent")
+	cmd.Flags().IntVar(&fileTokens, "file-tokens", 0, "Warn when a file exceeds this many tokens (0 disables)")
+	cmd.Flags().IntVar(&agentTokens, "agent-tokens", 0, "Warn when an agent loads more than this many tokens (0 disables)")
+	cmd.Flags().StringVar(&baseline, "baseline", "", "Extension version to compare against (default: the previous version)")
+	cmd.Flags().IntVarP(&top, "top", "n", 15, "Number of files and agents to list (0 for all)")
+	return cmd
+}
+
+func printBudget(report *assets.BudgetReport, top int) {
+	cliout.Section("📏", "Context Budget")
+	cliout.Newline()
+	cliout.Label("Total", fmt.Sprintf("~%s tokens in %d files", assets.FormatTokens(report.Total), len(report.Files)))
+	cliout.Label("Catalog", fmt.Sprintf("~%s tokens, loaded with every agent", assets.FormatTokens(report.Catalog)))
+	cliout.Label("Limits", fmt.Sprintf("%s per file, %s per agent", limitLabel(report.Limits.FileTokens), limitLabel(report.Limits.AgentTokens)))
+
+	cliout.Newline()
+	cliout.Section("📄", "Largest Files")
+	files := report.Files
+	if top > 0 && len(files) > top {
+		files = files[:top]
+	}
+	for _, f := range files {
+		fmt.Printf("  %7s  %-9s  %s  %s\n", assets.FormatTokens(f.Tokens), f.Kind, f.Path, cliout.Muted("%s", f.Layer))
+	}
+
+	cliout.Newline()
+	cliout.Section("🤖", "Agents")
+	agents := report.Agents
+	if top > 0 && len(agents) > top {
+		agents = agents[:top]
+	}
+	maxLen := 0
+	for _, a := range agents {
+		maxLen = max(maxLen, len(a.Agent))
+	}
+	for _, a := range agents {
+		fmt.Printf("  %s%-*s%s  %7s  %s\n", cliout.Cyan, maxLen, a.Agent, cliout.Reset, assets.FormatTokens(a.Total),
+			cliout.Muted("%d skills, +%s on demand", len(a.Skills), assets.FormatTokens(a.OnDemand)))
+	}
+
+	if d := report.Baseline; d != nil {
+		cliout.Newline()
+		cliout.Section("📈", fmt.Sprintf("Since %s", d.Version))
+		cliout.Label("Embedded", fmt.Sprintf("%s → %s (%s)", assets.FormatTokens(d.Before), assets.FormatTokens(d.After), signedTokens(d.After-d.Before)))
+		for _, p := range d.LargestChanges(top) {
+			fmt.Printf("  %7s  %s\n", signedTokens(d.Changed[p]), p)
+		}
+	}
+
+	cliout.Newline()
+	printBudgetWarnings(report.Warnings)
+	cliout.Hint("Use 'azd copilot assets budget --agent <name>' for one agent's breakdown")
+}
+
+func printAgentBudget(report *assets.BudgetReport, a *assets.AgentBudget) {
+	cliout.Section("🤖", fmt.Sprintf("Context Budget: %s", a.Agent))
+	cliout.Newline()
+	cliout.Label("Agent file", "~"+assets.FormatTokens(a.AgentTokens))
+	cliout.Label("Catalog", "~"+assets.FormatTokens(a.Catalog))
+	cliout.Label("Skills", fmt.Sprintf("~%s in %d SKILL.md files", assets.FormatTokens(a.SkillTokens), len(a.Skills)))
+	cliout.Label("Total", fmt.Sprintf("~%s of %s", assets.FormatTokens(a.Total), limitLabel(report.Limits.AgentTokens)))
+	cliout.Label("On demand", fmt.Sprintf("~%s in skill reference files", assets.FormatTokens(a.OnDemand)))
+
+	if len(a.Skills) > 0 {
+		cliout.Newline()
+		for _, f := range report.Files {
+			if f.Kind == assets.BudgetSkill && slices.Contains(a.Skills, f.Asset) {
+				fmt.Printf("  %7s  %s\n", assets.FormatTokens(f.Tokens), f.Asset)
+			}
+		}
+	}
+
+	cliout.Newline()
+	if limit := report.Limits.AgentTokens; limit > 0 && a.Total > limit {
+		cliout.Warning("Over the agent budget by ~%s tokens", assets.FormatTokens(a.Total-limit))
+		return
+	}
+	cliout.Success("Within budget")
+}
+
+func printBudgetWarnings(warnings []string) {
+	if len(warnings) == 0 {
+		cliout.Success("Within budget")
+		return
+	}
+	for _, w := range warnings {
+		cliout.Warning("%s", w)
+	}
+}
+
+func limitLabel(n int) string {
+	if n <= 0 {
+		return "no limit"
+	}
+	return assets.FormatTokens(n)
+}
+
+func signedTokens(n int) string {
+	if n > 0 {
+		return "+" + assets.FormatTokens(n)
+	}
+	return assets.FormatTokens(n)
+}
@@ -0,0 +1,211 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/audit"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+	"github.com/jongio/azd-core/cliout"
+	"github.com/jongio/azd-core/fileutil"
+	"github.com/spf13/cobra"
+)
+
+// auditFilterFlags are the filters shared by 'audit' and 'audit export'
+type auditFilterFlags struct {
+	since    string
+	until    string
+	kinds    []string
+	name     string
+	outcomes []string
+}
+
+func (f *auditFilterFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.since, "since", "", "Show events at or after a time, date (2006-01-02), or age (24h, 7d)")
+	cmd.Flags().StringVar(&f.until, "until", "", "Show events before a time, date, or age")
+	cmd.Flags().StringSliceVar(&f.kinds, "kind", nil, "Event kinds: "+joinNames(audit.Kinds))
+	cmd.Flags().StringVar(&f.name, "name", "", "Event name glob, e.g. run_* or AZURE_*")
+	cmd.Flags().StringSliceVar(&f.outcomes, "outcome", nil, "Outcomes: "+joinNames(audit.Outcomes))
+}
+
+// filter validates the flags and builds the audit filter
+func (f *auditFilterFlags) filter(now time.Time) (audit.Filter, error) {
+	var filter audit.Filter
+	var err error
+	if f.since != "" {
+		if filter.Since, err = audit.ParseTime(f.since, now); err != nil {
+			return filter, fmt.Errorf("--since: %w", err)
+		}
+	}
+	if f.until != "" {
+		if filter.Until, err = audit.ParseTime(f.until, now); err != nil {
+			return filter, fmt.Errorf("--until: %w", err)
+		}
+	}
+	for _, k := range f.kinds {
+		kind := audit.Kind(strings.ToLower(k))
+		if !slices.Contains(audit.Kinds, kind) {
+			return filter, fmt.Errorf("unknown kind %q (valid: %s)", k, joinNames(audit.Kinds))
+		}
+		filter.Kinds = append(filter.Kinds, kind)
+	}
+	for _, o := range f.outcomes {
+		outcome := audit.Outcome(strings.ToLower(o))
+		if !slices.Contains(audit.Outcomes, outcome) {
+			return filter, fmt.Errorf("unknown outcome %q (valid: %s)", o, joinNames(audit.Outcomes))
+		}
+		filter.Outcomes = append(filter.Outcomes, outcome)
+	}
+	filter.Name = f.name
+	return filter, nil
+}
+
+// NewAuditCommand creates the 'audit' command for reviewing agent-driven actions.
+func NewAuditCommand(outputFormat *string) *cobra.Command {
+	var (
+		filters auditFilterFlags
+		limit   int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show the audit log of agent-driven actions",
+		Long: fmt.Sprintf(`Show the append-only audit log of agent-driven actions in this project.
+
+The log records Copilot CLI launches (command, agent, model, yolo, and a hash
+of the prompt), every azd-copilot MCP tool call with secret arguments
+redacted, environment value changes, checkpoint creation and deletion, and
+lifecycle events received by 'listen'.
+
+The log is written to %s under the project root, the nearest folder with
+azure.yaml or %s, unless "auditFile" is set there. Nothing is recorded
+outside a project.`, audit.DefaultFile, spec.MetadataFile),
+		Example: `  # What changed yesterday?
+  azd copilot audit --since 2026-01-05 --until 2026-01-06
+
+  # Denied or declined MCP tool calls in the last week
+  azd copilot audit --kind tool --outcome denied,declined --since 7d`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := filters.filter(time.Now())
+			if err != nil {
+				return err
+			}
+			events, err := audit.Read(filter)
+			if err != nil {
+				return err
+			}
+			if limit > 0 && len(events) > limit {
+				events = events[len(events)-limit:]
+			}
+
+			if *outputFormat == "json" {
+				if events == nil {
+					events = []audit.Event{}
+				}
+				return cliout.PrintJSON(events)
+			}
+
+			cliout.Section("📜", "Audit Log")
+			if p := audit.Path(); p != "" {
+				cliout.Label("File", p)
+			} else {
+				cliout.Label("File", "none (not in an azd project)")
+			}
+			cliout.Newline()
+
+			if len(events) == 0 {
+				cliout.Info("No matching events.")
+				return nil
+			}
+
+			rows := make([]cliout.TableRow, 0, len(events))
+			for _, e := range events {
+				rows = append(rows, cliout.TableRow{
+					"Time":    e.Time.Local().Format("2006-01-02 15:04:05"),
+					"Kind":    string(e.Kind),
+					"Name":    e.Name,
+					"Outcome": string(e.Outcome),
+					"Detail":  truncate(e.Detail, 60),
+				})
+			}
+			cliout.Table([]string{"Time", "Kind", "Name", "Outcome", "Detail"}, rows)
+			return nil
+		},
+	}
+
+	filters.register(cmd)
+	cmd.Flags().IntVarP(&limit, "limit", "n", 50, "Show only the most recent events (0 for all)")
+
+	cmd.AddCommand(newAuditExportCommand())
+
+	return cmd
+}
+
+func newAuditExportCommand() *cobra.Command {
+	var (
+		filters auditFilterFlags
+		output  string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Export the audit log as CSV",
+		Example: `  azd copilot audit export --since 30d --output-file audit.csv`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := filters.filter(time.Now())
+			if err != nil {
+				return err
+			}
+			events, err := audit.Read(filter)
+			if err != nil {
+				return err
+			}
+
+			if output == "" || output == "-" {
+				return audit.WriteCSV(os.Stdout, events)
+			}
+
+			var buf bytes.Buffer
+			if err := audit.WriteCSV(&buf, events); err != nil {
+				return err
+			}
+			if err := fileutil.AtomicWriteFile(output, buf.Bytes(), 0o600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+			cliout.Success("Exported %d events to %s", len(events), output)
+			return nil
+		},
+	}
+
+	filters.register(cmd)
+	cmd.Flags().StringVar(&output, "output-file", "", "CSV file to write (default stdout)")
+
+	return cmd
+}
+
+// joinNames joins string-typed values for help and error messages
+func joinNames[T ~string](values []T) string {
+	names := make([]string, len(values))
+	for i, v := range values {
+		names[i] = string(v)
+	}
+	return strings.Join(names, ", ")
+}
+
+// truncate shortens s to at most n runes for table output
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -4,9 +4,14 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/jongio/azd-copilot/cli/src/internal/build"
+	"github.com/jongio/azd-copilot/cli/src/internal/checkpoint"
 	"github.com/jongio/azd-copilot/cli/src/internal/copilot"
 	"github.com/jongio/azd-copilot/cli/src/internal/spec"
 	"github.com/jongio/azd-core/cliout"
@@ -17,10 +22,12 @@ import (
 var (
 	buildMode    string
 	buildApprove bool
+	buildResume  bool
+	buildNoGates bool
 )
 
 // NewBuildCommand creates the 'build' subcommand for generating Azure applications from descriptions.
-func NewBuildCommand() *cobra.Command {
+func NewBuildCommand(outputFormat *string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "build [description]",
 		Short: "Generate a complete Azure application from description",
@@ -32,9 +39,15 @@ The build process:
 1. Analyzes your description
 2. Generates a spec (docs/spec.md)
 3. Waits for your approval (or use --approve to skip)
-4. Generates code, infrastructure, tests, and documentation
-5. Runs preflight checks
-6. Deploys to Azure (if approved)
+4. Runs the design, develop, quality, and deploy phases, one Copilot turn
+   each. After every turn the phase's expected outputs are verified
+   (azure.yaml, source code, tests, infra/) and a checkpoint is saved.
+5. Runs quality gates after develop (build) and quality (build, lint, test),
+   using the commands found in package.json scripts, go.mod, pyproject.toml,
+   requirements.txt, *.csproj, and pom.xml. Each command has a 10 minute
+   timeout; tools that are not installed are skipped.
+6. Stops at the first failed phase or gate with a recovery checkpoint that
+   holds the real command output; --resume continues from it
 
 Project Modes:
   prototype   - Fast, minimal setup, free tiers (POC, demo, experiment)
@@ -49,9 +62,21 @@ Project Modes:
   azd copilot build --approve "REST API for inventory management"
   
   # Continue after editing spec
-  azd copilot build --approve`,
+  azd copilot build --approve
+
+  # Continue an interrupted build from its last checkpoint
+  azd copilot build --resume
+
+  # See where the build stands
+  azd copilot build status`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			description := strings.Join(args, " ")
+			if buildResume {
+				if description != "" {
+					return fmt.Errorf("--resume continues the existing spec and takes no description")
+				}
+				return runBuildFromSpec(cmd)
+			}
 
 			// If --approve and no description, check for existing spec
 			if description == "" && buildApprove {
@@ -70,6 +95,9 @@ Project Modes:
 
 	cmd.Flags().StringVar(&buildMode, "mode", "", "Project mode: prototype or production (auto-detected if not specified)")
 	cmd.Flags().BoolVar(&buildApprove, "approve", false, "Auto-approve spec and proceed with generation")
+	cmd.Flags().BoolVar(&buildResume, "resume", false, "Resume an interrupted build from its last checkpoint")
+	cmd.Flags().BoolVar(&buildNoGates, "skip-gates", false, "Don't run the project's build, lint, and test commands between phases")
+	cmd.AddCommand(newBuildStatusCommand(outputFormat))
 
 	return cmd
 }
@@ -105,9 +133,10 @@ func runBuild(cmd *cobra.Command, description string) error {
 		prompt := spec.GeneratePrompt(description, detectedMode)
 
 		// Launch Copilot to generate spec
-		if err := copilot.Launch(cmd.Context(), copilot.Options{
-			Prompt: prompt,
-			Agent:  "azure-manager",
+		if err := launchSession(cmd.Context(), copilot.Options{
+			Command: cmd.CommandPath(),
+			Prompt:  prompt,
+			Agent:   "azure-manager",
 		}); err != nil {
 			return err
 		}
@@ -130,56 +159,96 @@ func runBuild(cmd *cobra.Command, description string) error {
 }
 
 func runBuildFromSpec(cmd *cobra.Command) error {
-	cliout.Section("🚀", "Building from spec...")
-	cliout.Newline()
+	if !spec.Exists() {
+		return fmt.Errorf("no spec found. Run 'azd copilot build \"description\"' first")
+	}
+	content, err := spec.Read()
+	if err != nil {
+		return err
+	}
 
-	var prompt string
-	if spec.Exists() {
-		content, err := spec.Read()
-		if err != nil {
-			return err
+	var resume *checkpoint.Checkpoint
+	if buildResume {
+		if resume, err = checkpoint.DetectInterrupted(); err != nil {
+			return fmt.Errorf("failed to read checkpoints: %w", err)
 		}
-		prompt = buildFromSpecPrompt(content)
+		if resume == nil {
+			return fmt.Errorf("no interrupted build to resume. Run 'azd copilot build --approve' to start one")
+		}
+		cliout.Section("🔄", fmt.Sprintf("Resuming build at %s phase", checkpoint.ResumePhase(resume)))
+		cliout.Label("Checkpoint", fmt.Sprintf("%s (%s)", resume.ID, resume.Description))
 	} else {
-		return fmt.Errorf("no spec found. Run 'azd copilot build \"description\"' first")
+		if interrupted, _ := checkpoint.DetectInterrupted(); interrupted != nil && interrupted.Phase != checkpoint.PhaseSpec {
+			cliout.Hint(fmt.Sprintf("An interrupted build stopped at the %s phase; 'azd copilot build --resume' continues it", interrupted.Phase))
+		}
+		cliout.Section("🚀", "Building from spec...")
+		if err := spec.Approve(content); err != nil {
+			cliout.Warning("Failed to record spec approval: %v", err)
+		}
 	}
+	cliout.Newline()
 
-	// Launch Copilot with the build prompt
-	return copilot.Launch(cmd.Context(), copilot.Options{
-		Prompt: prompt,
-		Agent:  "azure-manager",
+	result, err := build.Run(cmd.Context(), build.Options{
+		Spec:   content,
+		Resume: resume,
+		Launch: func(ctx context.Context, phase checkpoint.Phase, prompt string) error {
+			return launchSession(ctx, copilot.Options{
+				Command: cmd.CommandPath(),
+				Prompt:  prompt,
+				Agent:   "azure-manager",
+			})
+		},
+		SessionID: copilot.LatestSessionID,
+		SkipGates: buildNoGates,
+		Checked: func(phase checkpoint.Phase, r build.GateResult) {
+			switch {
+			case r.Skipped != "":
+				cliout.Info("⏭️  %s %s", r.Gate, cliout.Muted("skipped: %s", r.Skipped))
+			case r.Passed:
+				cliout.Success("%s %s", r.Gate, cliout.Muted("%s", r.Duration.Round(time.Second)))
+			default:
+				cliout.Error("%s failed (%s)", r.Gate, r.Duration.Round(time.Second))
+			}
+		},
+		Started: func(phase checkpoint.Phase, n, total int) {
+			cliout.Section("🏗️", fmt.Sprintf("Phase %d/%d: %s", n, total, phase))
+			cliout.Newline()
+		},
+		Finished: func(pr build.PhaseResult) {
+			cliout.Newline()
+			if pr.Err != nil {
+				cliout.Error("%s phase failed: %v", pr.Phase, pr.Err)
+				var gateErr *build.GateError
+				if errors.As(pr.Err, &gateErr) {
+					cliout.Newline()
+					fmt.Println(gateErr.Report())
+				}
+				return
+			}
+			cliout.Success("%s phase complete: %d files, checkpoint %s", pr.Phase, len(pr.Files), pr.Checkpoint)
+			cliout.Newline()
+		},
 	})
+	if err != nil {
+		if result != nil && result.Recovery != nil {
+			cliout.Label("Recovery checkpoint", result.Recovery.ID)
+			cliout.Hint("Fix the issue or retry with 'azd copilot build --resume'")
+		}
+		return err
+	}
+
+	cliout.Success("Build complete: %s", joinPhases(result.Completed))
+	cliout.Hint("Review the generated project, then deploy with 'azd up'")
+	return nil
 }
 
-func buildFromSpecPrompt(specContent string) string {
-	var sb strings.Builder
-
-	sb.WriteString("# Build Application from Spec\n\n")
-	sb.WriteString("The user has approved the following specification. Now generate the complete application.\n\n")
-	sb.WriteString("## Approved Spec\n\n")
-	sb.WriteString(specContent)
-	sb.WriteString("\n\n")
-	sb.WriteString("## Instructions\n\n")
-	sb.WriteString("Generate all code, infrastructure, tests, and documentation according to this spec.\n\n")
-	sb.WriteString("Follow these phases:\n\n")
-	sb.WriteString("### Phase 1: Design\n")
-	sb.WriteString("- Finalize architecture decisions\n")
-	sb.WriteString("- Create azure.yaml\n\n")
-	sb.WriteString("### Phase 2: Develop\n")
-	sb.WriteString("- Generate backend code\n")
-	sb.WriteString("- Generate frontend code (if applicable)\n")
-	sb.WriteString("- Generate database schema and migrations\n\n")
-	sb.WriteString("### Phase 3: Quality\n")
-	sb.WriteString("- Generate tests\n")
-	sb.WriteString("- Run linter\n")
-	sb.WriteString("- Security review\n\n")
-	sb.WriteString("### Phase 4: Infrastructure\n")
-	sb.WriteString("- Generate Bicep files in /infra\n")
-	sb.WriteString("- Generate CI/CD pipeline\n")
-	sb.WriteString("- Generate documentation\n\n")
-	sb.WriteString("Save checkpoints to `docs/checkpoints/` after each phase.\n")
-
-	return sb.String()
+// joinPhases formats phases as "spec → design → ..."
+func joinPhases(phases []checkpoint.Phase) string {
+	names := make([]string, len(phases))
+	for i, p := range phases {
+		names[i] = string(p)
+	}
+	return strings.Join(names, " → ")
 }
 
 func detectProjectMode(description string) string {
@@ -0,0 +1,154 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/build"
+	"github.com/jongio/azd-core/cliout"
+
+	"github.com/spf13/cobra"
+)
+
+// maxStatusFiles caps the file lists printed by 'build status'
+const maxStatusFiles = 10
+
+func newBuildStatusCommand(outputFormat *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show where the build stands",
+		Long: `Show the spec and its approval, the phase timeline from checkpoints
+(completed, current, and failed phases with their retries), the files the
+build generated, the last Copilot session, and whether the workspace has
+changed since the last checkpoint.`,
+		Example: `  azd copilot build status
+  azd copilot build status --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := build.GetStatus()
+			if err != nil {
+				return fmt.Errorf("failed to read build status: %w", err)
+			}
+			if *outputFormat == "json" {
+				return cliout.PrintJSON(status)
+			}
+			printBuildStatus(status)
+			return nil
+		},
+	}
+}
+
+func printBuildStatus(s *build.Status) {
+	cliout.Section("🏗️", "Build Status")
+	cliout.Newline()
+
+	switch {
+	case !s.Spec.Exists:
+		cliout.Label("Spec", cliout.Muted("none (%s)", s.Spec.Path))
+	case s.Spec.Approved:
+		cliout.Label("Spec", fmt.Sprintf("%s · approved %s", s.Spec.Path, cliout.Muted("%s", s.Spec.Hash[:12])))
+	case s.Spec.ApprovedHash != "":
+		cliout.Label("Spec", fmt.Sprintf("%s · changed since approval %s", s.Spec.Path, cliout.Muted("%s", s.Spec.Hash[:12])))
+	default:
+		cliout.Label("Spec", fmt.Sprintf("%s · not approved %s", s.Spec.Path, cliout.Muted("%s", s.Spec.Hash[:12])))
+	}
+	if s.LastCheckpoint != "" {
+		cliout.Label("Checkpoint", fmt.Sprintf("%s %s", s.LastCheckpoint, cliout.Muted("%s", formatAge(time.Since(*s.UpdatedAt)))))
+	}
+	if s.SessionID != "" {
+		cliout.Label("Session", s.SessionID)
+	}
+	cliout.Newline()
+
+	for _, p := range s.Phases {
+		fmt.Printf("  %s %-8s %s\n", phaseIcon(p.State), p.Phase, phaseDetail(p))
+	}
+	cliout.Newline()
+
+	if n := len(s.GeneratedFiles); n > 0 {
+		fmt.Printf("  Generated files (%d)\n", n)
+		printFileList("", s.GeneratedFiles)
+		cliout.Newline()
+	}
+
+	switch {
+	case s.Drift == nil:
+	case s.Drift.Drifted():
+		cliout.Warning("Workspace changed since the last checkpoint")
+		if s.Drift.SpecChanged {
+			fmt.Printf("    ~ %s %s\n", s.Spec.Path, cliout.Muted("spec"))
+		}
+		printFileList("~ ", s.Drift.Modified)
+		printFileList("- ", s.Drift.Deleted)
+		printFileList("+ ", s.Drift.Untracked)
+		cliout.Newline()
+	default:
+		cliout.Success("Workspace matches the last checkpoint")
+		cliout.Newline()
+	}
+
+	switch {
+	case !s.Spec.Exists:
+		cliout.Hint("Start with 'azd copilot build \"description\"'")
+	case s.Complete:
+		cliout.Hint("Deploy with 'azd up'")
+	case s.LastCheckpoint == "":
+		cliout.Hint("Build from the spec with 'azd copilot build --approve'")
+	default:
+		cliout.Hint(fmt.Sprintf("Continue the %s phase with 'azd copilot build --resume'", s.Current))
+	}
+}
+
+func phaseIcon(state string) string {
+	switch state {
+	case build.StateDone:
+		return "✓"
+	case build.StateFailed:
+		return "✗"
+	case build.StateCurrent:
+		return "▶"
+	default:
+		return cliout.Muted("○")
+	}
+}
+
+// phaseDetail is the rest of a timeline line: when the phase completed, or
+// how it last failed
+func phaseDetail(p build.PhaseStatus) string {
+	var parts []string
+	if p.State == build.StateDone && p.CompletedAt != nil {
+		parts = append(parts, formatAge(time.Since(*p.CompletedAt)))
+		if p.Files > 0 {
+			parts = append(parts, fmt.Sprintf("%d files", p.Files))
+		}
+	}
+	if p.State == build.StateCurrent {
+		parts = append(parts, "next")
+	}
+	if n := len(p.Failures); n > 0 {
+		last := p.Failures[n-1]
+		if p.State == build.StateFailed {
+			parts = append(parts, fmt.Sprintf("failed (retry %d): %s", last.Retries, truncate(oneLine(last.Error), 80)))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d failed attempts", n))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return cliout.Muted("%s", strings.Join(parts, " · "))
+}
+
+func printFileList(prefix string, files []string) {
+	for i, f := range files {
+		if i == maxStatusFiles {
+			fmt.Printf("    %s\n", cliout.Muted("… %d more", len(files)-maxStatusFiles))
+			return
+		}
+		fmt.Printf("    %s%s\n", prefix, f)
+	}
+}
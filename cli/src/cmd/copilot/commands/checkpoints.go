@@ -9,6 +9,7 @@ import (
 
 	"github.com/jongio/azd-copilot/cli/src/internal/checkpoint"
 	"github.com/jongio/azd-copilot/cli/src/internal/copilot"
+	"github.com/jongio/azd-copilot/cli/src/internal/redact"
 	"github.com/jongio/azd-core/cliout"
 
 	"github.com/spf13/cobra"
@@ -110,7 +111,7 @@ func newCheckpointsShowCommand() *cobra.Command {
 			// Context
 			if cp.Context.ErrorMessage != "" {
 				cliout.Warning("Error Context:")
-				fmt.Printf("    %s\n", cp.Context.ErrorMessage)
+				fmt.Printf("    %s\n", redact.Load().Text(cp.Context.ErrorMessage))
 				cliout.Newline()
 			}
 
@@ -171,9 +172,10 @@ If no checkpoint ID is provided, resumes from the latest checkpoint.`,
 			// Generate resume prompt and launch copilot
 			prompt := checkpoint.GenerateResumePrompt(cp)
 
-			return copilot.Launch(cmd.Context(), copilot.Options{
-				Prompt: prompt,
-				Agent:  "azure-manager",
+			return launchSession(cmd.Context(), copilot.Options{
+				Command: cmd.CommandPath(),
+				Prompt:  prompt,
+				Agent:   "azure-manager",
 			})
 		},
 	}
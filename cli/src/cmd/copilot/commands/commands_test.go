@@ -10,7 +10,8 @@ import (
 )
 
 func TestNewMCPCommand(t *testing.T) {
-	cmd := NewMCPCommand()
+	outputFormat := "default"
+	cmd := NewMCPCommand(&outputFormat)
 
 	if cmd == nil {
 		t.Fatal("NewMCPCommand() returned nil")
@@ -59,7 +60,8 @@ func TestNewListenCommand(t *testing.T) {
 }
 
 func TestNewAgentsCommand(t *testing.T) {
-	cmd := NewAgentsCommand()
+	outputFormat := "default"
+	cmd := NewAgentsCommand(&outputFormat)
 
 	if cmd == nil {
 		t.Fatal("NewAgentsCommand() returned nil")
@@ -73,7 +75,8 @@ func TestNewAgentsCommand(t *testing.T) {
 }
 
 func TestNewSkillsCommand(t *testing.T) {
-	cmd := NewSkillsCommand()
+	outputFormat := "default"
+	cmd := NewSkillsCommand(&outputFormat)
 
 	if cmd == nil {
 		t.Fatal("NewSkillsCommand() returned nil")
@@ -115,7 +118,8 @@ func TestNewCheckpointsCommand(t *testing.T) {
 }
 
 func TestNewBuildCommand(t *testing.T) {
-	cmd := NewBuildCommand()
+	outputFormat := "default"
+	cmd := NewBuildCommand(&outputFormat)
 
 	if cmd == nil {
 		t.Fatal("NewBuildCommand() returned nil")
@@ -169,3 +173,67 @@ func TestQuickActionCommands(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPolicyCommand(t *testing.T) {
+	outputFormat := "default"
+	cmd := NewPolicyCommand(&outputFormat)
+
+	if cmd == nil {
+		t.Fatal("NewPolicyCommand() returned nil")
+	}
+	if cmd.Use != "policy" {
+		t.Errorf("cmd.Use = %q, want %q", cmd.Use, "policy")
+	}
+
+	checkCmd, _, err := cmd.Find([]string{"check"})
+	if err != nil || checkCmd == nil {
+		t.Fatalf("'check' subcommand not found: %v", err)
+	}
+	for _, flag := range []string{"file", "command", "edit", "yolo"} {
+		if checkCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("check command missing --%s flag", flag)
+		}
+	}
+}
+
+func TestNewDoctorCommand(t *testing.T) {
+	outputFormat := "default"
+	cmd := NewDoctorCommand(&outputFormat)
+
+	if cmd == nil {
+		t.Fatal("NewDoctorCommand() returned nil")
+	}
+	if cmd.Use != "doctor" {
+		t.Errorf("cmd.Use = %q, want %q", cmd.Use, "doctor")
+	}
+	if cmd.RunE == nil {
+		t.Error("cmd.RunE should be set")
+	}
+}
+
+func TestNewSetupCommand(t *testing.T) {
+	outputFormat := "default"
+	cmd := NewSetupCommand(&outputFormat)
+
+	if cmd == nil {
+		t.Fatal("NewSetupCommand() returned nil")
+	}
+	if cmd.Use != "setup" {
+		t.Errorf("cmd.Use = %q, want %q", cmd.Use, "setup")
+	}
+	if cmd.Flags().Lookup("force") == nil {
+		t.Error("setup command missing --force flag")
+	}
+}
+
+func TestNewMCPCommand_ConfigSubcommands(t *testing.T) {
+	outputFormat := "default"
+	cmd := NewMCPCommand(&outputFormat)
+
+	for _, name := range []string{"list", "add", "remove", "enable", "disable", "diff"} {
+		sub, _, err := cmd.Find([]string{name})
+		if err != nil || sub == nil || sub.Name() != name {
+			t.Errorf("'%s' subcommand not found: %v", name, err)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/azure/azure-dev/cli/azd/pkg/azdext"
+	"github.com/jongio/azd-copilot/cli/src/internal/redact"
 	"github.com/jongio/azd-core/cliout"
 	"github.com/spf13/cobra"
 )
@@ -31,6 +32,7 @@ func NewContextCommand() *cobra.Command {
 			defer azdClient.Close()
 
 			var hasEnv bool
+			redactor := redact.Load()
 
 			getConfigResponse, err := azdClient.UserConfig().Get(ctx, &azdext.GetUserConfigRequest{
 				Path: "",
@@ -41,7 +43,7 @@ func NewContextCommand() *cobra.Command {
 					var userConfig map[string]string
 					err := json.Unmarshal(getConfigResponse.Value, &userConfig)
 					if err == nil {
-						jsonBytes, err := json.MarshalIndent(userConfig, "", "  ")
+						jsonBytes, err := json.MarshalIndent(redactor.Map(userConfig), "", "  ")
 						if err == nil {
 							fmt.Println(string(jsonBytes))
 						}
@@ -110,8 +112,14 @@ func NewContextCommand() *cobra.Command {
 				})
 				if err == nil {
 					cliout.Section("📝", "Environment values")
+					hidden := false
 					for _, pair := range getValuesResponse.KeyValues {
-						cliout.Label(pair.Key, pair.Value)
+						value := redactor.Value(pair.Key, pair.Value)
+						hidden = hidden || value != pair.Value
+						cliout.Label(pair.Key, value)
+					}
+					if hidden {
+						cliout.Hint("Secret values are hidden; use --show-secrets to reveal them.")
 					}
 					cliout.Newline()
 				}
@@ -0,0 +1,92 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/copilot"
+	"github.com/jongio/azd-copilot/cli/src/internal/doctor"
+	"github.com/jongio/azd-core/cliout"
+
+	"github.com/spf13/cobra"
+)
+
+// NewDoctorCommand creates the 'doctor' subcommand for diagnosing the local environment.
+func NewDoctorCommand(outputFormat *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the azd copilot environment",
+		Long: `Check everything azd copilot needs and suggest a fix for each problem:
+
+- Copilot CLI install locations (every candidate probed and which one is used)
+- node, npm, and npx versions
+- Copilot CLI version
+- ~/.copilot/mcp-config.json syntax and required MCP servers
+- Installed agents and skills compared with the embedded versions
+- Required azd extensions (jongio.azd.app, jongio.azd.exec)
+- Terminal access for interactive sessions`,
+		Example: `  # Run all checks
+  azd copilot doctor
+
+  # Machine-readable output
+  azd copilot doctor --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report := doctor.Run(cmd.Context())
+
+			if *outputFormat == "json" {
+				if err := cliout.PrintJSON(report); err != nil {
+					return err
+				}
+			} else {
+				printDoctorReport(report)
+			}
+
+			if n := report.Failures(); n > 0 {
+				return fmt.Errorf("%d check(s) failed", n)
+			}
+			return nil
+		},
+	}
+}
+
+func printDoctorReport(report *doctor.Report) {
+	cliout.Section("🩺", "azd copilot doctor")
+	cliout.Newline()
+
+	for _, check := range report.Checks {
+		switch check.Status {
+		case doctor.StatusOK:
+			cliout.ItemSuccess("%s: %s", check.Name, check.Detail)
+		case doctor.StatusWarn:
+			cliout.ItemWarning("%s: %s", check.Name, check.Detail)
+		default:
+			cliout.ItemError("%s: %s", check.Name, check.Detail)
+		}
+		if check.Fix != "" {
+			fmt.Printf("      → %s\n", check.Fix)
+		}
+
+		// List every probed location when the Copilot CLI lookup is reported
+		if candidates, ok := check.Data.([]copilot.Candidate); ok {
+			printCandidates(candidates)
+		}
+	}
+
+	cliout.Newline()
+}
+
+func printCandidates(candidates []copilot.Candidate) {
+	for _, c := range candidates {
+		mark := "·"
+		if c.Found {
+			mark = "✓"
+		}
+		line := fmt.Sprintf("        %s %s", mark, c.Path)
+		if c.Note != "" {
+			line += fmt.Sprintf(" (%s)", c.Note)
+		}
+		fmt.Println(cliout.Muted("%s", line))
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/assets"
+	"github.com/jongio/azd-copilot/cli/src/internal/checkpoint"
+	"github.com/jongio/azd-copilot/cli/src/internal/copilot"
+	"github.com/jongio/azd-copilot/cli/src/internal/policy"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+// ApplyPolicy loads the tool permission policy (policyFile, or the merged
+// user and project policies when empty) and adds its tool rules to opts.
+// Every Copilot session azd copilot starts goes through it.
+func ApplyPolicy(opts *copilot.Options, policyFile string) (*policy.Policy, error) {
+	pol, err := policy.Load(policyFile)
+	if err != nil {
+		return nil, err
+	}
+	toolArgs := pol.Translate()
+	opts.AllowTools = append(opts.AllowTools, toolArgs.AllowTools...)
+	opts.DenyTools = append(opts.DenyTools, toolArgs.DenyTools...)
+	if warning := pol.UnenforcedEdits(opts.Yolo); warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	return pol, nil
+}
+
+// AssetDirs returns the agent and skill directories a session loads: the
+// merged view with overrides applied and disabled assets left out, or
+// fallback when that view cannot be built.
+func AssetDirs(fallback []string) []string {
+	merged, err := assets.MergedDirs("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to apply agent and skill overrides: %v\n", err)
+		return fallback
+	}
+	return merged
+}
+
+// LaunchSession launches Copilot CLI with the tool permission policy
+// (policyFile, or the merged user and project policies when empty) and the
+// agent and skill view applied, using fallbackDirs when that view cannot be
+// built. In a project, it first snapshots the project files when the policy
+// lets destructive commands run without confirmation.
+func LaunchSession(ctx context.Context, opts copilot.Options, policyFile string, fallbackDirs []string) error {
+	pol, err := ApplyPolicy(&opts, policyFile)
+	if err != nil {
+		return err
+	}
+	opts.AddDirs = append(opts.AddDirs, AssetDirs(fallbackDirs)...)
+
+	if destructive := pol.AutoApprovedDestructive(opts.Yolo); len(destructive) > 0 {
+		if root, ok := spec.ProjectRoot(); ok && root == "." {
+			if _, err := checkpoint.SaveBeforeDestructive(destructive); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save pre-destructive checkpoint: %v\n", err)
+			}
+		}
+	}
+
+	return copilot.Launch(ctx, opts)
+}
+
+// launchSession launches Copilot CLI with the project's policy and agent
+// and skill view applied
+func launchSession(ctx context.Context, opts copilot.Options) error {
+	return LaunchSession(ctx, opts, "", nil)
+}
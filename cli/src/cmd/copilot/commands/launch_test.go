@@ -0,0 +1,91 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/copilot"
+	"github.com/jongio/azd-copilot/cli/src/internal/policy"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+func TestApplyPolicy(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Chdir(t.TempDir())
+
+	if err := os.MkdirAll(filepath.Dir(policy.ProjectFile), 0750); err != nil {
+		t.Fatal(err)
+	}
+	policyYAML := "allow:\n  commands: [\"git status\"]\ndeny:\n  commands: [\"az group delete\"]\n"
+	if err := os.WriteFile(policy.ProjectFile, []byte(policyYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := copilot.Options{Prompt: "build", AllowTools: []string{"azure"}}
+	pol, err := ApplyPolicy(&opts, "")
+	if err != nil {
+		t.Fatalf("ApplyPolicy() error = %v", err)
+	}
+	if pol.IsEmpty() {
+		t.Error("ApplyPolicy() returned an empty policy")
+	}
+	if !slices.Equal(opts.AllowTools, []string{"azure", "shell(git status)"}) {
+		t.Errorf("AllowTools = %v, want the existing tool plus the policy's", opts.AllowTools)
+	}
+	if !slices.Contains(opts.DenyTools, "shell(az group delete)") {
+		t.Errorf("DenyTools = %v, want shell(az group delete)", opts.DenyTools)
+	}
+	if opts.Prompt != "build" {
+		t.Errorf("Prompt = %q, want it unchanged", opts.Prompt)
+	}
+
+	if err := os.WriteFile(policy.ProjectFile, []byte("allow: ["), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyPolicy(&copilot.Options{}, ""); err == nil {
+		t.Error("ApplyPolicy() with a malformed policy should fail")
+	}
+}
+
+func TestAssetDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Chdir(t.TempDir())
+
+	agentsDir := filepath.Join(home, ".azd", "copilot", "agents")
+	if err := os.MkdirAll(agentsDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(agentsDir, "azure-data.md"), []byte("---\nname: azure-data\n---\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(spec.MetadataFile, []byte(`{"agents": {"disable": ["azure-data"]}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sessions load the filtered view without the disabled agent
+	dirs := AssetDirs(nil)
+	if len(dirs) != 2 || !strings.HasPrefix(dirs[0], filepath.Join(home, ".azd", "copilot", "merged")) {
+		t.Fatalf("AssetDirs() = %v, want the filtered view", dirs)
+	}
+	if _, err := os.Stat(filepath.Join(dirs[0], "azure-data.md")); !os.IsNotExist(err) {
+		t.Error("disabled agent azure-data is in the filtered view")
+	}
+
+	// Without a home directory the fallback is used
+	t.Setenv("HOME", "")
+	t.Setenv("USERPROFILE", "")
+	fallback := []string{"agents", "skills"}
+	if dirs := AssetDirs(fallback); !slices.Equal(dirs, fallback) {
+		t.Errorf("AssetDirs() = %v, want the fallback %v", dirs, fallback)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/jongio/azd-copilot/cli/src/internal/audit"
 	"github.com/jongio/azd-copilot/cli/src/internal/cache"
 	"github.com/jongio/azd-copilot/cli/src/internal/copilot"
 
@@ -18,15 +19,52 @@ func NewListenCommand() *cobra.Command {
 	return azdext.NewListenCommand(func(host *azdext.ExtensionHost) {
 		host.
 			// Project-level events
-			WithProjectEventHandler("preinit", handlePreInit).
-			WithProjectEventHandler("preprovision", handlePreProvision).
-			WithProjectEventHandler("postprovision", handlePostProvision).
+			WithProjectEventHandler("preinit", auditProjectEvent("preinit", handlePreInit)).
+			WithProjectEventHandler("preprovision", auditProjectEvent("preprovision", handlePreProvision)).
+			WithProjectEventHandler("postprovision", auditProjectEvent("postprovision", handlePostProvision)).
 			// Service-level events
-			WithServiceEventHandler("predeploy", handlePreDeploy, &azdext.ServiceEventOptions{}).
-			WithServiceEventHandler("postdeploy", handlePostDeploy, &azdext.ServiceEventOptions{})
+			WithServiceEventHandler("predeploy", auditServiceEvent("predeploy", handlePreDeploy), &azdext.ServiceEventOptions{}).
+			WithServiceEventHandler("postdeploy", auditServiceEvent("postdeploy", handlePostDeploy), &azdext.ServiceEventOptions{})
 	})
 }
 
+// auditProjectEvent records a project lifecycle event in the audit log
+func auditProjectEvent(name string, handler azdext.ProjectEventHandler) azdext.ProjectEventHandler {
+	return func(ctx context.Context, args *azdext.ProjectEventArgs) error {
+		err := handler(ctx, args)
+		var auditArgs map[string]interface{}
+		if args != nil && args.Project != nil {
+			auditArgs = map[string]interface{}{"project": args.Project.Name}
+		}
+		recordHook(name, auditArgs, err)
+		return err
+	}
+}
+
+// auditServiceEvent records a service lifecycle event in the audit log
+func auditServiceEvent(name string, handler azdext.ServiceEventHandler) azdext.ServiceEventHandler {
+	return func(ctx context.Context, args *azdext.ServiceEventArgs) error {
+		err := handler(ctx, args)
+		auditArgs := map[string]interface{}{}
+		if args != nil && args.Project != nil {
+			auditArgs["project"] = args.Project.Name
+		}
+		if args != nil && args.Service != nil {
+			auditArgs["service"] = args.Service.Name
+		}
+		recordHook(name, auditArgs, err)
+		return err
+	}
+}
+
+func recordHook(name string, args map[string]interface{}, err error) {
+	e := audit.Event{Kind: audit.KindHook, Name: name, Args: args, Outcome: audit.OutcomeOK}
+	if err != nil {
+		e.Outcome, e.Detail = audit.OutcomeError, err.Error()
+	}
+	audit.RecordBestEffort(e)
+}
+
 // handlePreInit is called before azd init completes
 func handlePreInit(ctx context.Context, args *azdext.ProjectEventArgs) error {
 	// Clear cached setup state when initializing a new project
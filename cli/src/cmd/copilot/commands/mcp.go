@@ -6,37 +6,82 @@ package commands
 import (
 	"context"
 	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/azdext"
-	"github.com/jongio/azd-copilot/cli/src/internal/copilot"
+	"github.com/jongio/azd-copilot/cli/src/internal/mcpconfig"
+	"github.com/jongio/azd-core/cliout"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
 )
 
 // NewMCPCommand creates the 'mcp' subcommand for MCP server management.
-func NewMCPCommand() *cobra.Command {
+func NewMCPCommand(outputFormat *string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "mcp",
 		Short: "MCP server management",
-		Long:  `Manage Model Context Protocol (MCP) server for AI tool integration.`,
+		Long: `Manage Model Context Protocol (MCP) server for AI tool integration.
+
+Built-in servers written to ~/.copilot/mcp-config.json are tracked as owned by
+azd copilot and upgraded when their definitions change. Servers you add or
+edit are never modified. A backup (mcp-config.json.bak) is kept before each write.
+
+npx-based servers are pinned to exact versions in mcp-config.lock.json so
+sessions are reproducible. Use 'azd copilot mcp update' to bump them.`,
 	}
 
 	cmd.AddCommand(newMCPServeCommand())
 	cmd.AddCommand(newMCPConfigureCommand())
+	cmd.AddCommand(newMCPListCommand(outputFormat))
+	cmd.AddCommand(newMCPAddCommand())
+	cmd.AddCommand(newMCPRemoveCommand())
+	cmd.AddCommand(newMCPEnableCommand())
+	cmd.AddCommand(newMCPDisableCommand())
+	cmd.AddCommand(newMCPDiffCommand(outputFormat))
+	cmd.AddCommand(newMCPUpdateCommand(outputFormat))
+	cmd.AddCommand(newMCPCheckCommand(outputFormat))
 
 	return cmd
 }
 
 func newMCPServeCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:    "serve",
-		Short:  "Start MCP server (for Copilot CLI integration)",
+	var (
+		transport string
+		addr      string
+		token     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Start MCP server (for Copilot CLI integration)",
+		Long: `Start the azd-copilot MCP server.
+
+By default the server speaks stdio for a single client. With --transport http
+(streamable HTTP at /mcp) or --transport sse (at /sse) one server can be shared
+by several clients, such as VS Code, other agents, and test harnesses.
+
+Set --token or ` + mcpTokenEnv + ` to require "Authorization: Bearer <token>" on
+every request. The server shuts down gracefully on Ctrl+C.`,
+		Example: `  # Share one server per workspace
+  AZD_COPILOT_MCP_TOKEN=secret azd copilot mcp serve --transport http --addr 127.0.0.1:8765`,
 		Hidden: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return serveMCP(cmd.Context())
+			if token == "" {
+				token = os.Getenv(mcpTokenEnv)
+			}
+			return serveMCP(cmd.Context(), transport, addr, token)
 		},
 	}
+
+	cmd.Flags().StringVar(&transport, "transport", transportStdio, "Transport: stdio, http, or sse")
+	cmd.Flags().StringVar(&addr, "addr", defaultMCPAddr, "Listen address for http and sse transports")
+	cmd.Flags().StringVar(&token, "token", "", "Bearer token required from clients (default $"+mcpTokenEnv+")")
+
+	return cmd
 }
 
 func newMCPConfigureCommand() *cobra.Command {
@@ -44,16 +89,27 @@ func newMCPConfigureCommand() *cobra.Command {
 		Use:   "configure",
 		Short: "Configure external MCP servers for Copilot CLI",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := copilot.ConfigureMCPServer(); err != nil {
+			store, err := mcpconfig.Open()
+			if err != nil {
+				return fmt.Errorf("failed to configure MCP servers: %w", err)
+			}
+			changes, err := store.Sync()
+			if err != nil {
 				return fmt.Errorf("failed to configure MCP servers: %w", err)
 			}
 
-			fmt.Println("MCP servers configured. Available servers:")
-			fmt.Println("  • azure         - Azure resource operations via @azure/mcp")
-			fmt.Println("  • azd           - Azure Developer CLI operations")
-			fmt.Println("  • microsoft-learn - Microsoft Learn documentation")
-			fmt.Println("  • context7      - Context7 memory")
-			fmt.Println("  • playwright    - Playwright browser automation and E2E testing")
+			for _, c := range changes {
+				switch c.Action {
+				case mcpconfig.ActionAdd:
+					fmt.Printf("  + %s (added)\n", c.Name)
+				case mcpconfig.ActionUpdate:
+					fmt.Printf("  ~ %s (updated)\n", c.Name)
+				case mcpconfig.ActionUnchanged:
+					fmt.Printf("  • %s\n", c.Name)
+				default:
+					fmt.Printf("  • %s - %s\n", c.Name, c.Action)
+				}
+			}
 			fmt.Println()
 			fmt.Println("These servers are automatically started by Copilot CLI.")
 
@@ -62,11 +118,15 @@ func newMCPConfigureCommand() *cobra.Command {
 	}
 }
 
-// serveMCP starts the MCP server for azd-copilot extension
-func serveMCP(_ context.Context) error {
+// newMCPServer builds the azd-copilot MCP server with every tool and resource
+func newMCPServer() *server.MCPServer {
 	builder := azdext.NewMCPServerBuilder("azd-copilot", Version).
 		WithRateLimit(10, 1.0).
-		WithResourceCapabilities(true, false)
+		WithResourceCapabilities(true, false).
+		WithPromptCapabilities(false).
+		WithServerOption(server.WithElicitation()).
+		WithServerOption(server.WithToolHandlerMiddleware(auditToolCalls)).
+		WithServerOption(server.WithToolHandlerMiddleware(redactToolResults))
 
 	// Register tools
 	registerMCPTools(builder)
@@ -74,15 +134,42 @@ func serveMCP(_ context.Context) error {
 	// Register resources
 	registerMCPResources(builder)
 
-	// Build and start stdio server
 	s := builder.Build()
-	return server.ServeStdio(s)
+
+	// Register quick actions as prompts
+	registerMCPPrompts(s)
+
+	return s
+}
+
+// serveMCP starts the MCP server for azd-copilot extension over the given transport
+func serveMCP(ctx context.Context, transport, addr, token string) error {
+	s := newMCPServer()
+	if transport == transportStdio {
+		return server.ServeStdio(s)
+	}
+
+	if token == "" && !isLoopback(addr) {
+		cliout.Warning("Serving MCP on %s without authentication; set --token or %s", addr, mcpTokenEnv)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return serveMCPHTTP(ctx, s, transport, ln, token)
 }
 
 func registerMCPTools(builder *azdext.MCPServerBuilder) {
 	// Register gRPC service tools (environments, deployments, accounts, workflows, compose)
 	registerGRPCTools(builder)
 
+	// Register spec tools (read_spec, update_spec_section, validate_spec, get_spec_metadata)
+	registerSpecTools(builder)
+
 	// Tool: list_agents
 	builder.AddTool("list_agents",
 		func(ctx context.Context, args azdext.ToolArgs) (*mcp.CallToolResult, error) {
@@ -0,0 +1,475 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/mcpconfig"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+	"github.com/jongio/azd-core/cliout"
+
+	"github.com/spf13/cobra"
+)
+
+func newMCPListCommand(outputFormat *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List MCP servers configured for Copilot CLI",
+		Long: fmt.Sprintf(`List MCP servers from ~/.copilot/mcp-config.json, merged with project
+servers declared in the "mcp" section of %s:
+
+  {
+    "mcp": {
+      "servers": {"docs": {"type": "http", "url": "https://docs.internal/mcp", "tools": ["*"]}},
+      "disable": ["playwright"]
+    }
+  }`, spec.MetadataFile),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := mcpconfig.Open()
+			if err != nil {
+				return err
+			}
+			meta, err := spec.LoadMetadata()
+			if err != nil {
+				return err
+			}
+			entries := mcpconfig.WithProject(store.List(), meta.MCP)
+
+			if *outputFormat == "json" {
+				return cliout.PrintJSON(entries)
+			}
+
+			cliout.Section("🔌", "MCP Servers")
+			cliout.Label("Config", store.Path)
+			cliout.Newline()
+
+			if len(entries) == 0 {
+				cliout.Info("No MCP servers configured.")
+				cliout.Hint("Run 'azd copilot mcp configure' to add the built-in servers.")
+				return nil
+			}
+
+			rows := make([]cliout.TableRow, 0, len(entries))
+			for _, e := range entries {
+				state := "enabled"
+				if !e.Enabled {
+					state = "disabled"
+				}
+				if e.Note != "" {
+					state += " (" + e.Note + ")"
+				}
+				rows = append(rows, cliout.TableRow{
+					"Name":    e.Name,
+					"Source":  e.Source,
+					"State":   state,
+					"Command": e.Server.Summary(),
+				})
+			}
+			cliout.Table([]string{"Name", "Source", "State", "Command"}, rows)
+			return nil
+		},
+	}
+}
+
+func newMCPAddCommand() *cobra.Command {
+	var (
+		command  string
+		cmdArgs  []string
+		url      string
+		serverTy string
+		env      []string
+		tools    []string
+		force    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add an MCP server to the Copilot CLI config",
+		Long: `Add an MCP server to ~/.copilot/mcp-config.json.
+
+Servers added with --command or --url are user-owned and never changed by
+'azd copilot mcp configure'. Adding a built-in server name without a
+definition restores the embedded definition.`,
+		Example: `  # Add a local server
+  azd copilot mcp add my-server --command npx --args -y,@acme/mcp@latest
+
+  # Add a remote server
+  azd copilot mcp add docs --url https://example.com/mcp
+
+  # Restore a built-in server that was removed
+  azd copilot mcp add playwright`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			var def json.RawMessage
+			if command != "" || url != "" {
+				if command != "" && url != "" {
+					return fmt.Errorf("--command and --url are mutually exclusive")
+				}
+				srv := mcpconfig.Server{Command: command, Args: cmdArgs, URL: url, Type: serverTy, Tools: tools}
+				if srv.Type == "" {
+					srv.Type = "local"
+					if url != "" {
+						srv.Type = "http"
+					}
+				}
+				if len(env) > 0 {
+					srv.Env = make(map[string]string, len(env))
+					for _, kv := range env {
+						k, v, ok := strings.Cut(kv, "=")
+						if !ok {
+							return fmt.Errorf("invalid --env %q, expected KEY=VALUE", kv)
+						}
+						srv.Env[k] = v
+					}
+				}
+				data, err := json.Marshal(srv)
+				if err != nil {
+					return fmt.Errorf("failed to marshal server definition: %w", err)
+				}
+				def = data
+			}
+
+			store, err := mcpconfig.Open()
+			if err != nil {
+				return err
+			}
+			if err := store.Add(name, def, force); err != nil {
+				return err
+			}
+
+			cliout.Success("Added MCP server %q", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&command, "command", "", "Command that starts a local server")
+	cmd.Flags().StringSliceVar(&cmdArgs, "args", nil, "Arguments for --command")
+	cmd.Flags().StringVar(&url, "url", "", "URL of a remote server")
+	cmd.Flags().StringVar(&serverTy, "type", "", "Server type (default: local for --command, http for --url)")
+	cmd.Flags().StringArrayVar(&env, "env", nil, "Environment variable for the server (KEY=VALUE, repeatable)")
+	cmd.Flags().StringSliceVar(&tools, "tools", []string{"*"}, "Tools to expose from the server")
+	cmd.Flags().BoolVar(&force, "force", false, "Replace an existing server with the same name")
+
+	return cmd
+}
+
+func newMCPRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove an MCP server from the Copilot CLI config",
+		Long: `Remove an MCP server from ~/.copilot/mcp-config.json.
+
+Removed built-in servers are not re-added by 'azd copilot mcp configure'
+until they are added again with 'azd copilot mcp add <name>'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := mcpconfig.Open()
+			if err != nil {
+				return err
+			}
+			if err := store.Remove(args[0]); err != nil {
+				return err
+			}
+			cliout.Success("Removed MCP server %q", args[0])
+			return nil
+		},
+	}
+}
+
+func newMCPEnableCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "enable <name>",
+		Short: "Re-enable a disabled MCP server",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := mcpconfig.Open()
+			if err != nil {
+				return err
+			}
+			if err := store.Enable(args[0]); err != nil {
+				return err
+			}
+			cliout.Success("Enabled MCP server %q", args[0])
+			return nil
+		},
+	}
+}
+
+func newMCPDisableCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "disable <name>",
+		Short: "Disable an MCP server without losing its definition",
+		Long: `Remove an MCP server from ~/.copilot/mcp-config.json while keeping its
+definition so 'azd copilot mcp enable <name>' can restore it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := mcpconfig.Open()
+			if err != nil {
+				return err
+			}
+			if err := store.Disable(args[0]); err != nil {
+				return err
+			}
+			cliout.Success("Disabled MCP server %q", args[0])
+			return nil
+		},
+	}
+}
+
+func newMCPDiffCommand(outputFormat *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff",
+		Short: "Show how the config differs from the built-in server definitions",
+		Long: `Compare ~/.copilot/mcp-config.json with the built-in server definitions and
+show what 'azd copilot mcp configure' would change. User-owned entries are
+shown but never modified.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := mcpconfig.Open()
+			if err != nil {
+				return err
+			}
+			changes := store.Plan()
+
+			if *outputFormat == "json" {
+				return cliout.PrintJSON(changes)
+			}
+
+			cliout.Section("🔌", "MCP Config Diff")
+			cliout.Label("Config", store.Path)
+			cliout.Newline()
+
+			pending := 0
+			for _, c := range changes {
+				switch c.Action {
+				case mcpconfig.ActionAdd, mcpconfig.ActionUpdate:
+					pending++
+					cliout.ItemWarning("%s: %s", c.Name, c.Action)
+					printDefinitionDiff(c.Current, c.Desired)
+				case mcpconfig.ActionSkipUser:
+					cliout.ItemInfo("%s: %s", c.Name, c.Action)
+					printDefinitionDiff(c.Current, c.Desired)
+				case mcpconfig.ActionUnchanged:
+					cliout.ItemSuccess("%s: %s", c.Name, c.Action)
+				default:
+					cliout.ItemInfo("%s: %s", c.Name, c.Action)
+				}
+			}
+			cliout.Newline()
+
+			if pending > 0 {
+				cliout.Hint("Run 'azd copilot mcp configure' to apply these changes.")
+			} else {
+				cliout.Success("No changes to apply")
+			}
+			return nil
+		},
+	}
+}
+
+func newMCPUpdateCommand(outputFormat *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "update [name...]",
+		Short: "Pin MCP servers to their latest published versions",
+		Long: `Resolve the latest published version of each npx-based built-in MCP server,
+record it in the lock file, download it into the npm cache for offline use,
+and update the Copilot CLI config.
+
+Without arguments every pinnable server is updated. The lock file defaults to
+~/.copilot/mcp-config.lock.json and can be overridden with ` + mcpconfig.LockPathEnv + `.`,
+		Example: `  # Update every pinned server
+  azd copilot mcp update
+
+  # Update only playwright
+  azd copilot mcp update playwright`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := mcpconfig.Open()
+			if err != nil {
+				return err
+			}
+			changes, pinErr := store.Pin(cmd.Context(), args, true)
+
+			if *outputFormat == "json" {
+				if err := cliout.PrintJSON(changes); err != nil {
+					return err
+				}
+				return pinErr
+			}
+
+			if len(changes) == 0 && pinErr == nil {
+				cliout.Success("MCP servers are already at their latest versions")
+			}
+			for _, c := range changes {
+				if c.From == "" {
+					cliout.ItemSuccess("%s: pinned %s@%s", c.Name, c.Package, c.To)
+				} else {
+					cliout.ItemSuccess("%s: %s %s → %s", c.Name, c.Package, c.From, c.To)
+				}
+			}
+			if len(changes) > 0 {
+				cliout.Label("Lock file", store.LockPath)
+			}
+			return pinErr
+		},
+	}
+}
+
+func newMCPCheckCommand(outputFormat *string) *cobra.Command {
+	var (
+		servers   []string
+		timeout   time.Duration
+		showTools bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Start each MCP server and verify it responds",
+		Long: `Start each configured MCP server, perform the MCP initialize handshake and
+tools/list, and report status, latency, server version, and tool count.
+
+Global servers from ~/.copilot/mcp-config.json, project servers from ` + spec.MetadataFile + `,
+and this extension's own server ('azd copilot mcp serve') are checked in
+parallel. Local servers are spawned over stdio and stopped afterwards.
+Disabled servers are skipped unless named with --server.`,
+		Example: `  # Check every server
+  azd copilot mcp check
+
+  # Check only playwright, allowing slow first-time npx installs
+  azd copilot mcp check --server playwright --timeout 2m
+
+  # Machine-readable output
+  azd copilot mcp check --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := mcpconfig.Open()
+			if err != nil {
+				return err
+			}
+			meta, err := spec.LoadMetadata()
+			if err != nil {
+				return err
+			}
+			entries := mcpconfig.WithProject(store.List(), meta.MCP)
+			if exe, err := os.Executable(); err == nil {
+				entries = append(entries, mcpconfig.SelfEntry(exe))
+			}
+
+			results, err := mcpconfig.CheckAll(cmd.Context(), entries, servers, timeout)
+			if err != nil {
+				return err
+			}
+
+			if *outputFormat == "json" {
+				if err := cliout.PrintJSON(results); err != nil {
+					return err
+				}
+			} else {
+				printCheckResults(results, showTools)
+			}
+
+			failed := 0
+			for _, r := range results {
+				if r.Status == mcpconfig.CheckFail {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d MCP server(s) failed to respond", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&servers, "server", nil, "Check only these servers (repeatable)")
+	cmd.Flags().DurationVar(&timeout, "timeout", mcpconfig.DefaultCheckTimeout, "Time allowed per server to start and list its tools")
+	cmd.Flags().BoolVar(&showTools, "tools", false, "List each server's tools")
+
+	return cmd
+}
+
+func printCheckResults(results []mcpconfig.CheckResult, showTools bool) {
+	cliout.Section("🔌", "MCP Server Check")
+	cliout.Newline()
+
+	rows := make([]cliout.TableRow, 0, len(results))
+	for _, r := range results {
+		version := strings.TrimSpace(r.ServerName + " " + r.ServerVersion)
+		tools := ""
+		latency := ""
+		if r.Status == mcpconfig.CheckOK {
+			tools = fmt.Sprintf("%d", r.ToolCount)
+		}
+		if r.Status != mcpconfig.CheckSkipped {
+			latency = r.Latency.Round(time.Millisecond).String()
+		}
+		rows = append(rows, cliout.TableRow{
+			"Name":    r.Name,
+			"Source":  r.Source,
+			"Status":  string(r.Status),
+			"Latency": latency,
+			"Server":  version,
+			"Tools":   tools,
+		})
+	}
+	cliout.Table([]string{"Name", "Source", "Status", "Latency", "Server", "Tools"}, rows)
+	cliout.Newline()
+
+	for _, r := range results {
+		switch {
+		case r.Status == mcpconfig.CheckFail:
+			cliout.ItemError("%s: %s", r.Name, r.Error)
+		case r.Status == mcpconfig.CheckSkipped:
+			cliout.ItemInfo("%s: skipped (%s)", r.Name, r.Error)
+		case showTools && len(r.Tools) > 0:
+			cliout.ItemSuccess("%s: %s", r.Name, strings.Join(r.Tools, ", "))
+		}
+	}
+}
+
+// printDefinitionDiff prints the lines that differ between two definitions
+func printDefinitionDiff(current, desired json.RawMessage) {
+	currentLines := indentLines(current)
+	desiredLines := indentLines(desired)
+
+	seen := make(map[string]int, len(desiredLines))
+	for _, l := range desiredLines {
+		seen[l]++
+	}
+	for _, l := range currentLines {
+		if seen[l] > 0 {
+			seen[l]--
+			continue
+		}
+		fmt.Printf("      %s- %s%s\n", cliout.Red, l, cliout.Reset)
+	}
+
+	seen = make(map[string]int, len(currentLines))
+	for _, l := range currentLines {
+		seen[l]++
+	}
+	for _, l := range desiredLines {
+		if seen[l] > 0 {
+			seen[l]--
+			continue
+		}
+		fmt.Printf("      %s+ %s%s\n", cliout.Green, l, cliout.Reset)
+	}
+}
+
+func indentLines(def json.RawMessage) []string {
+	if len(def) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, def, "", "  "); err != nil {
+		return []string{string(def)}
+	}
+	return strings.Split(buf.String(), "\n")
+}
@@ -6,8 +6,12 @@ package commands
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/azdext"
+	"github.com/jongio/azd-copilot/cli/src/internal/audit"
+	"github.com/jongio/azd-copilot/cli/src/internal/policy"
+	"github.com/jongio/azd-copilot/cli/src/internal/redact"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -20,6 +24,36 @@ func registerGRPCTools(builder *azdext.MCPServerBuilder) {
 	registerComposeTools(builder)
 }
 
+// azd calls that change state are variables so tests can exercise the
+// guardrails without an azd host
+var (
+	runWorkflow = func(ctx context.Context, name string, steps [][]string) error {
+		ctx, client, err := newAzdClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		workflow := &azdext.Workflow{Name: name}
+		for _, args := range steps {
+			workflow.Steps = append(workflow.Steps, &azdext.WorkflowStep{Command: &azdext.WorkflowCommand{Args: args}})
+		}
+		_, err = client.Workflow().Run(ctx, &azdext.RunWorkflowRequest{Workflow: workflow})
+		return err
+	}
+
+	setEnvironmentValue = func(ctx context.Context, envName, key, value string) error {
+		ctx, client, err := newAzdClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		_, err = client.Environment().SetValue(ctx, &azdext.SetEnvRequest{EnvName: envName, Key: key, Value: value})
+		return err
+	}
+)
+
 // newAzdClient creates a new azd gRPC client and returns it along with a context
 // that includes the access token for authentication.
 func newAzdClient(ctx context.Context) (context.Context, *azdext.AzdClient, error) {
@@ -95,10 +129,10 @@ func registerEnvironmentTools(builder *azdext.MCPServerBuilder) {
 				kvMap[kv.Key] = kv.Value
 			}
 
-			return azdext.MCPJSONResult(kvMap), nil
+			return azdext.MCPJSONResult(redact.Load().Map(kvMap)), nil
 		},
 		azdext.MCPToolOptions{
-			Description: "Get all key-value pairs for an azd environment",
+			Description: "Get all key-value pairs for an azd environment. Secret values are masked.",
 			ReadOnly:    true,
 		},
 		mcp.WithString("environment_name", mcp.Required(), mcp.Description("Name of the environment")),
@@ -120,30 +154,59 @@ func registerEnvironmentTools(builder *azdext.MCPServerBuilder) {
 				return azdext.MCPErrorResult("value is required"), nil
 			}
 
-			ctx, client, err := newAzdClient(ctx)
+			// The value is never written to the audit log
+			auditArgs := map[string]interface{}{"environment_name": envName, "key": key}
+
+			pol, err := policy.Load("")
 			if err != nil {
-				return azdext.MCPErrorResult("%s", err), nil
+				recordToolCall("set_environment_value", auditArgs, audit.OutcomeError, err.Error())
+				return azdext.MCPErrorResult("loading policy: %s", err), nil
+			}
+			decision := pol.DecideEnvKey(key)
+
+			if args.OptionalBool("dry_run", false) {
+				recordToolCall("set_environment_value", auditArgs, audit.OutcomeDryRun, string(decision.Action))
+				return azdext.MCPJSONResult(map[string]interface{}{
+					"environment": envName,
+					"key":         key,
+					"decision":    decision,
+				}), nil
+			}
+
+			switch decision.Action {
+			case policy.ActionDeny:
+				recordToolCall("set_environment_value", auditArgs, audit.OutcomeDenied, decision.Rule)
+				return azdext.MCPErrorResult("setting %s is denied by policy (%s)", key, decision.Rule), nil
+			case policy.ActionConfirm:
+				message := fmt.Sprintf("Set %s in azd environment %s?", key, envName)
+				if result := confirmOrReport(ctx, "set_environment_value", auditArgs, message); result != nil {
+					return result, nil
+				}
 			}
-			defer client.Close()
 
-			_, err = client.Environment().SetValue(ctx, &azdext.SetEnvRequest{
-				EnvName: envName,
-				Key:     key,
-				Value:   value,
-			})
-			if err != nil {
+			if err := setEnvironmentValue(ctx, envName, key, value); err != nil {
+				recordToolCall("set_environment_value", auditArgs, audit.OutcomeError, err.Error())
 				return azdext.MCPErrorResult("setting environment value: %s", err), nil
 			}
+			recordToolCall("set_environment_value", auditArgs, audit.OutcomeOK, "")
+			audit.RecordBestEffort(audit.Event{
+				Kind:    audit.KindEnv,
+				Name:    key,
+				Args:    map[string]interface{}{"environment_name": envName},
+				Outcome: audit.OutcomeOK,
+				Detail:  "set_environment_value",
+			})
 
 			return mcp.NewToolResultText(fmt.Sprintf("Successfully set %s in environment %s", key, envName)), nil
 		},
 		azdext.MCPToolOptions{
-			Description: "Set a key-value pair in an azd environment",
+			Description: "Set a key-value pair in an azd environment. Keys are checked against the policy; keys such as AZURE_SUBSCRIPTION_ID need the user's confirmation through the MCP client.",
 			Destructive: true,
 		},
 		mcp.WithString("environment_name", mcp.Required(), mcp.Description("Name of the environment")),
 		mcp.WithString("key", mcp.Required(), mcp.Description("Key to set")),
 		mcp.WithString("value", mcp.Required(), mcp.Description("Value to set")),
+		dryRunParam,
 	)
 }
 
@@ -276,7 +339,7 @@ func registerWorkflowTools(builder *azdext.MCPServerBuilder) {
 				return azdext.MCPErrorResult("steps array is required and must not be empty"), nil
 			}
 
-			var steps []*azdext.WorkflowStep
+			var steps [][]string
 			for i, stepRaw := range stepsRaw {
 				stepMap, ok := stepRaw.(map[string]interface{})
 				if !ok {
@@ -298,39 +361,76 @@ func registerWorkflowTools(builder *azdext.MCPServerBuilder) {
 				if len(cmdArgs) == 0 {
 					return azdext.MCPErrorResult("step %d has no command arguments", i), nil
 				}
-				steps = append(steps, &azdext.WorkflowStep{
-					Command: &azdext.WorkflowCommand{Args: cmdArgs},
-				})
+				steps = append(steps, cmdArgs)
 			}
 
 			if len(steps) == 0 {
 				return azdext.MCPErrorResult("no valid workflow steps found"), nil
 			}
 
-			ctx, client, err := newAzdClient(ctx)
+			auditArgs := map[string]interface{}{"workflow_name": workflowName, "steps": steps}
+
+			pol, err := policy.Load("")
 			if err != nil {
-				return azdext.MCPErrorResult("%s", err), nil
+				recordToolCall("run_workflow", auditArgs, audit.OutcomeError, err.Error())
+				return azdext.MCPErrorResult("loading policy: %s", err), nil
+			}
+
+			type stepPlan struct {
+				Command  string          `json:"command"`
+				Decision policy.Decision `json:"decision"`
+			}
+			plan := make([]stepPlan, 0, len(steps))
+			var denied, risky []string
+			for _, step := range steps {
+				d := pol.DecideWorkflowStep(step)
+				command := "azd " + strings.Join(step, " ")
+				plan = append(plan, stepPlan{Command: command, Decision: d})
+				switch d.Action {
+				case policy.ActionDeny:
+					denied = append(denied, fmt.Sprintf("%s (%s)", command, d.Rule))
+				case policy.ActionConfirm:
+					risky = append(risky, command)
+				}
 			}
-			defer client.Close()
 
-			_, err = client.Workflow().Run(ctx, &azdext.RunWorkflowRequest{
-				Workflow: &azdext.Workflow{
-					Name:  workflowName,
-					Steps: steps,
-				},
-			})
-			if err != nil {
+			if args.OptionalBool("dry_run", false) {
+				recordToolCall("run_workflow", auditArgs, audit.OutcomeDryRun, "")
+				return azdext.MCPJSONResult(map[string]interface{}{
+					"workflow":             workflowName,
+					"steps":                plan,
+					"denied":               len(denied) > 0,
+					"requiresConfirmation": len(risky) > 0,
+				}), nil
+			}
+
+			if len(denied) > 0 {
+				detail := strings.Join(denied, "; ")
+				recordToolCall("run_workflow", auditArgs, audit.OutcomeDenied, detail)
+				return azdext.MCPErrorResult("workflow denied by policy: %s", detail), nil
+			}
+			if len(risky) > 0 {
+				message := fmt.Sprintf("Workflow %q will run:\n%s\n\nProceed?", workflowName, strings.Join(risky, "\n"))
+				if result := confirmOrReport(ctx, "run_workflow", auditArgs, message); result != nil {
+					return result, nil
+				}
+			}
+
+			if err := runWorkflow(ctx, workflowName, steps); err != nil {
+				recordToolCall("run_workflow", auditArgs, audit.OutcomeError, err.Error())
 				return azdext.MCPErrorResult("running workflow: %s", err), nil
 			}
+			recordToolCall("run_workflow", auditArgs, audit.OutcomeOK, "")
 
 			return mcp.NewToolResultText(fmt.Sprintf("Workflow '%s' completed successfully", workflowName)), nil
 		},
 		azdext.MCPToolOptions{
-			Description: "Execute an azd workflow with the given name and steps",
+			Description: "Execute an azd workflow with the given name and steps. Steps are checked against the policy; risky steps such as down, up, provision, and deploy need the user's confirmation through the MCP client.",
 			Destructive: true,
 		},
 		mcp.WithString("workflow_name", mcp.Required(), mcp.Description("Name of the workflow to run")),
 		mcp.WithArray("steps", mcp.Required(), mcp.Description("Array of step objects, each with an 'args' array of command arguments")),
+		dryRunParam,
 	)
 }
 
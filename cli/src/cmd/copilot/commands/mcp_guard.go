@@ -0,0 +1,142 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azdext"
+	"github.com/jongio/azd-copilot/cli/src/internal/audit"
+	"github.com/jongio/azd-copilot/cli/src/internal/redact"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// confirmation is the outcome of asking the user to approve a risky tool call
+type confirmation int
+
+const (
+	confirmed confirmation = iota
+	declined
+	confirmationUnavailable // The client cannot ask the user, so the call is refused
+)
+
+// confirmToolCall asks the user to approve a risky tool call through an
+// MCP elicitation round-trip. Nothing the agent passes counts as approval,
+// so clients without elicitation support get confirmationUnavailable.
+func confirmToolCall(ctx context.Context, message string) confirmation {
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return confirmationUnavailable
+	}
+
+	result, err := srv.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: message,
+			RequestedSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"confirm": map[string]interface{}{
+						"type":        "boolean",
+						"title":       "Proceed",
+						"description": "Run this action",
+					},
+				},
+				"required": []string{"confirm"},
+			},
+		},
+	})
+	if errors.Is(err, server.ErrElicitationNotSupported) || errors.Is(err, server.ErrNoActiveSession) {
+		return confirmationUnavailable
+	}
+	if err != nil || result.Action != mcp.ElicitationResponseActionAccept {
+		return declined
+	}
+	if content, ok := result.Content.(map[string]interface{}); ok {
+		if approved, _ := content["confirm"].(bool); approved {
+			return confirmed
+		}
+	}
+	return declined
+}
+
+// confirmOrReport confirms a risky call, returning nil when it may proceed
+// or the tool result to return when it was declined or could not be
+// confirmed. Without elicitation support the call fails closed.
+func confirmOrReport(ctx context.Context, tool string, auditArgs map[string]interface{}, message string) *mcp.CallToolResult {
+	switch confirmToolCall(ctx, message) {
+	case confirmed:
+		return nil
+	case declined:
+		recordToolCall(tool, auditArgs, audit.OutcomeDeclined, "")
+		return azdext.MCPErrorResult("the user declined: %s", message)
+	default:
+		recordToolCall(tool, auditArgs, audit.OutcomeConfirmationRequired, "")
+		return azdext.MCPErrorResult("confirmation required: %s This MCP client cannot ask the user to confirm, so %s did not run. Ask the user to run it themselves, or to relax the mcp confirm rules in the azd copilot policy.", message, tool)
+	}
+}
+
+// recordToolCall appends an MCP tool call to the audit log. Failures are
+// logged but never fail the tool call.
+func recordToolCall(tool string, args map[string]interface{}, outcome audit.Outcome, detail string) {
+	audit.RecordBestEffort(audit.Event{Kind: audit.KindTool, Name: tool, Args: audit.RedactArgs(args), Outcome: outcome, Detail: detail})
+}
+
+// selfAuditedTools record their own, more specific outcomes
+var selfAuditedTools = map[string]bool{
+	"run_workflow":          true,
+	"set_environment_value": true,
+}
+
+// auditToolCalls is tool middleware that records every other tool call
+// with its arguments (secrets redacted) and result status
+func auditToolCalls(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, req)
+		if selfAuditedTools[req.Params.Name] {
+			return result, err
+		}
+
+		outcome, detail := audit.OutcomeOK, ""
+		switch {
+		case err != nil:
+			outcome, detail = audit.OutcomeError, err.Error()
+		case result != nil && result.IsError:
+			outcome = audit.OutcomeError
+			if len(result.Content) > 0 {
+				if text, ok := result.Content[0].(mcp.TextContent); ok {
+					detail = text.Text
+				}
+			}
+		}
+		recordToolCall(req.Params.Name, req.GetArguments(), outcome, detail)
+		return result, err
+	}
+}
+
+// redactToolResults is tool middleware that masks secrets embedded in the
+// text of every tool result unless --show-secrets is set
+func redactToolResults(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, req)
+		if result == nil {
+			return result, err
+		}
+		r := redact.Load()
+		if r.Show {
+			return result, err
+		}
+		for i, c := range result.Content {
+			if text, ok := c.(mcp.TextContent); ok {
+				text.Text = r.Text(text.Text)
+				result.Content[i] = text
+			}
+		}
+		return result, err
+	}
+}
+
+// dryRunParam is shared by the guarded tools
+var dryRunParam = mcp.WithBoolean("dry_run", mcp.Description("Return the resolved action and policy decision without running it"))
@@ -0,0 +1,218 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/audit"
+	"github.com/jongio/azd-copilot/cli/src/internal/redact"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// elicitAnswer answers elicitation requests with a fixed decision
+type elicitAnswer struct {
+	accept bool
+	asked  int
+}
+
+func (e *elicitAnswer) Elicit(context.Context, mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	e.asked++
+	return &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{
+		Action:  mcp.ElicitationResponseActionAccept,
+		Content: map[string]interface{}{"confirm": e.accept},
+	}}, nil
+}
+
+func newGuardClient(t *testing.T, answer *elicitAnswer) *client.Client {
+	t.Helper()
+	var opts []transport.InProcessOption
+	if answer != nil {
+		opts = append(opts, transport.WithElicitationHandler(answer))
+	}
+	c := client.NewClient(transport.NewInProcessTransportWithOptions(newMCPServer(), opts...))
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	req := mcp.InitializeRequest{}
+	if answer != nil {
+		req.Params.Capabilities.Elicitation = &mcp.ElicitationCapability{}
+	}
+	if _, err := c.Initialize(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestGuardedTools(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("azure.yaml", []byte("name: app\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var ran [][]string
+	var set []string
+	origRun, origSet := runWorkflow, setEnvironmentValue
+	t.Cleanup(func() { runWorkflow, setEnvironmentValue = origRun, origSet })
+	runWorkflow = func(_ context.Context, _ string, steps [][]string) error {
+		ran = append(ran, steps...)
+		return nil
+	}
+	setEnvironmentValue = func(_ context.Context, _, key, _ string) error {
+		set = append(set, key)
+		return nil
+	}
+
+	workflow := func(args ...string) map[string]interface{} {
+		argList := make([]interface{}, len(args))
+		for i, a := range args {
+			argList[i] = a
+		}
+		return map[string]interface{}{
+			"workflow_name": "test",
+			"steps":         []interface{}{map[string]interface{}{"args": argList}},
+		}
+	}
+	with := func(m map[string]interface{}, k string, v interface{}) map[string]interface{} {
+		m[k] = v
+		return m
+	}
+
+	// Denied steps never run
+	c := newGuardClient(t, nil)
+	if out, isErr := callTool(t, c, "run_workflow", workflow("down", "--purge")); !isErr || !strings.Contains(out, "denied by policy") {
+		t.Errorf("down --purge = %s, want denied", out)
+	}
+
+	// Dry run reports the plan without running
+	out, isErr := callTool(t, c, "run_workflow", with(workflow("provision"), "dry_run", true))
+	if isErr || !strings.Contains(out, `"requiresConfirmation": true`) {
+		t.Errorf("dry run = %s", out)
+	}
+
+	// Without elicitation support risky steps fail closed, whatever the
+	// agent claims about the user's approval
+	for _, confirm := range []interface{}{nil, true, "yes"} {
+		args := workflow("provision")
+		if confirm != nil {
+			args = with(args, "confirm", confirm)
+		}
+		if out, isErr := callTool(t, c, "run_workflow", args); !isErr || !strings.Contains(out, "cannot ask the user") {
+			t.Errorf("provision with confirm=%v = %s, want it refused", confirm, out)
+		}
+	}
+
+	// Safe steps run without confirmation
+	if _, isErr := callTool(t, c, "run_workflow", workflow("show")); isErr {
+		t.Error("show should run without confirmation")
+	}
+
+	// Clients that support elicitation are always asked, even when the
+	// agent claims the user confirmed
+	answer := &elicitAnswer{accept: false}
+	c = newGuardClient(t, answer)
+	if out, isErr := callTool(t, c, "set_environment_value", map[string]interface{}{
+		"environment_name": "dev", "key": "AZURE_SUBSCRIPTION_ID", "value": "sub", "confirm": true,
+	}); !isErr || !strings.Contains(out, "declined") || answer.asked != 1 {
+		t.Errorf("declined set = %s (asked %d)", out, answer.asked)
+	}
+	answer.accept = true
+	if _, isErr := callTool(t, c, "set_environment_value", map[string]interface{}{
+		"environment_name": "dev", "key": "AZURE_SUBSCRIPTION_ID", "value": "sub",
+	}); isErr {
+		t.Error("accepted set should run")
+	}
+
+	if len(ran) != 1 || ran[0][0] != "show" {
+		t.Errorf("ran = %v, want [show]", ran)
+	}
+	if len(set) != 1 {
+		t.Errorf("set = %v, want one change", set)
+	}
+
+	// Every call is audited once, plus the environment change, without values
+	data, err := os.ReadFile(audit.DefaultFile)
+	if err != nil {
+		t.Fatalf("audit log not written: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 9 {
+		t.Errorf("audit log has %d lines, want 9:\n%s", len(lines), data)
+	}
+	if !strings.Contains(string(data), `"kind":"env","name":"AZURE_SUBSCRIPTION_ID"`) {
+		t.Error("audit log missing the environment change")
+	}
+	for _, want := range []string{`"outcome":"denied"`, `"outcome":"dry_run"`, `"outcome":"confirmation_required"`, `"outcome":"declined"`, `"outcome":"ok"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("audit log missing %s", want)
+		}
+	}
+	if strings.Contains(string(data), `"sub"`) {
+		t.Error("audit log must not contain environment values")
+	}
+}
+
+func TestAuditToolCalls(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("azure.yaml", []byte("name: app\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newGuardClient(t, nil)
+	if _, isErr := callTool(t, c, "list_agents", nil); isErr {
+		t.Fatal("list_agents failed")
+	}
+	if _, isErr := callTool(t, c, "update_spec_section", map[string]interface{}{
+		"section": "Overview", "content": "x", "expected_hash": "stale", "api_key": "abc",
+	}); !isErr {
+		t.Fatal("update_spec_section without a spec should fail")
+	}
+
+	events, err := audit.Read(audit.Filter{Kinds: []audit.Kind{audit.KindTool}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("audited %d tool calls, want 2: %+v", len(events), events)
+	}
+	if events[0].Name != "list_agents" || events[0].Outcome != audit.OutcomeOK {
+		t.Errorf("first event = %+v", events[0])
+	}
+	failed := events[1]
+	if failed.Name != "update_spec_section" || failed.Outcome != audit.OutcomeError || failed.Detail == "" {
+		t.Errorf("second event = %+v", failed)
+	}
+	if failed.Args["section"] != "Overview" || failed.Args["api_key"] != audit.Redacted {
+		t.Errorf("args = %v, want section kept and api_key redacted", failed.Args)
+	}
+}
+
+func TestRedactToolResults(t *testing.T) {
+	t.Chdir(t.TempDir())
+	conn := "AccountName=st;AccountKey=abc123=="
+	if err := spec.Write("# Spec\n\n## Overview\n\nStorage: " + conn + "\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newGuardClient(t, nil)
+	out, isErr := callTool(t, c, "read_spec", nil)
+	if isErr || strings.Contains(out, "abc123") || !strings.Contains(out, "AccountName=st") {
+		t.Errorf("read_spec = %s, want the account key masked", out)
+	}
+
+	t.Setenv(redact.ShowSecretsEnv, "true")
+	if out, _ := callTool(t, c, "read_spec", nil); !strings.Contains(out, "abc123") {
+		t.Errorf("read_spec with --show-secrets = %s, want the raw value", out)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-core/cliout"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// MCP transports supported by 'mcp serve'
+const (
+	transportStdio = "stdio"
+	transportHTTP  = "http"
+	transportSSE   = "sse"
+)
+
+const (
+	// defaultMCPAddr keeps the shared server on loopback unless asked otherwise
+	defaultMCPAddr = "127.0.0.1:8765"
+
+	// mcpTokenEnv supplies the bearer token without exposing it in the process list
+	mcpTokenEnv = "AZD_COPILOT_MCP_TOKEN"
+
+	// mcpShutdownTimeout bounds how long in-flight requests may finish after cancel
+	mcpShutdownTimeout = 5 * time.Second
+)
+
+// mcpHTTPServer is the subset of the mcp-go HTTP and SSE servers used here
+type mcpHTTPServer interface {
+	http.Handler
+	Shutdown(ctx context.Context) error
+}
+
+// serveMCPHTTP serves s over streamable HTTP or SSE on ln until ctx is
+// cancelled, then shuts down gracefully. When token is set, every request
+// must carry "Authorization: Bearer <token>".
+func serveMCPHTTP(ctx context.Context, s *server.MCPServer, transport string, ln net.Listener, token string) error {
+	httpSrv := &http.Server{ReadHeaderTimeout: 10 * time.Second}
+
+	var mcpSrv mcpHTTPServer
+	var endpoint string
+	switch transport {
+	case transportHTTP:
+		mcpSrv = server.NewStreamableHTTPServer(s, server.WithStreamableHTTPServer(httpSrv))
+		endpoint = "/mcp"
+	case transportSSE:
+		mcpSrv = server.NewSSEServer(s, server.WithHTTPServer(httpSrv))
+		endpoint = "/sse"
+	default:
+		_ = ln.Close()
+		return fmt.Errorf("unsupported transport %q (use %s, %s, or %s)", transport, transportStdio, transportHTTP, transportSSE)
+	}
+	httpSrv.Handler = requireBearer(token, mcpSrv)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- httpSrv.Serve(ln) }()
+	cliout.Info("MCP server listening on http://%s%s (%s)", ln.Addr(), endpoint, transport)
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("MCP server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), mcpShutdownTimeout)
+	defer cancel()
+	if err := mcpSrv.Shutdown(shutdownCtx); err != nil {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("failed to shut down MCP server: %w", err)
+		}
+		// Drop streams that did not finish in time
+		_ = httpSrv.Close()
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("MCP server failed: %w", err)
+	}
+	return nil
+}
+
+// requireBearer rejects requests without the expected bearer token. An
+// empty token disables authentication.
+func requireBearer(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="azd-copilot"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopback reports whether addr only accepts local connections
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,127 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestServeMCPHTTP(t *testing.T) {
+	tests := []struct {
+		transport string
+		endpoint  string
+	}{
+		{transportHTTP, "/mcp"},
+		{transportSSE, "/sse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.transport, func(t *testing.T) {
+			s := server.NewMCPServer("azd-copilot", "test")
+			s.AddTool(mcp.NewTool("ping"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("pong"), nil
+			})
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			url := "http://" + ln.Addr().String() + tt.endpoint
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- serveMCPHTTP(ctx, s, tt.transport, ln, "secret") }()
+
+			// Requests without the token are rejected
+			resp, err := http.Get(url) //nolint:gosec // G107: local test server
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("unauthenticated status = %d, want 401", resp.StatusCode)
+			}
+
+			headers := map[string]string{"Authorization": "Bearer secret"}
+			var c *client.Client
+			if tt.transport == transportSSE {
+				c, err = client.NewSSEMCPClient(url, transport.WithHeaders(headers))
+			} else {
+				c, err = client.NewStreamableHttpClient(url, transport.WithHTTPHeaders(headers))
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer reqCancel()
+			if err := c.Start(reqCtx); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			if _, err := c.Initialize(reqCtx, mcp.InitializeRequest{Params: mcp.InitializeParams{ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION}}); err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+			tools, err := c.ListTools(reqCtx, mcp.ListToolsRequest{})
+			if err != nil {
+				t.Fatalf("ListTools() error = %v", err)
+			}
+			if len(tools.Tools) != 1 || tools.Tools[0].Name != "ping" {
+				t.Errorf("tools = %+v, want [ping]", tools.Tools)
+			}
+
+			// Cancelling the context shuts the server down gracefully,
+			// even with an SSE stream still open
+			cancel()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("serveMCPHTTP() error = %v", err)
+				}
+			case <-time.After(mcpShutdownTimeout + 2*time.Second):
+				t.Fatal("server did not shut down after cancel")
+			}
+			_ = c.Close()
+		})
+	}
+}
+
+func TestServeMCPHTTP_UnknownTransport(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	err = serveMCPHTTP(context.Background(), server.NewMCPServer("x", "1"), "grpc", ln, "")
+	if err == nil || !strings.Contains(err.Error(), "unsupported transport") {
+		t.Errorf("serveMCPHTTP() error = %v, want unsupported transport", err)
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1:8765", true},
+		{"localhost:8765", true},
+		{"[::1]:8765", true},
+		{"0.0.0.0:8765", false},
+		{":8765", false},
+		{"10.0.0.5:80", false},
+		{"bad", false},
+	}
+	for _, tt := range tests {
+		if got := isLoopback(tt.addr); got != tt.want {
+			t.Errorf("isLoopback(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
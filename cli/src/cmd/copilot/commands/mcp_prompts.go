@@ -0,0 +1,115 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// quickActionPrompt exposes a quick action as an MCP prompt. The prompt
+// text comes from the same builders the CLI commands use.
+type quickActionPrompt struct {
+	name        string
+	description string
+	args        []mcp.PromptOption
+	build       func(args map[string]string) (string, error)
+}
+
+var quickActionPrompts = []quickActionPrompt{
+	{
+		name:        "init",
+		description: "Analyze the project and initialize it for Azure deployment",
+		build: func(map[string]string) (string, error) {
+			return initPrompt(), nil
+		},
+	},
+	{
+		name:        "review",
+		description: "Perform a code review of the project",
+		args: []mcp.PromptOption{
+			mcp.WithArgument("focus", mcp.ArgumentDescription("Focus area: security, performance, quality, all (default all)")),
+			mcp.WithArgument("path", mcp.ArgumentDescription("Path to review (default .)")),
+		},
+		build: func(args map[string]string) (string, error) {
+			focus, err := promptChoice(args, "focus", "all", "security", "performance", "quality", "all")
+			if err != nil {
+				return "", err
+			}
+			return reviewPrompt(promptArg(args, "path", "."), focus), nil
+		},
+	},
+	{
+		name:        "fix",
+		description: "Fix build errors and test failures",
+		build: func(map[string]string) (string, error) {
+			return fixPrompt(), nil
+		},
+	},
+	{
+		name:        "optimize",
+		description: "Optimize Azure costs and application performance",
+		args: []mcp.PromptOption{
+			mcp.WithArgument("focus", mcp.ArgumentDescription("Focus: cost, performance, both (default both)")),
+		},
+		build: func(args map[string]string) (string, error) {
+			focus, err := promptChoice(args, "focus", "both", "cost", "performance", "both")
+			if err != nil {
+				return "", err
+			}
+			return optimizePrompt(focus), nil
+		},
+	},
+	{
+		name:        "diagnose",
+		description: "Troubleshoot Azure deployment and runtime issues",
+		args: []mcp.PromptOption{
+			mcp.WithArgument("resource", mcp.ArgumentDescription("Specific resource to diagnose")),
+			mcp.WithArgument("logs", mcp.ArgumentDescription("Include log analysis: true or false (default false)")),
+		},
+		build: func(args map[string]string) (string, error) {
+			logs, err := strconv.ParseBool(promptArg(args, "logs", "false"))
+			if err != nil {
+				return "", fmt.Errorf("logs must be true or false")
+			}
+			return diagnosePrompt(promptArg(args, "resource", ""), logs), nil
+		},
+	},
+}
+
+// registerMCPPrompts registers the quick actions as MCP prompts
+func registerMCPPrompts(s *server.MCPServer) {
+	for _, qa := range quickActionPrompts {
+		opts := append([]mcp.PromptOption{mcp.WithPromptDescription(qa.description)}, qa.args...)
+		s.AddPrompt(mcp.NewPrompt(qa.name, opts...), func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			text, err := qa.build(req.Params.Arguments)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", qa.name, err)
+			}
+			return mcp.NewGetPromptResult(qa.description, []mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+			}), nil
+		})
+	}
+}
+
+func promptArg(args map[string]string, key, defaultValue string) string {
+	if v := args[key]; v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+func promptChoice(args map[string]string, key, defaultValue string, choices ...string) (string, error) {
+	v := promptArg(args, key, defaultValue)
+	if !slices.Contains(choices, v) {
+		return "", fmt.Errorf("%s must be one of %v", key, choices)
+	}
+	return v, nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPPrompts(t *testing.T) {
+	c, err := client.NewInProcessClient(newMCPServer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c.Close() }()
+	ctx := context.Background()
+	if _, err := c.Initialize(ctx, mcp.InitializeRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := c.ListPrompts(ctx, mcp.ListPromptsRequest{})
+	if err != nil {
+		t.Fatalf("ListPrompts() error = %v", err)
+	}
+	if len(list.Prompts) != len(quickActionPrompts) {
+		t.Errorf("ListPrompts() = %d prompts, want %d", len(list.Prompts), len(quickActionPrompts))
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"init", nil, initPrompt(), false},
+		{"review", map[string]string{"focus": "security", "path": "src/api"}, reviewPrompt("src/api", "security"), false},
+		{"review", nil, reviewPrompt(".", "all"), false},
+		{"review", map[string]string{"focus": "style"}, "", true},
+		{"fix", nil, fixPrompt(), false},
+		{"optimize", map[string]string{"focus": "cost"}, optimizePrompt("cost"), false},
+		{"diagnose", map[string]string{"resource": "api", "logs": "true"}, diagnosePrompt("api", true), false},
+		{"diagnose", map[string]string{"logs": "maybe"}, "", true},
+	}
+	for _, tt := range tests {
+		req := mcp.GetPromptRequest{}
+		req.Params.Name = tt.name
+		req.Params.Arguments = tt.args
+		res, err := c.GetPrompt(ctx, req)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("GetPrompt(%s, %v) should fail", tt.name, tt.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("GetPrompt(%s, %v) error = %v", tt.name, tt.args, err)
+			continue
+		}
+		text, ok := res.Messages[0].Content.(mcp.TextContent)
+		if !ok || text.Text != tt.want {
+			t.Errorf("GetPrompt(%s, %v) text differs from the CLI prompt", tt.name, tt.args)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azdext"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerSpecTools registers MCP tools that read and edit the project spec
+// section by section, so agents don't rewrite the whole file
+func registerSpecTools(builder *azdext.MCPServerBuilder) {
+	// Tool: read_spec
+	builder.AddTool("read_spec",
+		func(ctx context.Context, args azdext.ToolArgs) (*mcp.CallToolResult, error) {
+			content, err := spec.Read()
+			if err != nil {
+				return azdext.MCPErrorResult("no spec found at %s; run 'azd copilot build \"description\"' to generate one", spec.Path()), nil
+			}
+
+			if title := args.OptionalString("section", ""); title != "" {
+				section, ok := spec.FindSection(content, title)
+				if !ok {
+					return azdext.MCPErrorResult("section %q not found in %s", title, spec.Path()), nil
+				}
+				return azdext.MCPJSONResult(section), nil
+			}
+
+			return azdext.MCPJSONResult(map[string]interface{}{
+				"path":     spec.Path(),
+				"hash":     spec.Hash(content),
+				"markdown": content,
+				"sections": spec.Parse(content),
+			}), nil
+		},
+		azdext.MCPToolOptions{
+			Description: "Read the project spec (docs/spec.md) as raw markdown and parsed sections, or a single section",
+			ReadOnly:    true,
+		},
+		mcp.WithString("section", mcp.Description("Return only this section, e.g. \"Azure Resources\"")),
+	)
+
+	// Tool: update_spec_section
+	builder.AddTool("update_spec_section",
+		func(ctx context.Context, args azdext.ToolArgs) (*mcp.CallToolResult, error) {
+			title, err := args.RequireString("section")
+			if err != nil {
+				return azdext.MCPErrorResult("missing section: %s", err), nil
+			}
+			body, err := args.RequireString("content")
+			if err != nil {
+				return azdext.MCPErrorResult("missing content: %s", err), nil
+			}
+
+			content, err := spec.Read()
+			if err != nil {
+				return azdext.MCPErrorResult("no spec found at %s", spec.Path()), nil
+			}
+			// Refuse to overwrite edits made after the caller last read the spec
+			if expected := args.OptionalString("expected_hash", ""); expected != "" && expected != spec.Hash(content) {
+				return azdext.MCPErrorResult("spec changed since it was read (hash mismatch); call read_spec and retry"), nil
+			}
+
+			updated, added, err := spec.ReplaceSection(content, title, body)
+			if err != nil {
+				return azdext.MCPErrorResult("%s", err), nil
+			}
+			if err := spec.Write(updated); err != nil {
+				return azdext.MCPErrorResult("%s", err), nil
+			}
+
+			action := "replaced"
+			if added {
+				action = "added"
+			}
+			return azdext.MCPJSONResult(map[string]interface{}{
+				"section": title,
+				"action":  action,
+				"hash":    spec.Hash(updated),
+				"issues":  spec.Validate(updated),
+			}), nil
+		},
+		azdext.MCPToolOptions{
+			Description: "Replace one section of the project spec, leaving the rest of the file untouched. Adds the section if it does not exist.",
+		},
+		mcp.WithString("section", mcp.Required(), mcp.Description("Section title without the leading ##, e.g. \"Azure Resources\"")),
+		mcp.WithString("content", mcp.Required(), mcp.Description("New markdown body for the section, without its heading; use ### or deeper for subheadings")),
+		mcp.WithString("expected_hash", mcp.Description("Hash from read_spec; the update fails if the spec changed since")),
+	)
+
+	// Tool: validate_spec
+	builder.AddTool("validate_spec",
+		func(ctx context.Context, args azdext.ToolArgs) (*mcp.CallToolResult, error) {
+			content, err := spec.Read()
+			if err != nil {
+				return azdext.MCPErrorResult("no spec found at %s", spec.Path()), nil
+			}
+			issues := spec.Validate(content)
+			return azdext.MCPJSONResult(map[string]interface{}{
+				"valid":  !spec.HasErrors(issues),
+				"issues": issues,
+			}), nil
+		},
+		azdext.MCPToolOptions{
+			Description: "Check the project spec for required sections, duplicate sections, unfilled placeholders, and an invalid mode",
+			ReadOnly:    true,
+		},
+	)
+
+	// Tool: get_spec_metadata
+	builder.AddTool("get_spec_metadata",
+		func(ctx context.Context, args azdext.ToolArgs) (*mcp.CallToolResult, error) {
+			info, err := spec.Stat()
+			if err != nil {
+				return azdext.MCPErrorResult("%s", err), nil
+			}
+			return azdext.MCPJSONResult(info), nil
+		},
+		azdext.MCPToolOptions{
+			Description: "Get the spec path, content hash, last approved hash, and section titles",
+			ReadOnly:    true,
+		},
+	)
+}
@@ -0,0 +1,95 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func callTool(t *testing.T, c *client.Client, name string, args map[string]interface{}) (string, bool) {
+	t.Helper()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	res, err := c.CallTool(context.Background(), req)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	var text strings.Builder
+	for _, content := range res.Content {
+		if tc, ok := content.(mcp.TextContent); ok {
+			text.WriteString(tc.Text)
+		}
+	}
+	return text.String(), res.IsError
+}
+
+func TestSpecTools(t *testing.T) {
+	t.Chdir(t.TempDir())
+	original := "# Spec\n\n## Overview\n\n**Name:** app\n**Mode:** prototype\n\n## Azure Resources\n\n- old\n\n## Notes\n\nUser notes.\n"
+	if err := spec.Write(original); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := client.NewInProcessClient(newMCPServer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c.Close() }()
+	if _, err := c.Initialize(context.Background(), mcp.InitializeRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	out, isErr := callTool(t, c, "read_spec", nil)
+	if isErr {
+		t.Fatalf("read_spec failed: %s", out)
+	}
+	var read struct {
+		Hash     string         `json:"hash"`
+		Sections []spec.Section `json:"sections"`
+	}
+	if err := json.Unmarshal([]byte(out), &read); err != nil {
+		t.Fatalf("read_spec output is not JSON: %v\n%s", err, out)
+	}
+	if len(read.Sections) != 4 {
+		t.Errorf("read_spec sections = %d, want 4", len(read.Sections))
+	}
+
+	out, isErr = callTool(t, c, "update_spec_section", map[string]interface{}{
+		"section":       "Azure Resources",
+		"content":       "- Container App\n- Cosmos DB",
+		"expected_hash": read.Hash,
+	})
+	if isErr {
+		t.Fatalf("update_spec_section failed: %s", out)
+	}
+	content, _ := spec.Read()
+	if !strings.Contains(content, "- Cosmos DB") || !strings.Contains(content, "User notes.") || strings.Contains(content, "- old") {
+		t.Errorf("spec after update:\n%s", content)
+	}
+
+	// A stale hash is rejected so hand edits are not clobbered
+	if out, isErr = callTool(t, c, "update_spec_section", map[string]interface{}{
+		"section": "Notes", "content": "overwritten", "expected_hash": read.Hash,
+	}); !isErr {
+		t.Errorf("update with a stale hash should fail: %s", out)
+	}
+
+	out, _ = callTool(t, c, "validate_spec", nil)
+	if !strings.Contains(out, `"valid": false`) || !strings.Contains(out, "Services") {
+		t.Errorf("validate_spec = %s, want missing Services", out)
+	}
+
+	out, _ = callTool(t, c, "get_spec_metadata", nil)
+	if !strings.Contains(out, spec.Hash(content)) {
+		t.Errorf("get_spec_metadata = %s, want current hash", out)
+	}
+}
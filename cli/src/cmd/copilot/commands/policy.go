@@ -0,0 +1,185 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/policy"
+	"github.com/jongio/azd-core/cliout"
+
+	"github.com/spf13/cobra"
+)
+
+// NewPolicyCommand creates the 'policy' subcommand for inspecting tool permission policies.
+func NewPolicyCommand(outputFormat *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Inspect tool permission policies",
+		Long: fmt.Sprintf(`Inspect the tool permission policy applied when Copilot CLI is launched.
+
+Policies are read from ~/.azd/copilot/policy.yaml and %s and merged.
+Deny rules always take precedence over allow rules.
+
+Example policy:
+  allow:
+    commands: ["azd provision --preview", "git status"]
+    edits: ["src/**"]
+  deny:
+    commands: ["az group delete"]
+    edits: [".github/**"]
+  destructive: ["azd down", "az group delete"]`, policy.ProjectFile),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPolicyCheck(*outputFormat, "", "", "", false)
+		},
+	}
+
+	cmd.AddCommand(newPolicyCheckCommand(outputFormat))
+
+	return cmd
+}
+
+func newPolicyCheckCommand(outputFormat *string) *cobra.Command {
+	var (
+		file    string
+		command string
+		edit    string
+		yolo    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Show the effective policy and the Copilot CLI arguments it produces",
+		Example: `  # Show the effective policy
+  azd copilot policy check
+
+  # Check whether a command would be allowed
+  azd copilot policy check --command "az group delete -n rg-dev"
+
+  # Check whether an edit would be allowed
+  azd copilot policy check --edit .github/workflows/ci.yml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPolicyCheck(*outputFormat, file, command, edit, yolo)
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Policy file to check (default: merged user and project policy)")
+	cmd.Flags().StringVar(&command, "command", "", "Evaluate a shell command against the policy")
+	cmd.Flags().StringVar(&edit, "edit", "", "Evaluate a file edit against the policy")
+	cmd.Flags().BoolVar(&yolo, "yolo", false, "Evaluate as if --yolo were passed at launch")
+
+	return cmd
+}
+
+type policyReport struct {
+	Policy                  *policy.Policy   `json:"policy"`
+	Args                    policy.Args      `json:"args"`
+	AutoApprovedDestructive []string         `json:"autoApprovedDestructive,omitempty"`
+	Command                 *policy.Decision `json:"command,omitempty"`
+	Edit                    *policy.Decision `json:"edit,omitempty"`
+}
+
+func runPolicyCheck(outputFormat, file, command, edit string, yolo bool) error {
+	pol, err := policy.Load(file)
+	if err != nil {
+		return err
+	}
+
+	report := policyReport{
+		Policy:                  pol,
+		Args:                    pol.Translate(),
+		AutoApprovedDestructive: pol.AutoApprovedDestructive(yolo),
+	}
+	if command != "" {
+		d := pol.DecideCommand(command)
+		report.Command = &d
+	}
+	if edit != "" {
+		d := pol.DecideEdit(edit)
+		report.Edit = &d
+	}
+
+	if outputFormat == "json" {
+		return cliout.PrintJSON(report)
+	}
+
+	cliout.Section("🛡️", "Tool Permission Policy")
+	cliout.Newline()
+
+	if len(pol.Sources) == 0 {
+		cliout.Info("No policy files found. Every tool use requires confirmation unless --yolo is set.")
+		cliout.Hint(fmt.Sprintf("Create %s to allow or deny specific commands.", policy.ProjectFile))
+	} else {
+		cliout.Label("Sources", strings.Join(pol.Sources, ", "))
+	}
+	cliout.Newline()
+
+	printPolicyList("Allowed commands", pol.Allow.Commands)
+	printPolicyList("Allowed edits", pol.Allow.Edits)
+	printPolicyList("Allowed tools", pol.Allow.Tools)
+	printPolicyList("Denied commands", pol.Deny.Commands)
+	printPolicyList("Denied edits", pol.Deny.Edits)
+	printPolicyList("Denied tools", pol.Deny.Tools)
+	printPolicyList("Destructive patterns", pol.DestructivePatterns())
+
+	if len(report.Args.AllowTools) > 0 || len(report.Args.DenyTools) > 0 {
+		fmt.Println("  Copilot CLI arguments:")
+		for _, t := range report.Args.AllowTools {
+			fmt.Printf("    --allow-tool %q\n", t)
+		}
+		for _, t := range report.Args.DenyTools {
+			fmt.Printf("    --deny-tool %q\n", t)
+		}
+		cliout.Newline()
+	}
+
+	for _, note := range report.Args.Notes {
+		cliout.Warning("%s", note)
+	}
+	if len(report.AutoApprovedDestructive) > 0 {
+		cliout.Warning("Auto-approved destructive commands: %s", strings.Join(report.AutoApprovedDestructive, ", "))
+	}
+
+	if report.Command != nil {
+		cliout.Newline()
+		printDecision("Command", command, *report.Command)
+	}
+	if report.Edit != nil {
+		cliout.Newline()
+		printDecision("Edit", edit, *report.Edit)
+	}
+
+	return nil
+}
+
+func printPolicyList(label string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Printf("  %s:\n", label)
+	for _, item := range items {
+		fmt.Printf("    • %s\n", item)
+	}
+	cliout.Newline()
+}
+
+func printDecision(kind, subject string, d policy.Decision) {
+	rule := "no matching rule"
+	if d.Rule != "" {
+		rule = fmt.Sprintf("rule %q", d.Rule)
+	}
+
+	switch d.Action {
+	case policy.ActionAllow:
+		cliout.Success("%s %q is allowed (%s)", kind, subject, rule)
+	case policy.ActionDeny:
+		cliout.Error("%s %q is denied (%s)", kind, subject, rule)
+	default:
+		cliout.Info("%s %q requires confirmation (%s)", kind, subject, rule)
+	}
+	if d.Destructive {
+		cliout.Warning("%q matches a destructive pattern", subject)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jongio/azd-copilot/cli/src/internal/redact"
 	"github.com/jongio/azd-core/cliout"
 	"github.com/spf13/cobra"
 )
@@ -210,7 +211,7 @@ func showSession(sessionID string) error {
 	planPath := filepath.Join(sessionPath, "plan.md")
 	if content, err := os.ReadFile(planPath); err == nil { //nolint:gosec // G304: planPath is constructed from validated sessionID
 		cliout.Warning("Plan:")
-		fmt.Println(string(content))
+		fmt.Println(redact.Load().Text(string(content)))
 	}
 
 	// Show checkpoints
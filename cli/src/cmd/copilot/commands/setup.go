@@ -0,0 +1,93 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/setup"
+	"github.com/jongio/azd-core/cliout"
+
+	"github.com/spf13/cobra"
+)
+
+// NewSetupCommand creates the 'setup' subcommand for running one-time setup.
+func NewSetupCommand(outputFormat *string) *cobra.Command {
+	var (
+		force   bool
+		offline bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "setup",
+		Short: "Install agents, skills, MCP servers, and required extensions",
+		Long: `Run the one-time setup steps azd copilot performs before launching Copilot CLI:
+
+- Locate the Copilot CLI and detect its version
+- Configure MCP servers in ~/.copilot/mcp-config.json and pin their versions
+- Install agents and skills to ~/.azd/copilot/
+- Install required azd extensions
+
+Results are cached, and later launches skip setup until the extension is
+upgraded. Use --force to rerun every step regardless of the cache.`,
+		Example: `  # Run setup if needed
+  azd copilot setup
+
+  # Rerun every step
+  azd copilot setup --force`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := setup.Run(cmd.Context(), setup.Options{Version: Version, Force: force, Offline: offline})
+			if err != nil {
+				return err
+			}
+
+			if *outputFormat == "json" {
+				if err := cliout.PrintJSON(result); err != nil {
+					return err
+				}
+			} else {
+				printSetupResult(result)
+			}
+
+			if result.Failed() {
+				return fmt.Errorf("setup did not complete")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Rerun every setup step, ignoring the cache")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Configure MCP servers to run from the npm cache only")
+
+	return cmd
+}
+
+func printSetupResult(result *setup.Result) {
+	cliout.Section("🔧", "azd copilot setup")
+	cliout.Newline()
+
+	if result.Cached {
+		cliout.Success("Setup is up to date for version %s", Version)
+		cliout.Hint("Use --force to rerun every step.")
+		cliout.Newline()
+		return
+	}
+
+	for _, step := range result.Steps {
+		switch step.Status {
+		case setup.StatusOK:
+			cliout.ItemSuccess("%s: %s", step.Name, step.Detail)
+		case setup.StatusWarn:
+			cliout.ItemWarning("%s: %s", step.Name, step.Detail)
+		default:
+			cliout.ItemError("%s: %s", step.Name, step.Detail)
+		}
+	}
+	cliout.Newline()
+
+	if result.Failed() {
+		cliout.Hint("Run 'azd copilot doctor' for suggested fixes.")
+		cliout.Newline()
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/assets"
+	"github.com/jongio/azd-core/cliout"
+
+	"github.com/spf13/cobra"
+)
+
+func newSkillsInstallCommand(outputFormat *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "install <path|git-url|archive.tar.gz>",
+		Short: "Install a third-party skill pack",
+		Long: `Install a skill pack from a local directory, a .tar.gz archive (local or
+https), or a git repository (URL, or a local path to a repository).
+
+A pack holds one directory per skill (<name>/SKILL.md) and a ` + assets.PackManifestFile + `
+manifest at its root:
+
+  { "name": "contoso", "version": "1.2.0", "sha256": "<digest>" }
+
+The sha256 is computed over every other file in the pack: the sha256 of the
+sorted "<sha256 of file>  <path>" lines that 'sha256sum' prints. A pack whose
+files do not match is rejected.
+
+Pack skills are installed to ~/.azd/copilot/packs/skills, a layer above the
+embedded skills and below user and project overrides. Installing a pack that
+is already installed replaces it.`,
+		Example: `  azd copilot skills install ./contoso-skills
+  azd copilot skills install contoso-skills-1.2.0.tar.gz
+  azd copilot skills install https://github.com/contoso/copilot-skills.git`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pack, err := assets.InstallPack(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to install skill pack: %w", err)
+			}
+			if *outputFormat == "json" {
+				return cliout.PrintJSON(pack)
+			}
+			cliout.Success("Installed %s %s (%d skills)", pack.Name, pack.Version, len(pack.Skills))
+			cliout.Info("Skills: %s", strings.Join(pack.Skills, ", "))
+			return nil
+		},
+	}
+}
+
+func newSkillsUninstallCommand(outputFormat *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall <pack>",
+		Short: "Remove an installed skill pack",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pack, err := assets.UninstallPack(args[0])
+			if err != nil {
+				return err
+			}
+			if *outputFormat == "json" {
+				return cliout.PrintJSON(pack)
+			}
+			cliout.Success("Uninstalled %s %s (%d skills removed)", pack.Name, pack.Version, len(pack.Skills))
+			return nil
+		},
+	}
+}
+
+func newSkillsUpdateCommand(outputFormat *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "update [pack...]",
+		Short: "Reinstall skill packs from their sources",
+		Long: `Fetch installed skill packs again from the directory, archive, or git
+repository they were installed from, verify them, and replace the installed
+skills. Without arguments, every pack is updated.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			updates, err := assets.UpdatePacks(cmd.Context(), args)
+			if *outputFormat == "json" && err == nil {
+				return cliout.PrintJSON(updates)
+			}
+			for _, u := range updates {
+				if u.From == u.To {
+					cliout.Info("%s %s is up to date", u.Name, u.To)
+				} else {
+					cliout.Success("Updated %s %s → %s", u.Name, u.From, u.To)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if len(updates) == 0 {
+				cliout.Info("No skill packs installed")
+			}
+			return nil
+		},
+	}
+}
+
+func newSkillsPacksCommand(outputFormat *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "packs",
+		Short: "List installed skill packs and their sources",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			packs, err := assets.ListPacks()
+			if err != nil {
+				return err
+			}
+			if *outputFormat == "json" {
+				if packs == nil {
+					packs = []assets.InstalledPack{}
+				}
+				return cliout.PrintJSON(packs)
+			}
+
+			cliout.Section("📦", fmt.Sprintf("Skill Packs (%d)", len(packs)))
+			cliout.Newline()
+			for _, p := range packs {
+				fmt.Printf("  %s%s%s %s  %s\n", cliout.Cyan, p.Name, cliout.Reset, p.Version, cliout.Muted("%s", p.Source))
+				if p.Description != "" {
+					fmt.Printf("    %s\n", truncate(oneLine(p.Description), 100))
+				}
+				fmt.Printf("    %s\n", cliout.Muted("%s", strings.Join(p.Skills, ", ")))
+			}
+			if len(packs) > 0 {
+				cliout.Newline()
+			}
+			cliout.Hint("Install one with 'azd copilot skills install <path|git-url|archive.tar.gz>'")
+			return nil
+		},
+	}
+}
@@ -5,15 +5,17 @@ package commands
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jongio/azd-copilot/cli/src/internal/assets"
+	"github.com/jongio/azd-copilot/cli/src/internal/stack"
 	"github.com/jongio/azd-core/cliout"
 
 	"github.com/spf13/cobra"
 )
 
 // NewSkillsCommand creates the 'skills' subcommand for listing and managing Azure skills.
-func NewSkillsCommand() *cobra.Command {
+func NewSkillsCommand(outputFormat *string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "skills",
 		Short: "List and manage Azure skills",
@@ -33,6 +35,15 @@ Skills provide focused expertise for specific Azure tasks:
 
 	cmd.AddCommand(newSkillsListCommand())
 	cmd.AddCommand(newSkillsShowCommand())
+	cmd.AddCommand(newValidateCommand(outputFormat, "skills", assets.ValidateSkills))
+	cmd.AddCommand(newSkillsSearchCommand(outputFormat))
+	cmd.AddCommand(newSkillsSuggestCommand(outputFormat))
+	cmd.AddCommand(newSkillsInstallCommand(outputFormat))
+	cmd.AddCommand(newSkillsUninstallCommand(outputFormat))
+	cmd.AddCommand(newSkillsUpdateCommand(outputFormat))
+	cmd.AddCommand(newSkillsPacksCommand(outputFormat))
+	cmd.AddCommand(newToggleCommand("skill", true, assets.EnableSkill))
+	cmd.AddCommand(newToggleCommand("skill", false, assets.DisableSkill))
 
 	return cmd
 }
@@ -58,8 +69,127 @@ func newSkillsShowCommand() *cobra.Command {
 	}
 }
 
+func newSkillsSearchCommand(outputFormat *string) *cobra.Command {
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search skills by name, description, and reference docs",
+		Long: `Rank skills by full-text relevance to a query. Names weigh most, then
+descriptions, SKILL.md bodies, and reference docs. Every word of the query
+must match; words match by prefix, so "func" finds "functions".`,
+		Example: `  azd copilot skills search cosmos
+  azd copilot skills search managed identity`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			matches, err := assets.SearchSkills("", strings.Join(args, " "))
+			if err != nil {
+				return fmt.Errorf("failed to search skills: %w", err)
+			}
+			if limit > 0 && len(matches) > limit {
+				matches = matches[:limit]
+			}
+
+			if *outputFormat == "json" {
+				if matches == nil {
+					matches = []assets.SkillMatch{}
+				}
+				return cliout.PrintJSON(matches)
+			}
+
+			cliout.Section("🔍", fmt.Sprintf("Skills matching %q (%d)", strings.Join(args, " "), len(matches)))
+			cliout.Newline()
+			if len(matches) == 0 {
+				cliout.Info("No skills match. Try fewer or shorter words.")
+				return nil
+			}
+			for _, m := range matches {
+				fmt.Printf("  %s%s%s  %s\n", cliout.Cyan, m.Name, cliout.Reset, cliout.Muted("score %.1f", m.Score))
+				if m.Description != "" {
+					fmt.Printf("    %s\n", truncate(oneLine(m.Description), 100))
+				}
+				if m.Snippet != "" {
+					fmt.Printf("    %s\n", cliout.Muted("%s: %s", m.File, m.Snippet))
+				}
+			}
+			cliout.Newline()
+			cliout.Hint("Use 'azd copilot skills show <name>' for details")
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "Maximum results (0 for all)")
+	return cmd
+}
+
+func newSkillsSuggestCommand(outputFormat *string) *cobra.Command {
+	var promptOnly bool
+	cmd := &cobra.Command{
+		Use:   "suggest",
+		Short: "Recommend skills for the current project's stack",
+		Long: `Detect the project's stack from azure.yaml service hosts, Bicep resource
+types and AVM modules, and package.json, go.mod, and requirements.txt
+dependencies, then recommend the skills that apply.
+
+Use --prompt to print a prompt preamble naming the skills, or launch with
+'azd copilot --suggest-skills -p "..."' to add it to the prompt directly.`,
+		Example: `  azd copilot skills suggest
+  azd copilot -p "$(azd copilot skills suggest --prompt) Add caching to the API"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			detected, err := stack.Detect("")
+			if err != nil {
+				return err
+			}
+			suggestions, err := assets.SuggestSkills("", detected)
+			if err != nil {
+				return fmt.Errorf("failed to suggest skills: %w", err)
+			}
+
+			if promptOnly {
+				names := make([]string, len(suggestions))
+				for i, s := range suggestions {
+					names[i] = s.Name
+				}
+				fmt.Println(assets.SkillPrompt(names))
+				return nil
+			}
+			if *outputFormat == "json" {
+				return cliout.PrintJSON(map[string]any{"stack": detected, "suggestions": suggestions})
+			}
+
+			cliout.Section("💡", fmt.Sprintf("Suggested Skills (%d)", len(suggestions)))
+			cliout.Newline()
+			if hosts := detected.Values(stack.KindHost); len(hosts) > 0 {
+				cliout.Label("Hosts", strings.Join(hosts, ", "))
+			}
+			if languages := detected.Values(stack.KindLanguage); len(languages) > 0 {
+				cliout.Label("Languages", strings.Join(languages, ", "))
+			}
+			cliout.Label("Resources", fmt.Sprintf("%d Bicep resources and modules", len(detected.Values(stack.KindResource))))
+			cliout.Label("Packages", fmt.Sprintf("%d dependencies", len(detected.Values(stack.KindDependency))))
+			cliout.Newline()
+
+			for _, s := range suggestions {
+				fmt.Printf("  %s%s%s  %s\n", cliout.Cyan, s.Name, cliout.Reset, truncate(oneLine(s.Description), 80))
+				for _, reason := range s.Reasons {
+					fmt.Printf("    %s\n", cliout.Muted("← %s", reason))
+				}
+			}
+			cliout.Newline()
+			cliout.Hint("Launch with: azd copilot --suggest-skills -p \"<prompt>\"")
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&promptOnly, "prompt", false, "Print only a prompt preamble naming the suggested skills")
+	return cmd
+}
+
+// oneLine collapses whitespace, including newlines in folded YAML descriptions
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
 func listSkills() error {
-	skills, err := assets.ListSkills()
+	skills, err := assets.ListLayeredSkills("")
 	if err != nil {
 		return fmt.Errorf("failed to list skills: %w", err)
 	}
@@ -67,12 +197,11 @@ func listSkills() error {
 	cliout.Section("⚡", fmt.Sprintf("Available Skills (%d)", len(skills)))
 	cliout.Newline()
 
-	// Calculate max name length for alignment
-	maxLen := 0
+	// Calculate max name and layer length for alignment
+	maxLen, maxLayer := 0, 0
 	for _, skill := range skills {
-		if len(skill.Name) > maxLen {
-			maxLen = len(skill.Name)
-		}
+		maxLen = max(maxLen, len(skill.Name))
+		maxLayer = max(maxLayer, len(stateLabel(skill.Layer, skill.Shadows, skill.Merged, skill.Disabled)))
 	}
 
 	// Print skills
@@ -85,11 +214,18 @@ func listSkills() error {
 		if len(desc) > 60 {
 			desc = desc[:57] + "..."
 		}
-		fmt.Printf("  %s%-*s%s  %s\n", cliout.Cyan, maxLen, skill.Name, cliout.Reset, desc)
+		color := cliout.Cyan
+		if skill.Disabled {
+			color = cliout.Gray
+		}
+		fmt.Printf("  %s%-*s%s  %-*s  %s\n", color, maxLen, skill.Name, cliout.Reset,
+			maxLayer, stateLabel(skill.Layer, skill.Shadows, skill.Merged, skill.Disabled), desc)
 	}
 
 	cliout.Newline()
 	cliout.Info("Skills are automatically available during copilot sessions.")
+	cliout.Hint("Override skills in ~/.azd/copilot/custom/skills or .azd/copilot/skills")
+	cliout.Hint("Turn skills off for this project with 'azd copilot skills disable <name>'")
 
 	return nil
 }
@@ -108,6 +244,10 @@ func showSkill(name string) error {
 	}
 
 	cliout.Label("Path", skill.Path)
+	cliout.Label("Layer", layerLabel(skill.Layer, skill.Shadows, skill.Merged))
+	if skill.Disabled {
+		cliout.Label("State", "disabled in this project")
+	}
 
 	cliout.Newline()
 	cliout.Info("Skills are automatically available during copilot sessions.")
@@ -0,0 +1,48 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+	"github.com/jongio/azd-core/cliout"
+
+	"github.com/spf13/cobra"
+)
+
+// newToggleCommand creates 'agents|skills enable|disable <name>'. kind is
+// "agent" or "skill".
+func newToggleCommand(kind string, enable bool, set func(name string) (bool, error)) *cobra.Command {
+	verb, state := "disable", "disabled"
+	short := fmt.Sprintf("Leave a %s out of sessions in this project", kind)
+	if enable {
+		verb, state = "enable", "enabled"
+		short = fmt.Sprintf("Re-enable a %s disabled in this project", kind)
+	}
+	return &cobra.Command{
+		Use:   verb + " <name>",
+		Short: short,
+		Long: fmt.Sprintf(`%s
+
+The state is stored in the project's %s under "%ss": {"disable": [...]}.
+Sessions load a filtered view of agents and skills without the disabled ones.`, short, spec.MetadataFile, kind),
+		Example: fmt.Sprintf("  azd copilot %ss %s <name>", kind, verb),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			changed, err := set(args[0])
+			if err != nil {
+				return err
+			}
+			title := strings.ToUpper(kind[:1]) + kind[1:]
+			if !changed {
+				cliout.Info("%s %s is already %s", title, args[0], state)
+				return nil
+			}
+			cliout.Success("%s %s is %s for this project", title, args[0], state)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/assets"
+	"github.com/jongio/azd-core/cliout"
+
+	"github.com/spf13/cobra"
+)
+
+// newValidateCommand creates 'agents validate' or 'skills validate'. kind is
+// "agents" or "skills".
+func newValidateCommand(outputFormat *string, kind string, validate func(assets.ValidateOptions) ([]assets.Finding, error)) *cobra.Command {
+	var (
+		layers []string
+		dir    string
+		format string
+	)
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: fmt.Sprintf("Lint %s for frontmatter, names, links, and size", kind),
+		Long: fmt.Sprintf(`Lint %s in the embedded, pack (~/.azd/copilot/packs/%s), user
+(~/.azd/copilot/custom/%s), and project (.azd/copilot/%s) layers, or in any
+directory with --dir.
+
+Checks:
+  frontmatter     name and description are present and the YAML parses
+  unknown-tool    agent tools are Copilot CLI tools or MCP tools (server/tool)
+  name-mismatch   the frontmatter name matches the file or directory name
+  broken-link     relative links to markdown files resolve
+  unknown-skill   skills referenced from agent skill tables exist
+  duplicate       names are unique, and overrides of lower layers are noted
+  oversized       files stay under the file token budget (default %d)
+  budget          agents with their skills stay under the agent token budget
+                  (default %d); set both in .copilot.json "budget"
+
+Exits with an error when any error-level finding is reported.`, kind, kind, kind, kind,
+			assets.DefaultBudgetLimits.FileTokens, assets.DefaultBudgetLimits.AgentTokens),
+		Example: fmt.Sprintf(`  azd copilot %[1]s validate
+  azd copilot %[1]s validate --layer project
+  azd copilot %[1]s validate --dir ./my-%[1]s --format sarif > results.sarif`, kind),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := assets.ValidateOptions{Dir: dir}
+			for _, l := range layers {
+				layer := assets.Layer(l)
+				if layer != assets.LayerEmbedded && !slices.Contains(assets.OverrideLayers, layer) {
+					return fmt.Errorf("invalid --layer %q (use embedded, pack, user, or project)", l)
+				}
+				opts.Layers = append(opts.Layers, layer)
+			}
+
+			findings, err := validate(opts)
+			if err != nil {
+				return fmt.Errorf("failed to validate %s: %w", kind, err)
+			}
+
+			switch {
+			case format == "sarif":
+				if err := writeSARIF(os.Stdout, findings); err != nil {
+					return err
+				}
+			case format != "" && format != "text":
+				return fmt.Errorf("invalid --format %q (use text or sarif)", format)
+			case *outputFormat == "json":
+				if findings == nil {
+					findings = []assets.Finding{}
+				}
+				if err := cliout.PrintJSON(findings); err != nil {
+					return err
+				}
+			default:
+				printFindings(kind, findings)
+			}
+
+			if assets.HasErrors(findings) {
+				return fmt.Errorf("%s validation failed", kind)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&layers, "layer", nil, "Layers to validate: embedded, pack, user, project (default all)")
+	cmd.Flags().StringVar(&dir, "dir", "", "Validate this directory instead of the installed layers")
+	cmd.Flags().StringVar(&format, "format", "text", "Report format: text or sarif")
+	return cmd
+}
+
+func printFindings(kind string, findings []assets.Finding) {
+	cliout.Section("🔎", fmt.Sprintf("Validate %s", kind))
+	cliout.Newline()
+	if len(findings) == 0 {
+		cliout.Success("No problems found")
+		return
+	}
+
+	counts := map[assets.Severity]int{}
+	for _, f := range findings {
+		counts[f.Severity]++
+		location := f.Path
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", f.Path, f.Line)
+		}
+		msg := fmt.Sprintf("%s  %s %s", location, f.Message, cliout.Muted("[%s]", f.Rule))
+		switch f.Severity {
+		case assets.SeverityError:
+			cliout.ItemError("%s", msg)
+		case assets.SeverityWarning:
+			cliout.ItemWarning("%s", msg)
+		default:
+			cliout.ItemInfo("%s", msg)
+		}
+	}
+
+	cliout.Newline()
+	cliout.Info("%d errors, %d warnings, %d notes", counts[assets.SeverityError], counts[assets.SeverityWarning], counts[assets.SeverityNote])
+}
+
+// SARIF 2.1.0 subset used for code scanning uploads
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// writeSARIF writes findings as a SARIF 2.1.0 log
+func writeSARIF(w io.Writer, findings []assets.Finding) error {
+	ids := make([]string, 0, len(assets.Rules))
+	for id := range assets.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	rules := make([]sarifRule, 0, len(ids))
+	for _, id := range ids {
+		rules = append(rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: assets.Rules[id]}})
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, f := range findings {
+		loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: strings.ReplaceAll(f.Path, "\\", "/")}}
+		if f.Line > 0 {
+			loc.Region = &sarifRegion{StartLine: f.Line}
+		}
+		results = append(results, sarifResult{
+			RuleID:    f.Rule,
+			Level:     string(f.Severity),
+			Message:   sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{{PhysicalLocation: loc}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "azd-copilot",
+				Version:        Version,
+				InformationURI: "https://github.com/jongio/azd-copilot",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(log); err != nil {
+		return fmt.Errorf("failed to write SARIF: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/assets"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	findings := []assets.Finding{
+		{Rule: assets.RuleUnknownTool, Severity: assets.SeverityError, Message: `unknown tool "x"`, Path: `.azd\copilot\agents\a.md`, Line: 1},
+		{Rule: assets.RuleOversized, Severity: assets.SeverityWarning, Message: "too big", Path: "embedded:skills/big/SKILL.md"},
+	}
+	var buf bytes.Buffer
+	if err := writeSARIF(&buf, findings); err != nil {
+		t.Fatalf("writeSARIF() error = %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("SARIF output is not JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("log = %+v, want one SARIF 2.1.0 run", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(assets.Rules) {
+		t.Errorf("rules = %d, want %d", len(run.Tool.Driver.Rules), len(assets.Rules))
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("results = %d, want 2", len(run.Results))
+	}
+	first := run.Results[0].Locations[0].PhysicalLocation
+	if run.Results[0].Level != "error" || first.ArtifactLocation.URI != ".azd/copilot/agents/a.md" || first.Region == nil || first.Region.StartLine != 1 {
+		t.Errorf("first result = %+v", run.Results[0])
+	}
+	if run.Results[1].Locations[0].PhysicalLocation.Region != nil {
+		t.Error("findings without a line should have no region")
+	}
+}
@@ -4,13 +4,20 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/azdext"
 	"github.com/jongio/azd-copilot/cli/src/cmd/copilot/commands"
 	"github.com/jongio/azd-copilot/cli/src/internal/assets"
 	"github.com/jongio/azd-copilot/cli/src/internal/copilot"
+	"github.com/jongio/azd-copilot/cli/src/internal/mcpconfig"
+	"github.com/jongio/azd-copilot/cli/src/internal/policy"
+	"github.com/jongio/azd-copilot/cli/src/internal/redact"
+	"github.com/jongio/azd-copilot/cli/src/internal/setup"
 	selfskills "github.com/jongio/azd-copilot/cli/src/internal/skills"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+	"github.com/jongio/azd-copilot/cli/src/internal/stack"
 	"github.com/jongio/azd-core/cliout"
 	"github.com/jongio/azd-core/logutil"
 
@@ -20,17 +27,22 @@ import (
 
 var (
 	structuredLogs bool
+	showSecrets    bool
 
 	// Root command flags for copilot session
-	prompt     string
-	resume     bool
-	yolo       bool
-	agent      string
-	model      string
-	addDirs    []string
-	verbose    bool
-	noBanner   bool
-	forceColor bool
+	prompt        string
+	resume        bool
+	yolo          bool
+	agent         string
+	model         string
+	addDirs       []string
+	verbose       bool
+	noBanner      bool
+	forceColor    bool
+	policyFile    string
+	refresh       bool
+	offline       bool
+	suggestSkills bool
 
 	// SDK extension context
 	extCtx *azdext.ExtensionContext
@@ -39,6 +51,7 @@ var (
 func main() {
 	// Set version in copilot package
 	copilot.Version = commands.Version
+	assets.Version = commands.Version
 
 	rootCmd := newRootCmd()
 
@@ -90,6 +103,11 @@ When run without subcommands, starts an interactive Copilot session with Azure c
 			_ = os.Setenv("FORCE_COLOR", "1")
 		}
 
+		// Reveal secrets in output; MCP servers started by the session inherit this
+		if showSecrets {
+			_ = os.Setenv(redact.ShowSecretsEnv, "true")
+		}
+
 		// Set global output format and debug mode
 		if extCtx.Debug {
 			_ = os.Setenv("AZD_DEBUG", "true")
@@ -128,6 +146,7 @@ When run without subcommands, starts an interactive Copilot session with Azure c
 	// Add extension-specific persistent flags
 	rootCmd.PersistentFlags().BoolVar(&structuredLogs, "structured-logs", false, "Enable structured JSON logging to stderr")
 	rootCmd.PersistentFlags().BoolVar(&forceColor, "color", false, "Force colored output")
+	rootCmd.PersistentFlags().BoolVar(&showSecrets, "show-secrets", false, "Show secret values in output and pass them to Copilot sessions")
 
 	// Add root command flags for copilot session
 	rootCmd.Flags().StringVarP(&prompt, "prompt", "p", "", "Run with a specific prompt")
@@ -138,19 +157,28 @@ When run without subcommands, starts an interactive Copilot session with Azure c
 	rootCmd.Flags().StringSliceVar(&addDirs, "add-dir", nil, "Additional directories to include")
 	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
 	rootCmd.Flags().BoolVar(&noBanner, "no-banner", false, "Skip the banner")
+	rootCmd.Flags().BoolVar(&suggestSkills, "suggest-skills", false, "Name the skills suggested for this project's stack in the prompt (with -p)")
+	rootCmd.Flags().BoolVar(&refresh, "refresh", false, "Rerun setup (agents, skills, MCP servers, extensions) before launching")
+	rootCmd.Flags().BoolVar(&offline, "offline", os.Getenv("AZD_COPILOT_OFFLINE") == "true", "Run MCP servers from the npm cache only (env: AZD_COPILOT_OFFLINE=true)")
+	rootCmd.Flags().StringVar(&policyFile, "policy", "", "Tool permission policy file (default: merged ~/.azd/copilot/policy.yaml and "+policy.ProjectFile+")")
 
 	// Register all commands
 	rootCmd.AddCommand(
 		commands.NewVersionCommand(&extCtx.OutputFormat),
 		commands.NewListenCommand(),
-		commands.NewAgentsCommand(),
-		commands.NewSkillsCommand(),
+		commands.NewAgentsCommand(&extCtx.OutputFormat),
+		commands.NewSkillsCommand(&extCtx.OutputFormat),
+		commands.NewAssetsCommand(&extCtx.OutputFormat),
 		commands.NewSessionsCommand(),
 		commands.NewContextCommand(),
 		commands.NewCheckpointsCommand(),
-		commands.NewBuildCommand(),
+		commands.NewBuildCommand(&extCtx.OutputFormat),
 		commands.NewSpecCommand(),
-		commands.NewMCPCommand(),
+		commands.NewMCPCommand(&extCtx.OutputFormat),
+		commands.NewPolicyCommand(&extCtx.OutputFormat),
+		commands.NewAuditCommand(&extCtx.OutputFormat),
+		commands.NewDoctorCommand(&extCtx.OutputFormat),
+		commands.NewSetupCommand(&extCtx.OutputFormat),
 		commands.NewMetadataCommand("1.0", "jongio.azd.copilot", newRootCmd),
 		// Quick actions
 		commands.NewInitCommand(),
@@ -164,13 +192,34 @@ When run without subcommands, starts an interactive Copilot session with Azure c
 }
 
 func runCopilotSession(cmd *cobra.Command) error {
+	if agent != "" && slices.Contains(assets.DisabledAgents(), agent) {
+		return fmt.Errorf("agent %s is disabled in this project; run 'azd copilot agents enable %s'", agent, agent)
+	}
+
 	// Print banner unless --no-banner or --prompt
 	if !noBanner && prompt == "" {
 		printBanner()
 	}
 
+	// Run setup unless the cache shows it already completed for this version
+	setupResult, err := setup.Run(cmd.Context(), setup.Options{Version: commands.Version, Force: refresh, Offline: offline})
+	if err != nil {
+		return err
+	}
+
+	// In offline mode, fail before launch if any MCP package would need the network
+	if offline {
+		store, err := mcpconfig.Open()
+		if err != nil {
+			return err
+		}
+		if err := store.CheckOffline(cmd.Context()); err != nil {
+			return err
+		}
+	}
+
 	// Check if Copilot CLI is installed
-	if !copilot.IsCopilotInstalled() {
+	if setupResult.CopilotPath == nil {
 		cliout.Error("GitHub Copilot CLI not found!")
 		cliout.Newline()
 		fmt.Println("Install with one of:")
@@ -180,36 +229,59 @@ func runCopilotSession(cmd *cobra.Command) error {
 		return fmt.Errorf("copilot CLI not installed")
 	}
 
-	// Configure MCP servers for Copilot CLI
-	if err := copilot.ConfigureMCPServer(); err != nil {
+	if setupResult.Failed() {
 		if extCtx.Debug {
-			fmt.Fprintf(os.Stderr, "Warning: failed to configure MCP servers: %v\n", err)
+			for _, step := range setupResult.Steps {
+				if step.Status == setup.StatusFail {
+					fmt.Fprintf(os.Stderr, "Warning: %s setup failed: %s\n", step.Name, step.Detail)
+				}
+			}
+		} else {
+			fmt.Fprintln(os.Stderr, "Warning: setup did not complete. Run 'azd copilot doctor' for details.")
 		}
 	}
 
-	// Install agents and skills to ~/.azd/copilot/
-	assetDirs, err := setupAgentsAndSkills()
-	if err != nil {
-		if extCtx.Debug {
-			fmt.Fprintf(os.Stderr, "Warning: failed to install agents/skills: %v\n", err)
+	// Build project context
+	projectContext := buildProjectContext()
+
+	// Point the prompt at skills suggested for the project's stack
+	launchPrompt := prompt
+	if suggestSkills && prompt != "" && projectContext != nil {
+		if preamble := assets.SkillPrompt(projectContext.Skills); preamble != "" {
+			launchPrompt = preamble + "\n\n" + prompt
 		}
 	}
 
-	// Build project context
-	projectContext := buildProjectContext()
+	// Project-scoped MCP servers are handed to this session only
+	meta, err := spec.LoadMetadata()
+	if err != nil {
+		return err
+	}
+	mcpSession, err := mcpconfig.PrepareSession(meta.MCP)
+	if err != nil {
+		return err
+	}
+	defer mcpSession.Close()
 
-	// Launch Copilot CLI
-	return copilot.Launch(cmd.Context(), copilot.Options{
-		Prompt:         prompt,
+	opts := copilot.Options{
+		Command:        cmd.CommandPath(),
+		Prompt:         launchPrompt,
 		Resume:         resume,
 		Yolo:           yolo,
 		Agent:          agent,
 		Model:          model,
-		AddDirs:        append(addDirs, assetDirs...),
+		AddDirs:        addDirs,
 		Verbose:        verbose,
 		Debug:          extCtx.Debug,
 		ProjectContext: projectContext,
-	})
+		CopilotPath:    setupResult.CopilotPath,
+
+		AdditionalMCPConfig: mcpSession.ConfigFile,
+		DisableMCPServers:   mcpSession.Disable,
+	}
+
+	// Launch Copilot CLI with the tool permission policy applied
+	return commands.LaunchSession(cmd.Context(), opts, policyFile, setupResult.AssetDirs)
 }
 
 func printBanner() {
@@ -230,26 +302,6 @@ func printBanner() {
 	cliout.Newline()
 }
 
-func setupAgentsAndSkills() ([]string, error) {
-	var dirs []string
-
-	// Install agents
-	agentsDir, _, err := assets.InstallAgents()
-	if err != nil {
-		return nil, fmt.Errorf("failed to install agents: %w", err)
-	}
-	dirs = append(dirs, agentsDir)
-
-	// Install skills
-	skillsDir, _, err := assets.InstallSkills()
-	if err != nil {
-		return nil, fmt.Errorf("failed to install skills: %w", err)
-	}
-	dirs = append(dirs, skillsDir)
-
-	return dirs, nil
-}
-
 func buildProjectContext() *copilot.ProjectContext {
 	// Try to detect azd project context
 	// This is a simplified version - full implementation would use azdext client
@@ -281,5 +333,14 @@ func buildProjectContext() *copilot.ProjectContext {
 		}
 	}
 
+	// Suggest skills for the detected stack
+	if detected, err := stack.Detect(""); err == nil {
+		if suggestions, err := assets.SuggestSkills("", detected); err == nil {
+			for _, s := range suggestions {
+				ctx.Skills = append(ctx.Skills, s.Name)
+			}
+		}
+	}
+
 	return ctx
 }
@@ -7,11 +7,9 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
-	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 
-	"github.com/jongio/azd-core/fileutil"
 	"gopkg.in/yaml.v3"
 )
 
@@ -24,44 +22,29 @@ type AgentInfo struct {
 	Description string   `yaml:"description"`
 	Tools       []string `yaml:"tools"`
 	FilePath    string
+	Layer       Layer   // Layer the agent resolves to
+	Shadows     []Layer // Lower layers it overrides
+	Merged      bool    // Extends the lower layer instead of replacing it
+	Disabled    bool    // Disabled in the project's .copilot.json
 }
 
-// InstallAgents extracts embedded agents to ~/.azd/copilot/agents/
-func InstallAgents() (string, int, error) {
-	home, err := os.UserHomeDir()
+// InstallAgents installs embedded agents to ~/.azd/copilot/agents/,
+// writing only changed files and pruning ones no longer shipped
+func InstallAgents() (*InstallReport, error) {
+	destDir, err := AgentsDir()
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to get home directory: %w", err)
-	}
-
-	destDir := filepath.Join(home, ".azd", "copilot", "agents")
-	if err := fileutil.EnsureDir(destDir); err != nil {
-		return "", 0, fmt.Errorf("failed to create agents directory: %w", err)
+		return nil, err
 	}
-
-	entries, err := fs.ReadDir(embeddedAgents, "agents")
+	files, err := embeddedAgentFiles()
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to read embedded agents: %w", err)
+		return nil, err
 	}
-
-	installed := 0
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
-			continue
-		}
-
-		data, err := embeddedAgents.ReadFile("agents/" + entry.Name())
-		if err != nil {
-			continue
-		}
-
-		destPath := filepath.Join(destDir, entry.Name())
-		if err := fileutil.AtomicWriteFile(destPath, data, 0644); err != nil {
-			continue
-		}
-		installed++
+	report, err := installFiles(destDir, files)
+	if err != nil {
+		return report, fmt.Errorf("failed to install agents: %w", err)
 	}
-
-	return destDir, installed, nil
+	report.Count = len(files)
+	return report, nil
 }
 
 // ListAgents returns information about all embedded agents
@@ -83,15 +66,36 @@ func ListAgents() ([]AgentInfo, error) {
 		}
 
 		agent := parseAgentInfo(entry.Name(), data)
+		agent.Layer = LayerEmbedded
 		agents = append(agents, agent)
 	}
 
 	return agents, nil
 }
 
-// GetAgent returns information about a specific agent
+// ListLayeredAgents returns agents after applying user and project
+// overrides, with the layer each one comes from and whether it is disabled
+func ListLayeredAgents(projectRoot string) ([]AgentInfo, error) {
+	resolved, err := ResolveAgents(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	disabled := DisabledAgents()
+	agents := make([]AgentInfo, 0, len(resolved))
+	for _, r := range resolved {
+		main := agentKind.main(r.Name)
+		agent := parseAgentInfo(main, r.Files[main])
+		agent.Layer, agent.Shadows, agent.Merged = r.Layer, r.Shadows, r.Merged
+		agent.Disabled = slices.Contains(disabled, r.Name)
+		agents = append(agents, agent)
+	}
+	return agents, nil
+}
+
+// GetAgent returns information about a specific agent, including overrides
+// for the current project
 func GetAgent(name string) (*AgentInfo, error) {
-	agents, err := ListAgents()
+	agents, err := ListLayeredAgents("")
 	if err != nil {
 		return nil, err
 	}
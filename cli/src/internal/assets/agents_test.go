@@ -188,15 +188,15 @@ func TestInstallAgents(t *testing.T) {
 	// Note: Actually installing to ~/.azd/copilot/agents/ may modify user's system
 	// so we just verify the function works without error
 
-	dir, count, err := InstallAgents()
+	report, err := InstallAgents()
 	if err != nil {
 		t.Logf("InstallAgents() error = %v (may be expected if no home directory)", err)
 		return
 	}
 
-	t.Logf("InstallAgents() installed %d agents to %s", count, dir)
+	t.Logf("InstallAgents() installed %d agents to %s (%s)", report.Count, report.Dir, report.Summary())
 
-	if count == 0 {
+	if report.Count == 0 {
 		t.Log("InstallAgents() installed 0 agents (may be expected if already installed)")
 	}
 }
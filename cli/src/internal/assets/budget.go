@@ -0,0 +1,382 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+	"github.com/jongio/azd-core/fileutil"
+)
+
+// DefaultBudgetLimits are the token thresholds used unless the project's
+// .copilot.json sets "budget"
+var DefaultBudgetLimits = spec.BudgetLimits{FileTokens: 8000, AgentTokens: 40000}
+
+// BudgetHistoryFile records the embedded asset budget of each installed
+// extension version, for diffs across upgrades
+const BudgetHistoryFile = "budget-history.json"
+
+// maxBudgetHistory is the number of versions kept in the history
+const maxBudgetHistory = 10
+
+// File kinds in a budget report
+const (
+	BudgetAgent     = "agent"
+	BudgetSkill     = "skill"     // SKILL.md, loaded when the skill is invoked
+	BudgetReference = "reference" // Other skill files, read on demand
+)
+
+// FileBudget is the estimated token count of one asset file
+type FileBudget struct {
+	Path   string `json:"path"` // Install-relative, prefixed with agents/ or skills/
+	Kind   string `json:"kind"`
+	Asset  string `json:"asset"`
+	Layer  Layer  `json:"layer"`
+	Tokens int    `json:"tokens"`
+}
+
+// AgentBudget is the context an agent consumes when it runs
+type AgentBudget struct {
+	Agent       string   `json:"agent"`
+	AgentTokens int      `json:"agentTokens"` // The agent file itself
+	Catalog     int      `json:"catalogTokens"`
+	Skills      []string `json:"skills"`      // Skills the agent references
+	SkillTokens int      `json:"skillTokens"` // Their SKILL.md files
+	Total       int      `json:"total"`       // Agent + catalog + referenced skills
+	OnDemand    int      `json:"onDemand"`    // Reference files of those skills, read only when needed
+}
+
+// BudgetDiff compares the embedded budget with a previous extension version
+type BudgetDiff struct {
+	Version string         `json:"version"`
+	Before  int            `json:"before"`
+	After   int            `json:"after"`
+	Changed map[string]int `json:"changed"` // Path → token delta; added and removed files included
+}
+
+// BudgetReport is the context budget of the layered agents and skills
+type BudgetReport struct {
+	Version  string            `json:"version"`
+	Limits   spec.BudgetLimits `json:"limits"`
+	Catalog  int               `json:"catalogTokens"` // Skill names and descriptions, always loaded
+	Total    int               `json:"total"`         // Every agent, skill, and reference file
+	Files    []FileBudget      `json:"files"`         // Largest first
+	Agents   []AgentBudget     `json:"agents"`        // Largest total first
+	Warnings []string          `json:"warnings"`
+	Baseline *BudgetDiff       `json:"baseline,omitempty"`
+}
+
+// budgetSnapshot is one version's embedded budget in the history file
+type budgetSnapshot struct {
+	Version    string         `json:"version"`
+	RecordedAt time.Time      `json:"recordedAt"`
+	Files      map[string]int `json:"files"`
+}
+
+// EstimateTokens approximates the token count of text for BPE tokenizers
+// used by current models, without a vocabulary. Words cost a token plus one
+// per 8 characters, punctuation runs a token per 4 characters, and
+// whitespace containing a newline one token. Expect roughly ±20% on prose
+// and markdown.
+func EstimateTokens(data []byte) int {
+	tokens := 0
+	word, punct := 0, 0
+	newline := false
+	flush := func() {
+		if word > 0 {
+			tokens += 1 + word/8
+		}
+		if punct > 0 {
+			tokens += 1 + (punct-1)/4
+		}
+		word, punct = 0, 0
+	}
+	for _, r := range string(data) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if punct > 0 {
+				flush()
+			}
+			if newline {
+				tokens++
+				newline = false
+			}
+			word++
+		case unicode.IsSpace(r):
+			flush()
+			newline = newline || r == '\n'
+		default:
+			if word > 0 {
+				flush()
+			}
+			if newline {
+				tokens++
+				newline = false
+			}
+			punct++
+		}
+	}
+	flush()
+	return tokens
+}
+
+// LoadBudgetLimits returns the thresholds for the current project
+func LoadBudgetLimits() spec.BudgetLimits {
+	limits := DefaultBudgetLimits
+	m, _ := spec.LoadMetadata()
+	if m.Budget != nil {
+		if m.Budget.FileTokens > 0 {
+			limits.FileTokens = m.Budget.FileTokens
+		}
+		if m.Budget.AgentTokens > 0 {
+			limits.AgentTokens = m.Budget.AgentTokens
+		}
+	}
+	return limits
+}
+
+// AnalyzeBudget estimates the context consumed by the layered agents and
+// skills and checks it against limits
+func AnalyzeBudget(projectRoot string, limits spec.BudgetLimits) (*BudgetReport, error) {
+	agents, err := ResolveAgents(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := ResolveSkills(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := BuildAgentGraph(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BudgetReport{Version: Version, Limits: limits, Warnings: []string{}}
+	skillTokens := map[string]int{} // Skill → SKILL.md tokens
+	onDemand := map[string]int{}    // Skill → reference tokens
+	for _, r := range agents {
+		for rel, data := range r.Files {
+			report.addFile(FileBudget{Path: "agents/" + rel, Kind: BudgetAgent, Asset: r.Name, Layer: r.Layer, Tokens: EstimateTokens(data)})
+		}
+	}
+	for _, r := range skills {
+		main := skillKind.main(r.Name)
+		info := parseSkillInfo(r.Name, r.Files[main])
+		report.Catalog += EstimateTokens([]byte(info.Name + ": " + info.Description))
+		for rel, data := range r.Files {
+			f := FileBudget{Path: "skills/" + rel, Kind: BudgetReference, Asset: r.Name, Layer: r.Layer, Tokens: EstimateTokens(data)}
+			if rel == main {
+				f.Kind = BudgetSkill
+				skillTokens[r.Name] = f.Tokens
+			} else {
+				onDemand[r.Name] += f.Tokens
+			}
+			report.addFile(f)
+		}
+	}
+
+	refs := map[string][]string{}
+	for _, e := range graph.Edges {
+		if e.Kind == EdgeUsesSkill && !e.Missing {
+			refs[e.From] = append(refs[e.From], e.To)
+		}
+	}
+	for _, r := range agents {
+		b := AgentBudget{Agent: r.Name, AgentTokens: EstimateTokens(r.Files[agentKind.main(r.Name)]), Catalog: report.Catalog, Skills: refs[r.Name]}
+		sort.Strings(b.Skills)
+		for _, s := range b.Skills {
+			b.SkillTokens += skillTokens[s]
+			b.OnDemand += onDemand[s]
+		}
+		b.Total = b.AgentTokens + b.Catalog + b.SkillTokens
+		if b.Skills == nil {
+			b.Skills = []string{}
+		}
+		report.Agents = append(report.Agents, b)
+		if limits.AgentTokens > 0 && b.Total > limits.AgentTokens {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("agent %s loads ~%d tokens, over the %d-token limit", b.Agent, b.Total, limits.AgentTokens))
+		}
+	}
+
+	sort.Slice(report.Files, func(i, j int) bool {
+		if report.Files[i].Tokens != report.Files[j].Tokens {
+			return report.Files[i].Tokens > report.Files[j].Tokens
+		}
+		return report.Files[i].Path < report.Files[j].Path
+	})
+	sort.SliceStable(report.Agents, func(i, j int) bool { return report.Agents[i].Total > report.Agents[j].Total })
+	for _, f := range report.Files {
+		if limits.FileTokens > 0 && f.Tokens > limits.FileTokens {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s is ~%d tokens, over the %d-token file limit", f.Path, f.Tokens, limits.FileTokens))
+		}
+	}
+	return report, nil
+}
+
+func (r *BudgetReport) addFile(f FileBudget) {
+	r.Files = append(r.Files, f)
+	r.Total += f.Tokens
+}
+
+// Agent returns the budget of one agent, or nil
+func (r *BudgetReport) Agent(name string) *AgentBudget {
+	for i := range r.Agents {
+		if r.Agents[i].Agent == name {
+			return &r.Agents[i]
+		}
+	}
+	return nil
+}
+
+// embeddedBudget returns the token count of each embedded agent and skill file
+func embeddedBudget() (map[string]int, error) {
+	files := map[string]int{}
+	for _, kind := range []assetKind{agentKind, skillKind} {
+		embedded, err := kind.embedded()
+		if err != nil {
+			return nil, err
+		}
+		for rel, data := range embedded {
+			files[kind.dir+"/"+rel] = EstimateTokens(data)
+		}
+	}
+	return files, nil
+}
+
+// RecordBudget adds the embedded budget of the running extension version to
+// the history, so later versions can be compared against it
+func RecordBudget() error {
+	path, err := budgetHistoryPath()
+	if err != nil {
+		return err
+	}
+	history, err := loadBudgetHistory(path)
+	if err != nil {
+		return err
+	}
+	for _, s := range history {
+		if s.Version == Version {
+			return nil
+		}
+	}
+	files, err := embeddedBudget()
+	if err != nil {
+		return err
+	}
+	history = append(history, budgetSnapshot{Version: Version, RecordedAt: time.Now().UTC(), Files: files})
+	if len(history) > maxBudgetHistory {
+		history = history[len(history)-maxBudgetHistory:]
+	}
+	if err := fileutil.EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := fileutil.AtomicWriteJSON(path, history); err != nil {
+		return fmt.Errorf("failed to write budget history: %w", err)
+	}
+	return nil
+}
+
+// DiffBudget compares the embedded budget with a recorded version. An empty
+// version means the most recently recorded version other than the running
+// one. It returns nil when there is nothing to compare against.
+func DiffBudget(version string) (*BudgetDiff, error) {
+	path, err := budgetHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+	history, err := loadBudgetHistory(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline *budgetSnapshot
+	for i := len(history) - 1; i >= 0; i-- {
+		s := history[i]
+		if (version == "" && s.Version != Version) || (version != "" && s.Version == version) {
+			baseline = &history[i]
+			break
+		}
+	}
+	if baseline == nil {
+		if version != "" {
+			return nil, fmt.Errorf("no budget recorded for version %s", version)
+		}
+		return nil, nil
+	}
+
+	current, err := embeddedBudget()
+	if err != nil {
+		return nil, err
+	}
+	diff := &BudgetDiff{Version: baseline.Version, Changed: map[string]int{}}
+	for p, tokens := range baseline.Files {
+		diff.Before += tokens
+		if delta := current[p] - tokens; delta != 0 {
+			diff.Changed[p] = delta
+		}
+	}
+	for p, tokens := range current {
+		diff.After += tokens
+		if _, existed := baseline.Files[p]; !existed {
+			diff.Changed[p] = tokens
+		}
+	}
+	return diff, nil
+}
+
+// LargestChanges returns up to n changed paths, largest change first
+func (d *BudgetDiff) LargestChanges(n int) []string {
+	paths := sortedKeys(d.Changed)
+	sort.SliceStable(paths, func(i, j int) bool { return abs(d.Changed[paths[i]]) > abs(d.Changed[paths[j]]) })
+	if n > 0 && len(paths) > n {
+		paths = paths[:n]
+	}
+	return paths
+}
+
+func budgetHistoryPath() (string, error) {
+	dir, err := installDir("")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, BudgetHistoryFile), nil
+}
+
+func loadBudgetHistory(path string) ([]budgetSnapshot, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under ~/.azd/copilot
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget history: %w", err)
+	}
+	var history []budgetSnapshot
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return history, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// FormatTokens formats a token count compactly, e.g. "12.3k"
+func FormatTokens(n int) string {
+	if n < 1000 && n > -1000 {
+		return fmt.Sprintf("%d", n)
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1000), ".0") + "k"
+}
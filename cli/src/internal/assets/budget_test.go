@@ -0,0 +1,135 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+	"github.com/jongio/azd-core/fileutil"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text     string
+		min, max int
+	}{
+		{"", 0, 0},
+		{"hello", 1, 1},
+		{"The quick brown fox jumps over the lazy dog.", 9, 11},
+		{"internationalization", 2, 4},
+		{"| a | b |\n|---|---|\n", 8, 14},
+		{strings.Repeat("word ", 100), 100, 100},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens([]byte(tt.text)); got < tt.min || got > tt.max {
+			t.Errorf("EstimateTokens(%q) = %d, want %d-%d", tt.text, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestAnalyzeBudget(t *testing.T) {
+	_, project := setupLayers(t)
+	writeFile(t, filepath.Join(project, ".azd", "copilot", "agents", "heavy.md"),
+		"---\nname: heavy\ndescription: Heavy\n---\n\n| Skill | Purpose |\n|---|---|\n| @big | x |\n")
+	writeFile(t, filepath.Join(project, ".azd", "copilot", "skills", "big", "SKILL.md"),
+		"---\nname: big\ndescription: Big\n---\n"+strings.Repeat("word ", 500))
+	writeFile(t, filepath.Join(project, ".azd", "copilot", "skills", "big", "references", "more.md"), strings.Repeat("word ", 200))
+
+	limits := spec.BudgetLimits{FileTokens: 400, AgentTokens: 100000}
+	report, err := AnalyzeBudget("", limits)
+	if err != nil {
+		t.Fatalf("AnalyzeBudget() error = %v", err)
+	}
+
+	heavy := report.Agent("heavy")
+	if heavy == nil {
+		t.Fatal("Agent(heavy) = nil")
+	}
+	if !slices.Equal(heavy.Skills, []string{"big"}) || heavy.SkillTokens < 500 || heavy.OnDemand != 200 {
+		t.Errorf("heavy = %+v, want skill big with ~500 tokens and 200 on demand", heavy)
+	}
+	if heavy.Total != heavy.AgentTokens+report.Catalog+heavy.SkillTokens {
+		t.Errorf("heavy.Total = %d, want agent + catalog + skills", heavy.Total)
+	}
+	if !slices.ContainsFunc(report.Warnings, func(w string) bool { return strings.HasPrefix(w, "skills/big/SKILL.md ") }) {
+		t.Errorf("warnings = %v, want big/SKILL.md over the file limit", report.Warnings)
+	}
+	for i := 1; i < len(report.Files); i++ {
+		if report.Files[i].Tokens > report.Files[i-1].Tokens {
+			t.Fatal("files are not sorted largest first")
+		}
+	}
+
+	limits.AgentTokens = heavy.Total - 1
+	report, err = AnalyzeBudget("", limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.ContainsFunc(report.Warnings, func(w string) bool { return strings.HasPrefix(w, "agent heavy ") }) {
+		t.Errorf("warnings = %v, want heavy over the agent limit", report.Warnings)
+	}
+}
+
+func TestRecordBudget_Diff(t *testing.T) {
+	home, _ := setupLayers(t)
+	old := Version
+	t.Cleanup(func() { Version = old })
+
+	if diff, err := DiffBudget(""); err != nil || diff != nil {
+		t.Fatalf("DiffBudget() without history = %v, %v; want nil, nil", diff, err)
+	}
+	if _, err := DiffBudget("0.0.1"); err == nil {
+		t.Error("DiffBudget(missing version) error = nil")
+	}
+
+	files, err := embeddedBudget()
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(files, "agents/azure-manager.md")
+	files["agents/azure-dev.md"] -= 100
+	files["agents/retired.md"] = 50
+	history := []budgetSnapshot{{Version: "0.9.0", Files: files}}
+	path := filepath.Join(home, ".azd", "copilot", BudgetHistoryFile)
+	if err := fileutil.EnsureDir(filepath.Dir(path)); err != nil {
+		t.Fatal(err)
+	}
+	if err := fileutil.AtomicWriteJSON(path, history); err != nil {
+		t.Fatal(err)
+	}
+
+	Version = "1.0.0"
+	if err := RecordBudget(); err != nil {
+		t.Fatalf("RecordBudget() error = %v", err)
+	}
+	recorded, err := loadBudgetHistory(path)
+	if err != nil || len(recorded) != 2 || recorded[1].Version != "1.0.0" {
+		t.Fatalf("history = %+v, %v; want 0.9.0 and 1.0.0", recorded, err)
+	}
+
+	diff, err := DiffBudget("")
+	if err != nil || diff == nil {
+		t.Fatalf("DiffBudget() = %v, %v", diff, err)
+	}
+	if diff.Version != "0.9.0" || diff.Changed["agents/azure-dev.md"] != 100 || diff.Changed["agents/retired.md"] != -50 ||
+		diff.Changed["agents/azure-manager.md"] <= 0 || len(diff.Changed) != 3 {
+		t.Errorf("diff = %+v", diff)
+	}
+	if got := diff.LargestChanges(1); len(got) != 1 || got[0] != "agents/azure-manager.md" {
+		t.Errorf("LargestChanges(1) = %v, want the added azure-manager.md", got)
+	}
+}
+
+func TestFormatTokens(t *testing.T) {
+	tests := map[int]string{0: "0", 999: "999", 1000: "1k", 12345: "12.3k", -2500: "-2.5k"}
+	for n, want := range tests {
+		if got := FormatTokens(n); got != want {
+			t.Errorf("FormatTokens(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+// DisabledAgents returns the agents disabled in the project's .copilot.json
+func DisabledAgents() []string {
+	return disabledNames(agentKind)
+}
+
+// DisabledSkills returns the skills disabled in the project's .copilot.json
+func DisabledSkills() []string {
+	return disabledNames(skillKind)
+}
+
+// EnableAgent removes an agent from the project's disabled list. It reports
+// whether .copilot.json changed.
+func EnableAgent(name string) (bool, error) {
+	return setEnabled(agentKind, name, true)
+}
+
+// DisableAgent leaves an agent out of sessions in this project. The entry
+// agent cannot be disabled.
+func DisableAgent(name string) (bool, error) {
+	if name == EntryAgent {
+		return false, fmt.Errorf("%s is the default agent and cannot be disabled", EntryAgent)
+	}
+	return setEnabled(agentKind, name, false)
+}
+
+// EnableSkill removes a skill from the project's disabled list
+func EnableSkill(name string) (bool, error) {
+	return setEnabled(skillKind, name, true)
+}
+
+// DisableSkill leaves a skill out of sessions in this project
+func DisableSkill(name string) (bool, error) {
+	return setEnabled(skillKind, name, false)
+}
+
+// togglesField returns the metadata field holding the settings for a kind
+func togglesField(m *spec.Metadata, kind assetKind) **spec.AssetToggles {
+	if kind.dir == skillKind.dir {
+		return &m.Skills
+	}
+	return &m.Agents
+}
+
+func disabledNames(kind assetKind) []string {
+	m, err := spec.LoadMetadata()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; no %s are disabled\n", err, kind.dir)
+	}
+	if t := *togglesField(m, kind); t != nil {
+		return t.Disable
+	}
+	return nil
+}
+
+func setEnabled(kind assetKind, name string, enabled bool) (bool, error) {
+	m, err := spec.LoadMetadata()
+	if err != nil {
+		return false, err
+	}
+	field := togglesField(m, kind)
+	disabled := *field != nil && slices.Contains((*field).Disable, name)
+	if disabled == !enabled {
+		return false, nil // Already in the requested state
+	}
+
+	if enabled {
+		t := *field
+		if t.Disable = slices.DeleteFunc(t.Disable, func(n string) bool { return n == name }); len(t.Disable) == 0 {
+			*field = nil
+		}
+	} else {
+		resolved, err := resolveKind(kind, "")
+		if err != nil {
+			return false, err
+		}
+		if !slices.ContainsFunc(resolved, func(r Resolved) bool { return r.Name == name }) {
+			return false, fmt.Errorf("%s not found: %s", strings.TrimSuffix(kind.dir, "s"), name)
+		}
+		if *field == nil {
+			*field = &spec.AssetToggles{}
+		}
+		t := *field
+		t.Disable = append(t.Disable, name)
+		sort.Strings(t.Disable)
+	}
+	if err := spec.SaveMetadata(m); err != nil {
+		return false, fmt.Errorf("failed to save %s: %w", spec.MetadataFile, err)
+	}
+	return true, nil
+}
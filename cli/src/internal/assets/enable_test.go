@@ -0,0 +1,85 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+func TestDisableAssets(t *testing.T) {
+	home, _ := setupLayers(t)
+
+	for _, step := range []struct {
+		name        string
+		set         func(string) (bool, error)
+		asset       string
+		wantChanged bool
+		wantErr     string
+	}{
+		{"disable skill", DisableSkill, "azure-kusto", true, ""},
+		{"disable again", DisableSkill, "azure-kusto", false, ""},
+		{"disable second skill", DisableSkill, "azure-ai", true, ""},
+		{"disable agent", DisableAgent, "azure-data", true, ""},
+		{"unknown skill", DisableSkill, "no-such-skill", false, "not found"},
+		{"entry agent", DisableAgent, EntryAgent, false, "cannot be disabled"},
+		{"enable agent", EnableAgent, "azure-data", true, ""},
+		{"enable enabled", EnableAgent, "azure-data", false, ""},
+	} {
+		changed, err := step.set(step.asset)
+		if step.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), step.wantErr) {
+				t.Errorf("%s: error = %v, want %q", step.name, err, step.wantErr)
+			}
+			continue
+		}
+		if err != nil || changed != step.wantChanged {
+			t.Errorf("%s: changed = %v, %v; want %v", step.name, changed, err, step.wantChanged)
+		}
+	}
+
+	m, err := spec.LoadMetadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Skills == nil || !slices.Equal(m.Skills.Disable, []string{"azure-ai", "azure-kusto"}) {
+		t.Errorf(".copilot.json skills = %+v, want azure-ai and azure-kusto disabled", m.Skills)
+	}
+	if m.Agents != nil {
+		t.Errorf(".copilot.json agents = %+v, want the setting removed once none are disabled", m.Agents)
+	}
+
+	skills, err := ListLayeredSkills("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range skills {
+		if want := s.Name == "azure-ai" || s.Name == "azure-kusto"; s.Disabled != want {
+			t.Errorf("%s.Disabled = %v, want %v", s.Name, s.Disabled, want)
+		}
+	}
+
+	// Sessions load a filtered view without the disabled skills
+	dirs, err := MergedDirs("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 2 || !strings.HasPrefix(dirs[1], filepath.Join(home, ".azd", "copilot", "merged")) {
+		t.Fatalf("MergedDirs() = %v, want the filtered view", dirs)
+	}
+	if _, err := os.Stat(filepath.Join(dirs[1], "azure-kusto")); !os.IsNotExist(err) {
+		t.Error("disabled skill azure-kusto is in the filtered view")
+	}
+	if _, err := os.Stat(filepath.Join(dirs[1], "azure-prepare", "SKILL.md")); err != nil {
+		t.Errorf("enabled skill missing from the filtered view: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dirs[0], "azure-data.md")); err != nil {
+		t.Errorf("re-enabled agent missing from the filtered view: %v", err)
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// EntryAgent is the agent sessions start with. It is never reported as an orphan.
+const EntryAgent = "azure-manager"
+
+// Edge kinds in the agent graph
+const (
+	EdgeDelegates = "delegates" // Agent hands work to another agent
+	EdgeUsesSkill = "uses-skill"
+)
+
+// Graph issue kinds
+const (
+	IssueOrphanAgent  = "orphan-agent"  // No agent delegates to it
+	IssueCycle        = "cycle"         // Agents delegate to each other in a loop
+	IssueMissingAgent = "missing-agent" // Delegation to an agent that does not exist
+	IssueMissingSkill = "missing-skill" // Reference to a skill that does not exist
+	IssueUnusedSkill  = "unused-skill"  // No agent mentions the skill
+)
+
+var (
+	// taskPattern matches task tool calls: task(agent_type="azure-dev", ...)
+	taskPattern = regexp.MustCompile(`task\(\s*agent_type\s*=\s*"([a-z0-9-]+)"`)
+	// skillCallPattern matches skill tool calls: skill("azure-prepare")
+	skillCallPattern = regexp.MustCompile(`skill\(\s*"([a-z0-9-]+)"\s*\)`)
+	// delegatePattern matches prose delegations: delegate ... to `azure-dev`
+	delegatePattern = regexp.MustCompile("(?i)delegat\\w*\\b[^.`]*?\\bto\\s+`([a-z0-9-]+)`")
+	// bulletRefPattern matches list items that start with a name: - `azure-design` — ...
+	bulletRefPattern = regexp.MustCompile("^\\s*[-*]\\s+`([a-z0-9-]+)`")
+	// cellRefPattern matches the name at the start of a table cell: `name` or @name
+	cellRefPattern = regexp.MustCompile("^(?:\\*\\*)?(?:`([a-z0-9-]+)`|@([a-z0-9][a-z0-9-]*))")
+)
+
+// GraphEdge is a delegation or skill reference found in an agent body
+type GraphEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Kind    string `json:"kind"`
+	Line    int    `json:"line"`
+	Missing bool   `json:"missing,omitempty"` // Target does not exist
+}
+
+// GraphIssue is a consistency problem in the agent graph
+type GraphIssue struct {
+	Kind    string   `json:"kind"`
+	Message string   `json:"message"`
+	Nodes   []string `json:"nodes"`
+}
+
+// AgentGraph is the delegation and skill graph of the layered agents
+type AgentGraph struct {
+	Agents []string     `json:"agents"`
+	Skills []string     `json:"skills"`
+	Edges  []GraphEdge  `json:"edges"`
+	Issues []GraphIssue `json:"issues"`
+}
+
+// BuildAgentGraph parses the layered agents for delegations (task calls,
+// "Agent" table columns, and "delegate to `name`" prose) and skill references
+// ("Skill" table columns and skill calls), then checks the graph for orphan
+// agents, cycles, missing targets, and unused skills.
+func BuildAgentGraph(projectRoot string) (*AgentGraph, error) {
+	agents, err := ResolveAgents(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := ResolveSkills(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &AgentGraph{Edges: []GraphEdge{}, Issues: []GraphIssue{}}
+	agentSet, skillSet := map[string]bool{}, map[string]bool{}
+	for _, a := range agents {
+		g.Agents = append(g.Agents, a.Name)
+		agentSet[a.Name] = true
+	}
+	for _, s := range skills {
+		g.Skills = append(g.Skills, s.Name)
+		skillSet[s.Name] = true
+	}
+
+	seen := map[string]bool{}
+	for _, a := range agents {
+		for _, e := range parseAgentRefs(a.Name, a.Files[agentKind.main(a.Name)], agentSet) {
+			key := e.From + "\x00" + e.To + "\x00" + e.Kind
+			if e.From == e.To || seen[key] {
+				continue
+			}
+			seen[key] = true
+			if e.Kind == EdgeDelegates {
+				e.Missing = !agentSet[e.To]
+			} else {
+				e.Missing = !skillSet[e.To]
+			}
+			g.Edges = append(g.Edges, e)
+		}
+	}
+
+	g.check()
+	return g, nil
+}
+
+// parseAgentRefs returns the delegations and skill references in an agent
+// file. List items naming a known agent also count as delegations.
+func parseAgentRefs(agent string, data []byte, agents map[string]bool) []GraphEdge {
+	var edges []GraphEdge
+	add := func(to, kind string, line int) {
+		edges = append(edges, GraphEdge{From: agent, To: to, Kind: kind, Line: line})
+	}
+
+	_, body := splitFrontmatter(data)
+	offset := strings.Count(string(data), "\n") - strings.Count(body, "\n")
+	var columns []string // Edge kind per column of the current table, "" when not a reference column
+	inCode := false
+	forEachLine([]byte(body), func(n int, text string) {
+		line := n + offset
+		trimmed := strings.TrimSpace(text)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+		}
+		for _, m := range taskPattern.FindAllStringSubmatch(text, -1) {
+			add(m[1], EdgeDelegates, line)
+		}
+		for _, m := range skillCallPattern.FindAllStringSubmatch(text, -1) {
+			add(m[1], EdgeUsesSkill, line)
+		}
+		if inCode {
+			return
+		}
+		for _, m := range delegatePattern.FindAllStringSubmatch(text, -1) {
+			add(m[1], EdgeDelegates, line)
+		}
+		if m := bulletRefPattern.FindStringSubmatch(text); m != nil && agents[m[1]] {
+			add(m[1], EdgeDelegates, line)
+		}
+
+		if !strings.HasPrefix(trimmed, "|") {
+			columns = nil
+			return
+		}
+		cells := strings.Split(strings.Trim(trimmed, "|"), "|")
+		switch {
+		case columns == nil:
+			// Header row: classify columns by their titles
+			columns = make([]string, len(cells))
+			for i, cell := range cells {
+				title := strings.ToLower(cell)
+				if strings.Contains(title, "agent") {
+					columns[i] = EdgeDelegates
+				} else if strings.Contains(title, "skill") {
+					columns[i] = EdgeUsesSkill
+				}
+			}
+		case strings.Trim(trimmed, "|-: ") == "":
+			// Separator row
+		default:
+			for i, cell := range cells {
+				if i >= len(columns) || columns[i] == "" {
+					continue
+				}
+				if m := cellRefPattern.FindStringSubmatch(strings.TrimSpace(cell)); m != nil {
+					add(m[1]+m[2], columns[i], line)
+				}
+			}
+		}
+	})
+	return edges
+}
+
+func (g *AgentGraph) check() {
+	incoming := map[string]bool{}
+	usedSkills := map[string]bool{}
+	for _, e := range g.Edges {
+		switch {
+		case e.Kind == EdgeDelegates && e.Missing:
+			g.issue(IssueMissingAgent, fmt.Sprintf("%s delegates to %s, which does not exist (line %d)", e.From, e.To, e.Line), e.From, e.To)
+		case e.Kind == EdgeUsesSkill && e.Missing:
+			g.issue(IssueMissingSkill, fmt.Sprintf("%s references skill %s, which does not exist (line %d)", e.From, e.To, e.Line), e.From, e.To)
+		case e.Kind == EdgeDelegates:
+			incoming[e.To] = true
+		default:
+			usedSkills[e.To] = true
+		}
+	}
+
+	for _, a := range g.Agents {
+		if a != EntryAgent && !incoming[a] {
+			g.issue(IssueOrphanAgent, fmt.Sprintf("no agent delegates to %s", a), a)
+		}
+	}
+	for _, cycle := range g.cycles() {
+		g.issue(IssueCycle, "delegation cycle: "+strings.Join(slices.Concat(cycle, cycle[:1]), " → "), cycle...)
+	}
+	for _, s := range g.Skills {
+		if !usedSkills[s] {
+			g.issue(IssueUnusedSkill, fmt.Sprintf("no agent mentions skill %s", s), s)
+		}
+	}
+}
+
+func (g *AgentGraph) issue(kind, message string, nodes ...string) {
+	g.Issues = append(g.Issues, GraphIssue{Kind: kind, Message: message, Nodes: nodes})
+}
+
+// cycles returns each delegation cycle once, rotated to start at its smallest name
+func (g *AgentGraph) cycles() [][]string {
+	next := map[string][]string{}
+	for _, e := range g.Edges {
+		if e.Kind == EdgeDelegates && !e.Missing {
+			next[e.From] = append(next[e.From], e.To)
+		}
+	}
+
+	found := map[string][]string{}
+	var stack []string
+	onStack := map[string]bool{}
+	done := map[string]bool{}
+	var visit func(string)
+	visit = func(a string) {
+		stack = append(stack, a)
+		onStack[a] = true
+		for _, b := range next[a] {
+			if onStack[b] {
+				cycle := slices.Clone(stack[slices.Index(stack, b):])
+				start := slices.Index(cycle, slices.Min(cycle))
+				cycle = append(slices.Clone(cycle[start:]), cycle[:start]...)
+				found[strings.Join(cycle, ",")] = cycle
+			} else if !done[b] {
+				visit(b)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		onStack[a] = false
+		done[a] = true
+	}
+	for _, a := range g.Agents {
+		if !done[a] {
+			visit(a)
+		}
+	}
+
+	cycles := make([][]string, 0, len(found))
+	for _, key := range sortedKeys(found) {
+		cycles = append(cycles, found[key])
+	}
+	return cycles
+}
+
+// Mermaid renders the graph as a Mermaid flowchart. Skills are rounded,
+// missing targets are styled red.
+func (g *AgentGraph) Mermaid(withSkills bool) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for _, a := range g.Agents {
+		fmt.Fprintf(&sb, "  %s[\"%s\"]\n", mermaidID("agent", a), a)
+	}
+	for _, e := range g.edges(withSkills) {
+		kind := "agent"
+		shape := "[\"%s\"]"
+		arrow := "-->"
+		if e.Kind == EdgeUsesSkill {
+			kind, shape, arrow = "skill", "([\"%s\"])", "-.->"
+		}
+		target := mermaidID(kind, e.To) + fmt.Sprintf(shape, e.To)
+		fmt.Fprintf(&sb, "  %s %s %s\n", mermaidID("agent", e.From), arrow, target)
+		if e.Missing {
+			fmt.Fprintf(&sb, "  class %s missing\n", mermaidID(kind, e.To))
+		}
+	}
+	sb.WriteString("  classDef missing fill:#fdd,stroke:#c00,stroke-dasharray:4\n")
+	return sb.String()
+}
+
+// DOT renders the graph in Graphviz DOT format
+func (g *AgentGraph) DOT(withSkills bool) string {
+	var sb strings.Builder
+	sb.WriteString("digraph agents {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, a := range g.Agents {
+		fmt.Fprintf(&sb, "  %q;\n", a)
+	}
+	for _, e := range g.edges(withSkills) {
+		var attrs []string
+		if e.Kind == EdgeUsesSkill {
+			fmt.Fprintf(&sb, "  %q [shape=ellipse%s];\n", "skill:"+e.To, missingAttrs(e.Missing))
+			attrs = append(attrs, "style=dashed")
+			fmt.Fprintf(&sb, "  %q -> %q [%s];\n", e.From, "skill:"+e.To, strings.Join(attrs, ", "))
+			continue
+		}
+		if e.Missing {
+			fmt.Fprintf(&sb, "  %q [%s];\n", e.To, strings.TrimPrefix(missingAttrs(true), ", "))
+		}
+		fmt.Fprintf(&sb, "  %q -> %q;\n", e.From, e.To)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func (g *AgentGraph) edges(withSkills bool) []GraphEdge {
+	edges := make([]GraphEdge, 0, len(g.Edges))
+	for _, e := range g.Edges {
+		if withSkills || e.Kind == EdgeDelegates {
+			edges = append(edges, e)
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].Kind < edges[j].Kind
+	})
+	return edges
+}
+
+func missingAttrs(missing bool) string {
+	if !missing {
+		return ""
+	}
+	return ", color=red, fontcolor=red, style=dashed"
+}
+
+// mermaidID makes a node ID that is unique per kind and valid in Mermaid
+func mermaidID(kind, name string) string {
+	return kind + "_" + strings.ReplaceAll(name, "-", "_")
+}
@@ -0,0 +1,79 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseAgentRefs(t *testing.T) {
+	agents := map[string]bool{"azure-dev": true, "azure-design": true}
+	data := "---\nname: lead\ndescription: x\n---\n" +
+		"| Agent | Task |\n|---|---|\n| **You** | plan |\n| `azure-dev` | code |\n\n" +
+		"| When | Skill to Invoke |\n|---|---|\n| Bicep | `avm-bicep-rules` (REQUIRED) |\n| Cost | @cost-skill |\n\n" +
+		"Delegate infrastructure changes to `azure-architect` now.\n" +
+		"- `azure-design` — accessibility\n" +
+		"- `azure-prepare` — not an agent\n" +
+		"```\ntask(agent_type=\"azure-docs\", prompt=\"x\")\nskill(\"azure-validate\")\n| Agent |\n```\n"
+
+	var got []string
+	for _, e := range parseAgentRefs("lead", []byte(data), agents) {
+		got = append(got, e.Kind+":"+e.To)
+	}
+	want := []string{
+		"delegates:azure-dev",
+		"uses-skill:avm-bicep-rules",
+		"uses-skill:cost-skill",
+		"delegates:azure-architect",
+		"delegates:azure-design",
+		"delegates:azure-docs",
+		"uses-skill:azure-validate",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseAgentRefs() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestBuildAgentGraph(t *testing.T) {
+	_, project := setupLayers(t)
+	dir := filepath.Join(project, ".azd", "copilot", "agents")
+	writeFile(t, filepath.Join(dir, "loop-a.md"), "---\nname: loop-a\ndescription: A\n---\n\nDelegate reviews to `loop-b`.\n")
+	writeFile(t, filepath.Join(dir, "loop-b.md"), "---\nname: loop-b\ndescription: B\n---\n\nDelegate fixes to `loop-a`, or delegate networking to `azure-networking`.\n\n| Skill | Purpose |\n|---|---|\n| @no-such-skill | x |\n")
+
+	g, err := BuildAgentGraph("")
+	if err != nil {
+		t.Fatalf("BuildAgentGraph() error = %v", err)
+	}
+
+	issues := map[string][]string{}
+	for _, issue := range g.Issues {
+		issues[issue.Kind] = append(issues[issue.Kind], strings.Join(issue.Nodes, ","))
+	}
+	if !slices.Contains(issues[IssueCycle], "loop-a,loop-b") {
+		t.Errorf("cycles = %v, want loop-a → loop-b", issues[IssueCycle])
+	}
+	if !slices.Contains(issues[IssueMissingAgent], "loop-b,azure-networking") {
+		t.Errorf("missing agents = %v", issues[IssueMissingAgent])
+	}
+	if !slices.Contains(issues[IssueMissingSkill], "loop-b,no-such-skill") {
+		t.Errorf("missing skills = %v", issues[IssueMissingSkill])
+	}
+	if slices.Contains(issues[IssueOrphanAgent], EntryAgent) || slices.Contains(issues[IssueOrphanAgent], "azure-dev") {
+		t.Errorf("orphans = %v, want neither the entry agent nor delegated agents", issues[IssueOrphanAgent])
+	}
+
+	mermaid := g.Mermaid(true)
+	for _, want := range []string{"flowchart LR", "agent_loop_a --> agent_loop_b", "class agent_azure_networking missing", "-.-> skill_"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid() missing %q", want)
+		}
+	}
+	dot := g.DOT(false)
+	if !strings.Contains(dot, `"loop-a" -> "loop-b";`) || strings.Contains(dot, "skill:") {
+		t.Errorf("DOT(false) =\n%s", dot)
+	}
+}
@@ -0,0 +1,403 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-core/fileutil"
+	"gopkg.in/yaml.v3"
+)
+
+// Layer is where an agent or skill comes from. Later layers override
+// earlier ones: embedded, then pack, then user, then project.
+type Layer string
+
+// LayerEmbedded through LayerProject are the asset layers, lowest first.
+const (
+	LayerEmbedded Layer = "embedded" // Shipped with the extension, as installed in ~/.azd/copilot/agents|skills
+	LayerPack     Layer = "pack"     // ~/.azd/copilot/packs/skills, from installed skill packs
+	LayerUser     Layer = "user"     // ~/.azd/copilot/custom/agents|skills
+	LayerProject  Layer = "project"  // .azd/copilot/agents|skills in the project
+)
+
+// MergeAppend is the frontmatter value ("merge: append") that makes an
+// override extend the asset below it instead of replacing it. The override's
+// body is appended to the lower agent or SKILL.md, and its other skill files
+// are added alongside the lower skill's files.
+const MergeAppend = "append"
+
+// ProjectAssetsDir is the project override directory, relative to the project root
+var ProjectAssetsDir = filepath.Join(".azd", "copilot")
+
+// Resolved is an agent or skill after layering
+type Resolved struct {
+	Name    string            `json:"name"`
+	Layer   Layer             `json:"layer"`             // Highest layer that provides the asset
+	Shadows []Layer           `json:"shadows,omitempty"` // Lower layers it replaces or extends
+	Merged  bool              `json:"merged,omitempty"`  // True when an override extends rather than replaces
+	Files   map[string][]byte `json:"-"`                 // Install-relative path → content
+}
+
+// assetKind describes how files of one asset type map to asset names
+type assetKind struct {
+	dir      string                            // "agents" or "skills"
+	embedded func() (map[string][]byte, error) // Embedded files keyed by install-relative path
+	name     func(rel string) string           // Asset name for a file, or "" to ignore it
+	main     func(name string) string          // Primary file of an asset
+}
+
+var (
+	agentKind = assetKind{
+		dir:      "agents",
+		embedded: embeddedAgentFiles,
+		name: func(rel string) string {
+			if strings.Contains(rel, "/") || !strings.HasSuffix(rel, ".md") {
+				return ""
+			}
+			return strings.TrimSuffix(rel, ".md")
+		},
+		main: func(name string) string { return name + ".md" },
+	}
+	skillKind = assetKind{
+		dir:      "skills",
+		embedded: embeddedSkillFiles,
+		name: func(rel string) string {
+			name, _, found := strings.Cut(rel, "/")
+			if !found {
+				return ""
+			}
+			return name
+		},
+		main: func(name string) string { return name + "/SKILL.md" },
+	}
+)
+
+// CustomDir returns the user override directory (~/.azd/copilot/custom)
+func CustomDir() (string, error) {
+	return installDir("custom")
+}
+
+// OverrideLayers are the layers read from disk, lowest first
+var OverrideLayers = []Layer{LayerPack, LayerUser, LayerProject}
+
+// ResolveAgents layers embedded, user, and project agents. projectRoot ""
+// means the current directory.
+func ResolveAgents(projectRoot string) ([]Resolved, error) {
+	return resolveKind(agentKind, projectRoot)
+}
+
+// ResolveSkills layers embedded, pack, user, and project skills. projectRoot ""
+// means the current directory.
+func ResolveSkills(projectRoot string) ([]Resolved, error) {
+	return resolveKind(skillKind, projectRoot)
+}
+
+// overrideDirs returns the pack, user, and project directories for a kind
+func overrideDirs(kind assetKind, projectRoot string) (map[Layer]string, error) {
+	custom, err := CustomDir()
+	if err != nil {
+		return nil, err
+	}
+	packs, err := PacksDir()
+	if err != nil {
+		return nil, err
+	}
+	if projectRoot == "" {
+		projectRoot = "."
+	}
+	return map[Layer]string{
+		LayerPack:    filepath.Join(packs, kind.dir),
+		LayerUser:    filepath.Join(custom, kind.dir),
+		LayerProject: filepath.Join(projectRoot, ProjectAssetsDir, kind.dir),
+	}, nil
+}
+
+// baseFiles returns the embedded files of a kind as installed in
+// ~/.azd/copilot/agents|skills, so local edits that installs preserve are
+// kept. Files not installed yet come from the embedded copy.
+func baseFiles(kind assetKind) (map[string][]byte, error) {
+	files, err := kind.embedded()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := installDir(kind.dir)
+	if err != nil {
+		return nil, err
+	}
+	installed, err := readDirFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read installed %s: %w", kind.dir, err)
+	}
+	for rel, data := range installed {
+		files[rel] = data
+	}
+	return files, nil
+}
+
+func resolveKind(kind assetKind, projectRoot string) ([]Resolved, error) {
+	embedded, err := baseFiles(kind)
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := overrideDirs(kind, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	type layerFiles struct {
+		layer Layer
+		files map[string][]byte
+	}
+	layers := []layerFiles{{LayerEmbedded, embedded}}
+	for _, layer := range OverrideLayers {
+		files, err := readDirFiles(dirs[layer])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s %s: %w", layer, kind.dir, err)
+		}
+		layers = append(layers, layerFiles{layer, files})
+	}
+
+	byName := map[string]*Resolved{}
+	for _, l := range layers {
+		for name, files := range groupByAsset(kind, l.files) {
+			lower, exists := byName[name]
+			if !exists {
+				byName[name] = &Resolved{Name: name, Layer: l.layer, Files: files}
+				continue
+			}
+			lower.Shadows = append(lower.Shadows, lower.Layer)
+			lower.Layer = l.layer
+			main := kind.main(name)
+			if mergeMode(files[main]) == MergeAppend {
+				lower.Merged = true
+				for rel, data := range files {
+					if rel == main {
+						data = appendBody(lower.Files[main], data)
+					}
+					lower.Files[rel] = data
+				}
+			} else {
+				lower.Merged = false
+				lower.Files = files
+			}
+		}
+	}
+
+	resolved := make([]Resolved, 0, len(byName))
+	for _, r := range byName {
+		resolved = append(resolved, *r)
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].Name < resolved[j].Name })
+	return resolved, nil
+}
+
+// MergedDirs returns the directories Copilot CLI should load agents and
+// skills from. Without overrides or disabled assets these are the install
+// directories; otherwise a merged view of all layers, without the assets
+// disabled in .copilot.json, is written under ~/.azd/copilot/merged/<project>
+// and returned.
+func MergedDirs(projectRoot string) ([]string, error) {
+	agentsDir, err := AgentsDir()
+	if err != nil {
+		return nil, err
+	}
+	skillsDir, err := SkillsDir()
+	if err != nil {
+		return nil, err
+	}
+	if !hasOverrides(projectRoot) && len(DisabledAgents()) == 0 && len(DisabledSkills()) == 0 {
+		return []string{agentsDir, skillsDir}, nil
+	}
+
+	root, err := mergedRoot(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, kind := range []assetKind{agentKind, skillKind} {
+		resolved, err := resolveKind(kind, projectRoot)
+		if err != nil {
+			return nil, err
+		}
+		disabled := disabledNames(kind)
+		resolved = slices.DeleteFunc(resolved, func(r Resolved) bool { return slices.Contains(disabled, r.Name) })
+		dir := filepath.Join(root, kind.dir)
+		if err := updateMerged(dir, resolved); err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs, nil
+}
+
+// hasOverrides reports whether any pack, user, or project directory exists
+func hasOverrides(projectRoot string) bool {
+	for _, kind := range []assetKind{agentKind, skillKind} {
+		dirs, err := overrideDirs(kind, projectRoot)
+		if err != nil {
+			return false
+		}
+		for _, dir := range dirs {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mergedRoot returns the merged view directory for a project
+func mergedRoot(projectRoot string) (string, error) {
+	if projectRoot == "" {
+		projectRoot = "."
+	}
+	abs, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve project path: %w", err)
+	}
+	base, err := installDir("merged")
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(base, filepath.Base(abs)+"-"+hex.EncodeToString(sum[:])[:12]), nil
+}
+
+// updateMerged rewrites dir with the resolved files unless it already holds
+// them, as recorded by a digest file next to it from the last write
+func updateMerged(dir string, resolved []Resolved) error {
+	digest := mergedDigest(resolved)
+	digestFile := dir + ".sha256"
+	if data, err := os.ReadFile(digestFile); err == nil && string(data) == digest { //nolint:gosec // G304: path is under the merged view directory
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return nil
+		}
+	}
+	if err := writeMerged(dir, resolved); err != nil {
+		return err
+	}
+	if err := fileutil.AtomicWriteFile(digestFile, []byte(digest), 0o644); err != nil {
+		return fmt.Errorf("failed to write merged view digest: %w", err)
+	}
+	return nil
+}
+
+// mergedDigest hashes the paths and contents of the resolved files
+func mergedDigest(resolved []Resolved) string {
+	h := sha256.New()
+	for _, r := range resolved {
+		for _, rel := range sortedKeys(r.Files) {
+			_, _ = fmt.Fprintf(h, "%s\x00%s\n", rel, hashBytes(r.Files[rel]))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeMerged replaces dir with the resolved files
+func writeMerged(dir string, resolved []Resolved) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to clear merged view %s: %w", dir, err)
+	}
+	for _, r := range resolved {
+		for rel, data := range r.Files {
+			dest := filepath.Join(dir, filepath.FromSlash(rel))
+			if err := fileutil.EnsureDir(filepath.Dir(dest)); err != nil {
+				return fmt.Errorf("failed to create merged view: %w", err)
+			}
+			if err := fileutil.AtomicWriteFile(dest, data, 0o644); err != nil {
+				return fmt.Errorf("failed to write merged view: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// readDirFiles reads every file under dir keyed by slash-separated relative
+// path. A missing directory has no files.
+func readDirFiles(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if os.IsNotExist(walkErr) && path == dir {
+				return filepath.SkipDir
+			}
+			return walkErr
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path is under an asset override directory
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
+
+func groupByAsset(kind assetKind, files map[string][]byte) map[string]map[string][]byte {
+	grouped := map[string]map[string][]byte{}
+	for rel, data := range files {
+		name := kind.name(rel)
+		if name == "" {
+			continue
+		}
+		if grouped[name] == nil {
+			grouped[name] = map[string][]byte{}
+		}
+		grouped[name][rel] = data
+	}
+	// An asset needs its primary file; stray files are ignored
+	for name, assetFiles := range grouped {
+		if _, ok := assetFiles[kind.main(name)]; !ok {
+			delete(grouped, name)
+		}
+	}
+	return grouped
+}
+
+// splitFrontmatter returns the YAML frontmatter and the body of a markdown file
+func splitFrontmatter(data []byte) (string, string) {
+	content := string(data)
+	if !strings.HasPrefix(content, "---") {
+		return "", content
+	}
+	parts := strings.SplitN(content, "---", 3)
+	if len(parts) < 3 {
+		return "", content
+	}
+	return parts[1], parts[2]
+}
+
+// mergeMode returns the "merge" frontmatter value of an override
+func mergeMode(data []byte) string {
+	fm, _ := splitFrontmatter(data)
+	var frontmatter struct {
+		Merge string `yaml:"merge"`
+	}
+	if err := yaml.Unmarshal([]byte(fm), &frontmatter); err != nil {
+		return ""
+	}
+	return frontmatter.Merge
+}
+
+// appendBody appends the body of an override (without its frontmatter) to base
+func appendBody(base, override []byte) []byte {
+	_, body := splitFrontmatter(override)
+	return []byte(strings.TrimRight(string(base), "\n") + "\n\n" + strings.TrimLeft(body, "\n"))
+}
@@ -0,0 +1,178 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupLayers isolates HOME and the project directory, returning both
+func setupLayers(t *testing.T) (home, project string) {
+	t.Helper()
+	home = t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	project = t.TempDir()
+	t.Chdir(project)
+	return home, project
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findResolved(resolved []Resolved, name string) *Resolved {
+	for i := range resolved {
+		if resolved[i].Name == name {
+			return &resolved[i]
+		}
+	}
+	return nil
+}
+
+func TestResolveAgents(t *testing.T) {
+	home, project := setupLayers(t)
+	custom := filepath.Join(home, ".azd", "copilot", "custom", "agents")
+	overrides := filepath.Join(project, ".azd", "copilot", "agents")
+
+	// User layer replaces azure-dev; the project layer then extends it
+	writeFile(t, filepath.Join(custom, "azure-dev.md"), "---\nname: azure-dev\ndescription: Team dev agent\n---\n\nUse our conventions.\n")
+	writeFile(t, filepath.Join(overrides, "azure-dev.md"), "---\nmerge: append\n---\n\nThis repo uses Go.\n")
+	// Project-only agent
+	writeFile(t, filepath.Join(overrides, "repo-helper.md"), "---\nname: repo-helper\ndescription: Repo specific\n---\n")
+
+	resolved, err := ResolveAgents("")
+	if err != nil {
+		t.Fatalf("ResolveAgents() error = %v", err)
+	}
+
+	dev := findResolved(resolved, "azure-dev")
+	if dev == nil || dev.Layer != LayerProject || !dev.Merged || len(dev.Shadows) != 2 {
+		t.Fatalf("azure-dev = %+v, want project extending user over embedded", dev)
+	}
+	content := string(dev.Files["azure-dev.md"])
+	if !strings.Contains(content, "Team dev agent") || !strings.Contains(content, "Use our conventions.\n\nThis repo uses Go.") || strings.Contains(content, "merge: append") {
+		t.Errorf("merged azure-dev.md =\n%s", content)
+	}
+
+	if helper := findResolved(resolved, "repo-helper"); helper == nil || helper.Layer != LayerProject || len(helper.Shadows) != 0 {
+		t.Errorf("repo-helper = %+v, want a project-only agent", helper)
+	}
+	if ai := findResolved(resolved, "azure-ai"); ai == nil || ai.Layer != LayerEmbedded {
+		t.Errorf("azure-ai = %+v, want the embedded agent", ai)
+	}
+
+	agents, err := ListLayeredAgents("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range agents {
+		if a.Name == "azure-dev" && a.Description != "Team dev agent" {
+			t.Errorf("layered azure-dev description = %q", a.Description)
+		}
+	}
+}
+
+func TestResolveSkills_Shadow(t *testing.T) {
+	_, project := setupLayers(t)
+	skillDir := filepath.Join(project, ".azd", "copilot", "skills", "quality")
+	writeFile(t, filepath.Join(skillDir, "SKILL.md"), "---\nname: quality\ndescription: Our quality bar\n---\n")
+
+	resolved, err := ResolveSkills("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	quality := findResolved(resolved, "quality")
+	if quality == nil || quality.Layer != LayerProject || quality.Merged {
+		t.Fatalf("quality = %+v, want a project shadow", quality)
+	}
+	// Shadowing replaces the whole skill, including its reference files
+	if len(quality.Files) != 1 {
+		t.Errorf("shadowed skill files = %v, want only SKILL.md", len(quality.Files))
+	}
+}
+
+func TestMergedDirs(t *testing.T) {
+	home, project := setupLayers(t)
+
+	dirs, err := MergedDirs("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentsDir, _ := AgentsDir()
+	if len(dirs) != 2 || dirs[0] != agentsDir {
+		t.Errorf("MergedDirs() without overrides = %v, want the install directories", dirs)
+	}
+
+	writeFile(t, filepath.Join(project, ".azd", "copilot", "skills", "quality", "extra.md"), "stray file without SKILL.md")
+	writeFile(t, filepath.Join(project, ".azd", "copilot", "agents", "repo-helper.md"), "---\nname: repo-helper\n---\n")
+	dirs, err = MergedDirs("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 2 || !strings.HasPrefix(dirs[0], filepath.Join(home, ".azd", "copilot", "merged")) {
+		t.Fatalf("MergedDirs() = %v, want the merged view", dirs)
+	}
+	for _, want := range []string{
+		filepath.Join(dirs[0], "repo-helper.md"),
+		filepath.Join(dirs[0], "azure-dev.md"),
+		filepath.Join(dirs[1], "quality", "code-review.md"),
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("merged view missing %s", want)
+		}
+	}
+	// Files without the asset's primary file are ignored
+	if _, err := os.Stat(filepath.Join(dirs[1], "quality", "extra.md")); err == nil {
+		t.Error("stray override file should not be merged")
+	}
+}
+
+func TestMergedDirs_KeepsInstalledEdits(t *testing.T) {
+	home, project := setupLayers(t)
+	installed := filepath.Join(home, ".azd", "copilot", "agents")
+	if _, err := InstallAgents(); err != nil {
+		t.Fatal(err)
+	}
+	// A local edit an install preserves stays in the merged view
+	writeFile(t, filepath.Join(installed, "azure-dev.md"), "---\nname: azure-dev\ndescription: Edited locally\n---\n")
+	writeFile(t, filepath.Join(project, ".azd", "copilot", "agents", "repo-helper.md"), "---\nname: repo-helper\n---\n")
+
+	dirs, err := MergedDirs("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dirs[0], "azure-dev.md"))
+	if err != nil || !strings.Contains(string(data), "Edited locally") {
+		t.Errorf("merged azure-dev.md = %q, %v; want the local edit", data, err)
+	}
+
+	// Unchanged inputs leave the view alone
+	marker := filepath.Join(dirs[0], "marker")
+	writeFile(t, marker, "untouched")
+	if _, err := MergedDirs(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("MergedDirs() rewrote the view although nothing changed")
+	}
+
+	// Changed inputs rewrite it
+	writeFile(t, filepath.Join(project, ".azd", "copilot", "agents", "repo-helper.md"), "---\nname: repo-helper\ndescription: v2\n---\n")
+	if _, err := MergedDirs(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("MergedDirs() kept a stale view after an override changed")
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-core/fileutil"
+)
+
+// Version is set by the main package at startup and recorded in install manifests.
+var Version = "0.1.0"
+
+// ManifestFile records what was installed in an asset directory
+const ManifestFile = ".azd-copilot-manifest.json"
+
+// Manifest lists the files azd copilot installed and their hashes, so later
+// installs can tell unchanged files from local edits and prune files that
+// are no longer shipped
+type Manifest struct {
+	Version string            `json:"version"`
+	Files   map[string]string `json:"files"` // Install-relative path → sha256
+}
+
+// InstallReport describes an incremental install
+type InstallReport struct {
+	Dir       string   `json:"dir"`
+	Count     int      `json:"count"`               // Agents or skills shipped
+	Written   []string `json:"written,omitempty"`   // Added or updated files
+	Removed   []string `json:"removed,omitempty"`   // Previously installed files no longer shipped
+	Preserved []string `json:"preserved,omitempty"` // Locally edited files left in place
+}
+
+// Summary describes the changes, e.g. "3 written, 1 removed"
+func (r *InstallReport) Summary() string {
+	var parts []string
+	for _, p := range []struct {
+		n    int
+		verb string
+	}{{len(r.Written), "written"}, {len(r.Removed), "removed"}, {len(r.Preserved), "preserved (edited locally)"}} {
+		if p.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", p.n, p.verb))
+		}
+	}
+	if len(parts) == 0 {
+		return "up to date"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// LoadManifest reads the install manifest in dir. It returns nil when the
+// directory was never installed with a manifest.
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile)) //nolint:gosec // G304: path is under the asset install directory
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse install manifest %s: %w", filepath.Join(dir, ManifestFile), err)
+	}
+	return &m, nil
+}
+
+// installFiles writes only the files that changed, removes files a previous
+// install wrote that are no longer shipped, and leaves files edited since
+// they were installed alone. Without a manifest (installs before manifests
+// existed) every file is written, as before.
+func installFiles(dir string, files map[string][]byte) (*InstallReport, error) {
+	if err := fileutil.EnsureDir(dir); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	previous, err := LoadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &InstallReport{Dir: dir}
+	next := &Manifest{Version: Version, Files: make(map[string]string, len(files))}
+
+	for _, rel := range sortedKeys(files) {
+		want := hashBytes(files[rel])
+		dest := filepath.Join(dir, filepath.FromSlash(rel))
+		current, err := hashFile(dest)
+		installed, tracked := "", false
+		if previous != nil {
+			installed, tracked = previous.Files[rel]
+		}
+
+		switch {
+		case err == nil && current == want:
+			next.Files[rel] = want
+			continue
+		case err == nil && previous != nil && (!tracked || current != installed):
+			// Edited locally, or a file we never wrote: keep it
+			report.Preserved = append(report.Preserved, rel)
+			if tracked {
+				next.Files[rel] = installed
+			}
+			continue
+		}
+
+		if err := fileutil.EnsureDir(filepath.Dir(dest)); err != nil {
+			return report, fmt.Errorf("failed to create directory for %s: %w", rel, err)
+		}
+		if err := fileutil.AtomicWriteFile(dest, files[rel], 0o644); err != nil {
+			return report, fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+		next.Files[rel] = want
+		report.Written = append(report.Written, rel)
+	}
+
+	if previous != nil {
+		for _, rel := range sortedKeys(previous.Files) {
+			if _, shipped := files[rel]; shipped {
+				continue
+			}
+			dest := filepath.Join(dir, filepath.FromSlash(rel))
+			current, err := hashFile(dest)
+			if err != nil {
+				continue // Already gone
+			}
+			if current != previous.Files[rel] {
+				report.Preserved = append(report.Preserved, rel)
+				continue
+			}
+			if err := os.Remove(dest); err != nil {
+				return report, fmt.Errorf("failed to remove %s: %w", rel, err)
+			}
+			removeEmptyParents(dir, filepath.Dir(dest))
+			report.Removed = append(report.Removed, rel)
+		}
+	}
+
+	if err := fileutil.AtomicWriteJSON(filepath.Join(dir, ManifestFile), next); err != nil {
+		return report, fmt.Errorf("failed to write install manifest: %w", err)
+	}
+	return report, nil
+}
+
+// removeEmptyParents removes empty directories from dir up to, but not including, root
+func removeEmptyParents(root, dir string) {
+	for dir != root && strings.HasPrefix(dir, root) {
+		if err := os.Remove(dir); err != nil {
+			return // Not empty
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under the asset install directory
+	if err != nil {
+		return "", err
+	}
+	return hashBytes(data), nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,116 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestInstallFiles(t *testing.T) {
+	dir := t.TempDir()
+	v1 := map[string][]byte{
+		"a.md":         []byte("a v1"),
+		"b.md":         []byte("b v1"),
+		"old/SKILL.md": []byte("old skill"),
+		"gone.md":      []byte("gone"),
+	}
+
+	report, err := installFiles(dir, v1)
+	if err != nil {
+		t.Fatalf("first install error = %v", err)
+	}
+	if len(report.Written) != len(v1) {
+		t.Errorf("first install wrote %v, want every file", report.Written)
+	}
+
+	report, err = installFiles(dir, v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Written)+len(report.Removed)+len(report.Preserved) != 0 || report.Summary() != "up to date" {
+		t.Errorf("reinstall = %+v, want no changes", report)
+	}
+
+	// Edit one shipped file and one file that is about to stop shipping
+	writeFile(t, filepath.Join(dir, "b.md"), "b edited")
+	writeFile(t, filepath.Join(dir, "gone.md"), "gone edited")
+
+	v2 := map[string][]byte{
+		"a.md":   []byte("a v2"),
+		"b.md":   []byte("b v2"),
+		"new.md": []byte("new"),
+	}
+	report, err = installFiles(dir, v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(report.Written, []string{"a.md", "new.md"}) {
+		t.Errorf("Written = %v", report.Written)
+	}
+	if !slices.Equal(report.Removed, []string{"old/SKILL.md"}) {
+		t.Errorf("Removed = %v", report.Removed)
+	}
+	if !slices.Equal(report.Preserved, []string{"b.md", "gone.md"}) {
+		t.Errorf("Preserved = %v", report.Preserved)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "b.md")); string(data) != "b edited" {
+		t.Errorf("edited b.md = %q, want the local edit kept", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old")); !os.IsNotExist(err) {
+		t.Error("empty directory of a pruned file should be removed")
+	}
+
+	m, err := LoadManifest(dir)
+	if err != nil || m == nil {
+		t.Fatalf("LoadManifest() = %v, %v", m, err)
+	}
+	if m.Version != Version || m.Files["b.md"] != hashBytes([]byte("b v1")) {
+		t.Errorf("manifest = %+v, want the version and the last installed hash for edited files", m)
+	}
+	if _, ok := m.Files["old/SKILL.md"]; ok {
+		t.Error("pruned file should leave the manifest")
+	}
+}
+
+func TestInstallFiles_WithoutManifest(t *testing.T) {
+	dir := t.TempDir()
+	// Installs from before manifests existed are overwritten as before
+	writeFile(t, filepath.Join(dir, "a.md"), "older release")
+
+	report, err := installFiles(dir, map[string][]byte{"a.md": []byte("current")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(report.Written, []string{"a.md"}) || len(report.Preserved) != 0 {
+		t.Errorf("report = %+v, want a.md written", report)
+	}
+}
+
+func TestInstallAgents_Report(t *testing.T) {
+	setupLayers(t)
+
+	report, err := InstallAgents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := embeddedAgentFiles()
+	if report.Count != len(files) || len(report.Written) != len(files) {
+		t.Errorf("InstallAgents() = %d agents, %d written, want %d", report.Count, len(report.Written), len(files))
+	}
+	if _, err := os.Stat(filepath.Join(report.Dir, ManifestFile)); err != nil {
+		t.Errorf("manifest not written: %v", err)
+	}
+
+	report, err = InstallSkills()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count != SkillCount() {
+		t.Errorf("InstallSkills() count = %d, want %d", report.Count, SkillCount())
+	}
+}
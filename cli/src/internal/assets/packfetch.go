@@ -0,0 +1,204 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jongio/azd-core/fileutil"
+)
+
+// maxPackBytes caps the unpacked size of a pack archive
+const maxPackBytes = 64 << 20
+
+// fetchPack copies or unpacks source into tmp and returns the pack root
+// (the directory holding the manifest) and the source normalized for
+// reinstalls: local paths become absolute.
+func fetchPack(ctx context.Context, source, tmp string) (string, string, error) {
+	dest := filepath.Join(tmp, "pack")
+	switch {
+	case isArchive(source):
+		if isRemote(source) {
+			if err := downloadArchive(ctx, source, dest); err != nil {
+				return "", "", err
+			}
+			break
+		}
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to resolve %s: %w", source, err)
+		}
+		source = abs
+		f, err := os.Open(abs) //nolint:gosec // G304: pack archive chosen by the user
+		if err != nil {
+			return "", "", fmt.Errorf("failed to open %s: %w", source, err)
+		}
+		defer func() { _ = f.Close() }()
+		if err := extractTarGz(f, dest); err != nil {
+			return "", "", err
+		}
+	case isGitSource(source):
+		if !strings.Contains(source, "://") && !strings.HasPrefix(source, "git@") {
+			abs, err := filepath.Abs(source)
+			if err != nil {
+				return "", "", fmt.Errorf("failed to resolve %s: %w", source, err)
+			}
+			source = abs
+		}
+		if err := gitClone(ctx, source, dest); err != nil {
+			return "", "", err
+		}
+	default:
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to resolve %s: %w", source, err)
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return "", "", fmt.Errorf("%s is not a pack directory, .tar.gz archive, or git repository", source)
+		}
+		return abs, abs, nil
+	}
+
+	root, err := packRoot(dest)
+	if err != nil {
+		return "", "", err
+	}
+	return root, source, nil
+}
+
+func isArchive(source string) bool {
+	return strings.HasSuffix(source, ".tar.gz") || strings.HasSuffix(source, ".tgz")
+}
+
+func isRemote(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
+
+// isGitSource reports whether source is a git URL, a path ending in .git, or
+// a local bare repository
+func isGitSource(source string) bool {
+	for _, prefix := range []string{"git@", "git://", "ssh://", "file://", "https://", "http://"} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	if strings.HasSuffix(source, ".git") {
+		return true
+	}
+	head, err := os.Stat(filepath.Join(source, "HEAD"))
+	objects, err2 := os.Stat(filepath.Join(source, "objects"))
+	return err == nil && err2 == nil && !head.IsDir() && objects.IsDir()
+}
+
+// packRoot returns dir, or its only subdirectory when the manifest is one
+// level down (as in archives that wrap their contents in a folder)
+func packRoot(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, PackManifestFile)); err == nil {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pack: %w", err)
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		sub := filepath.Join(dir, entries[0].Name())
+		if _, err := os.Stat(filepath.Join(sub, PackManifestFile)); err == nil {
+			return sub, nil
+		}
+	}
+	return "", fmt.Errorf("pack has no %s", PackManifestFile)
+}
+
+func gitClone(ctx context.Context, url, dest string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git is required to install packs from %s", url)
+	}
+	cmd := exec.CommandContext(ctx, "git", "clone", "--quiet", "--depth", "1", url, dest) //nolint:gosec // G204: git with the pack source as an argument
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to clone %s: %s", url, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func downloadArchive(ctx context.Context, url, dest string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid pack URL %s: %w", url, err)
+	}
+	resp, err := http.DefaultClient.Do(req) //nolint:gosec // G107: pack URL chosen by the user
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	return extractTarGz(resp.Body, dest)
+}
+
+// extractTarGz unpacks regular files and directories into dest, rejecting
+// entries that would escape it
+func extractTarGz(r io.Reader, dest string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to read pack archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	tr := tar.NewReader(gz)
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read pack archive: %w", err)
+		}
+		name := path.Clean(hdr.Name)
+		if name == "." {
+			continue
+		}
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("pack archive entry %q is outside the pack", hdr.Name)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(name))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := fileutil.EnsureDir(target); err != nil {
+				return fmt.Errorf("failed to create %s: %w", target, err)
+			}
+		case tar.TypeReg:
+			total += hdr.Size
+			if total > maxPackBytes {
+				return fmt.Errorf("pack archive is larger than %d MB", maxPackBytes>>20)
+			}
+			if err := fileutil.EnsureDir(filepath.Dir(target)); err != nil {
+				return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+			}
+			data, err := io.ReadAll(io.LimitReader(tr, hdr.Size))
+			if err != nil {
+				return fmt.Errorf("failed to read %s from pack archive: %w", hdr.Name, err)
+			}
+			if err := fileutil.AtomicWriteFile(target, data, 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", target, err)
+			}
+		default:
+			// Links and special files are not part of a pack
+		}
+	}
+}
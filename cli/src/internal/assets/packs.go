@@ -0,0 +1,277 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-core/fileutil"
+)
+
+// PackManifestFile is the manifest at the root of a skill pack
+const PackManifestFile = "skillpack.json"
+
+// packRegistryFile lists the installed packs, in PacksDir
+const packRegistryFile = "packs.json"
+
+// packNamePattern matches valid pack names
+var packNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// PackManifest identifies a skill pack. SHA256 is the PackDigest of the
+// pack's files, excluding the manifest.
+type PackManifest struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	SHA256      string `json:"sha256"`
+	Description string `json:"description,omitempty"`
+}
+
+// InstalledPack is a skill pack recorded in the registry
+type InstalledPack struct {
+	PackManifest
+	Source      string    `json:"source"` // Directory, archive, or git URL it was installed from
+	InstalledAt time.Time `json:"installedAt"`
+	Skills      []string  `json:"skills"`
+}
+
+// PackUpdate is the result of updating one pack
+type PackUpdate struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// PacksDir returns the skill pack directory (~/.azd/copilot/packs). Pack
+// skills are installed flat under its skills directory, which is the pack layer.
+func PacksDir() (string, error) {
+	return installDir("packs")
+}
+
+// ListPacks returns the installed packs, sorted by name
+func ListPacks() ([]InstalledPack, error) {
+	dir, err := PacksDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, packRegistryFile)) //nolint:gosec // G304: path is under ~/.azd/copilot
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pack registry: %w", err)
+	}
+	var packs []InstalledPack
+	if err := json.Unmarshal(data, &packs); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, packRegistryFile), err)
+	}
+	return packs, nil
+}
+
+func savePacks(packs []InstalledPack) error {
+	dir, err := PacksDir()
+	if err != nil {
+		return err
+	}
+	if err := fileutil.EnsureDir(dir); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Name < packs[j].Name })
+	if err := fileutil.AtomicWriteJSON(filepath.Join(dir, packRegistryFile), packs); err != nil {
+		return fmt.Errorf("failed to write pack registry: %w", err)
+	}
+	return nil
+}
+
+// InstallPack fetches a skill pack from a directory, a .tar.gz archive, or a
+// git repository, verifies it against its manifest, and installs its skills
+// into the pack layer. Installing a pack that is already installed replaces it.
+func InstallPack(ctx context.Context, source string) (*InstalledPack, error) {
+	tmp, err := os.MkdirTemp("", "azd-copilot-pack-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	root, source, err := fetchPack(ctx, source, tmp)
+	if err != nil {
+		return nil, err
+	}
+	manifest, files, err := readPack(root)
+	if err != nil {
+		return nil, err
+	}
+	skills := sortedKeys(groupByAsset(skillKind, files))
+	if len(skills) == 0 {
+		return nil, fmt.Errorf("pack %s has no skills (<name>/SKILL.md)", manifest.Name)
+	}
+
+	packs, err := ListPacks()
+	if err != nil {
+		return nil, err
+	}
+	var previous *InstalledPack
+	for i, p := range packs {
+		if p.Name == manifest.Name {
+			previous = &packs[i]
+			continue
+		}
+		for _, s := range skills {
+			if slices.Contains(p.Skills, s) {
+				return nil, fmt.Errorf("skill %s is already installed by pack %s", s, p.Name)
+			}
+		}
+	}
+
+	dir, err := PacksDir()
+	if err != nil {
+		return nil, err
+	}
+	skillsDir := filepath.Join(dir, skillKind.dir)
+	if previous != nil {
+		if err := removeSkillDirs(skillsDir, previous.Skills); err != nil {
+			return nil, err
+		}
+	}
+	for rel, data := range files {
+		if !slices.Contains(skills, skillKind.name(rel)) {
+			continue
+		}
+		dest := filepath.Join(skillsDir, filepath.FromSlash(rel))
+		if err := fileutil.EnsureDir(filepath.Dir(dest)); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(dest), err)
+		}
+		if err := fileutil.AtomicWriteFile(dest, data, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", dest, err)
+		}
+	}
+
+	pack := InstalledPack{PackManifest: *manifest, Source: source, InstalledAt: time.Now().UTC(), Skills: skills}
+	packs = slices.DeleteFunc(packs, func(p InstalledPack) bool { return p.Name == manifest.Name })
+	if err := savePacks(append(packs, pack)); err != nil {
+		return nil, err
+	}
+	return &pack, nil
+}
+
+// UninstallPack removes an installed pack and its skills
+func UninstallPack(name string) (*InstalledPack, error) {
+	packs, err := ListPacks()
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(packs, func(p InstalledPack) bool { return p.Name == name })
+	if i < 0 {
+		return nil, fmt.Errorf("pack %q is not installed", name)
+	}
+	pack := packs[i]
+
+	dir, err := PacksDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := removeSkillDirs(filepath.Join(dir, skillKind.dir), pack.Skills); err != nil {
+		return nil, err
+	}
+	if err := savePacks(slices.Delete(packs, i, i+1)); err != nil {
+		return nil, err
+	}
+	return &pack, nil
+}
+
+// UpdatePacks reinstalls packs from their recorded sources. No names means
+// every installed pack.
+func UpdatePacks(ctx context.Context, names []string) ([]PackUpdate, error) {
+	packs, err := ListPacks()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(packs, func(p InstalledPack) bool { return p.Name == name }) {
+			return nil, fmt.Errorf("pack %q is not installed", name)
+		}
+	}
+
+	var updates []PackUpdate
+	for _, p := range packs {
+		if len(names) > 0 && !slices.Contains(names, p.Name) {
+			continue
+		}
+		pack, err := InstallPack(ctx, p.Source)
+		if err != nil {
+			return updates, fmt.Errorf("failed to update %s: %w", p.Name, err)
+		}
+		if pack.Name != p.Name {
+			return updates, fmt.Errorf("source of %s now provides pack %s", p.Name, pack.Name)
+		}
+		updates = append(updates, PackUpdate{Name: p.Name, From: p.Version, To: pack.Version})
+	}
+	return updates, nil
+}
+
+// PackDigest hashes pack files: the sha256 of one "<sha256 of content>  <path>\n"
+// line per file, sorted by slash-separated path (the sha256sum format).
+func PackDigest(files map[string][]byte) string {
+	h := sha256.New()
+	for _, rel := range sortedKeys(files) {
+		_, _ = fmt.Fprintf(h, "%s  %s\n", hashBytes(files[rel]), rel)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// readPack reads and verifies the pack rooted at root. The returned files
+// exclude the manifest and any .git directory.
+func readPack(root string) (*PackManifest, map[string][]byte, error) {
+	files, err := readDirFiles(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read pack: %w", err)
+	}
+	data, ok := files[PackManifestFile]
+	if !ok {
+		return nil, nil, fmt.Errorf("pack has no %s", PackManifestFile)
+	}
+	delete(files, PackManifestFile)
+	for rel := range files {
+		if rel == ".git" || strings.HasPrefix(rel, ".git/") {
+			delete(files, rel)
+		}
+	}
+
+	var m PackManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", PackManifestFile, err)
+	}
+	switch {
+	case !packNamePattern.MatchString(m.Name):
+		return nil, nil, fmt.Errorf("%s: invalid name %q (use lowercase letters, digits, and dashes)", PackManifestFile, m.Name)
+	case m.Version == "":
+		return nil, nil, fmt.Errorf("%s: version is required", PackManifestFile)
+	case m.SHA256 == "":
+		return nil, nil, fmt.Errorf("%s: sha256 is required", PackManifestFile)
+	}
+	if digest := PackDigest(files); !strings.EqualFold(m.SHA256, digest) {
+		return nil, nil, fmt.Errorf("pack %s %s failed verification: manifest sha256 is %s, content is %s", m.Name, m.Version, m.SHA256, digest)
+	}
+	return &m, files, nil
+}
+
+// removeSkillDirs removes the named skill directories under dir
+func removeSkillDirs(dir string, skills []string) error {
+	for _, s := range skills {
+		if err := os.RemoveAll(filepath.Join(dir, s)); err != nil {
+			return fmt.Errorf("failed to remove skill %s: %w", s, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writePack writes a pack with a correct manifest to dir and returns dir
+func writePack(t *testing.T, dir, name, version string, files map[string]string) string {
+	t.Helper()
+	contents := map[string][]byte{}
+	for rel, content := range files {
+		writeFile(t, filepath.Join(dir, filepath.FromSlash(rel)), content)
+		contents[rel] = []byte(content)
+	}
+	data, err := json.Marshal(PackManifest{Name: name, Version: version, SHA256: PackDigest(contents)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, PackManifestFile), string(data))
+	return dir
+}
+
+// writeTarGz archives dir under a top-level folder, as release tarballs do
+func writeTarGz(t *testing.T, dir, archive string) {
+	t.Helper()
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	files, err := readDirFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rel := range sortedKeys(files) {
+		if err := tw.WriteHeader(&tar.Header{Name: "pack-1.0/" + rel, Mode: 0o644, Size: int64(len(files[rel])), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(files[rel]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var packSkill = map[string]string{
+	"kusto-lite/SKILL.md":              "---\nname: kusto-lite\ndescription: Lightweight KQL\n---\n\nQuery things.\n",
+	"kusto-lite/references/queries.md": "# Queries\n",
+	"README.md":                        "Not a skill\n",
+}
+
+func TestInstallPack_Dir(t *testing.T) {
+	home, _ := setupLayers(t)
+	src := writePack(t, t.TempDir(), "contoso", "1.0.0", packSkill)
+
+	pack, err := InstallPack(context.Background(), src)
+	if err != nil {
+		t.Fatalf("InstallPack() error = %v", err)
+	}
+	if pack.Name != "contoso" || pack.Source != src || !slices.Equal(pack.Skills, []string{"kusto-lite"}) {
+		t.Errorf("pack = %+v", pack)
+	}
+	installed := filepath.Join(home, ".azd", "copilot", "packs", "skills")
+	if _, err := os.Stat(filepath.Join(installed, "kusto-lite", "references", "queries.md")); err != nil {
+		t.Errorf("reference file not installed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(installed, "README.md")); !os.IsNotExist(err) {
+		t.Error("files outside skill directories should not be installed")
+	}
+
+	skills, err := ResolveSkills("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := findResolved(skills, "kusto-lite"); r == nil || r.Layer != LayerPack {
+		t.Errorf("resolved kusto-lite = %+v, want the pack layer", r)
+	}
+
+	// Another pack may not claim the same skill
+	other := writePack(t, t.TempDir(), "fabrikam", "1.0.0", packSkill)
+	if _, err := InstallPack(context.Background(), other); err == nil || !strings.Contains(err.Error(), "contoso") {
+		t.Errorf("InstallPack(conflict) error = %v, want a conflict with contoso", err)
+	}
+
+	if _, err := UninstallPack("contoso"); err != nil {
+		t.Fatalf("UninstallPack() error = %v", err)
+	}
+	if packs, _ := ListPacks(); len(packs) != 0 {
+		t.Errorf("ListPacks() after uninstall = %+v", packs)
+	}
+	if _, err := os.Stat(filepath.Join(installed, "kusto-lite")); !os.IsNotExist(err) {
+		t.Error("uninstall left the skill directory")
+	}
+	if _, err := UninstallPack("contoso"); err == nil {
+		t.Error("UninstallPack(missing) error = nil")
+	}
+}
+
+func TestInstallPack_Verification(t *testing.T) {
+	setupLayers(t)
+	tests := []struct {
+		name    string
+		tamper  func(dir string)
+		wantErr string
+	}{
+		{"modified file", func(dir string) { writeFile(t, filepath.Join(dir, "kusto-lite", "SKILL.md"), "tampered") }, "failed verification"},
+		{"added file", func(dir string) { writeFile(t, filepath.Join(dir, "kusto-lite", "extra.md"), "x") }, "failed verification"},
+		{"no manifest", func(dir string) { _ = os.Remove(filepath.Join(dir, PackManifestFile)) }, "no " + PackManifestFile},
+		{"bad name", func(dir string) {
+			writeFile(t, filepath.Join(dir, PackManifestFile), `{"name":"Bad Name","version":"1","sha256":"x"}`)
+		}, "invalid name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writePack(t, t.TempDir(), "contoso", "1.0.0", packSkill)
+			tt.tamper(dir)
+			if _, err := InstallPack(context.Background(), dir); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("InstallPack() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInstallPack_Archive(t *testing.T) {
+	setupLayers(t)
+	archive := filepath.Join(t.TempDir(), "contoso-1.0.0.tar.gz")
+	writeTarGz(t, writePack(t, t.TempDir(), "contoso", "1.0.0", packSkill), archive)
+
+	pack, err := InstallPack(context.Background(), archive)
+	if err != nil {
+		t.Fatalf("InstallPack(archive) error = %v", err)
+	}
+	if pack.Version != "1.0.0" || pack.Source != archive {
+		t.Errorf("pack = %+v", pack)
+	}
+}
+
+func TestExtractTarGz_Traversal(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "evil.tar.gz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	_ = tw.WriteHeader(&tar.Header{Name: "../escape.md", Mode: 0o644, Size: 1, Typeflag: tar.TypeReg})
+	_, _ = tw.Write([]byte("x"))
+	_ = tw.Close()
+	_ = gz.Close()
+	_ = f.Close()
+
+	r, err := os.Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := extractTarGz(r, filepath.Join(t.TempDir(), "out")); err == nil {
+		t.Error("extractTarGz() accepted an entry outside the destination")
+	}
+}
+
+func TestInstallPack_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	setupLayers(t)
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_CONFIG_GLOBAL="+os.DevNull)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	bare := filepath.Join(t.TempDir(), "contoso.git")
+	git(".", "init", "--quiet", "--bare", bare)
+	work := writePack(t, t.TempDir(), "contoso", "1.0.0", packSkill)
+	git(work, "init", "--quiet")
+	git(work, "add", "-A")
+	git(work, "commit", "--quiet", "-m", "v1")
+	git(work, "push", "--quiet", bare, "HEAD:refs/heads/main")
+	git(bare, "symbolic-ref", "HEAD", "refs/heads/main")
+
+	pack, err := InstallPack(context.Background(), bare)
+	if err != nil {
+		t.Fatalf("InstallPack(git) error = %v", err)
+	}
+	if pack.Version != "1.0.0" || pack.Source != bare {
+		t.Errorf("pack = %+v", pack)
+	}
+
+	// Publish 1.1.0 with a second skill, then update from the recorded source
+	files := maps.Clone(packSkill)
+	files["kql-extra/SKILL.md"] = "---\nname: kql-extra\ndescription: More KQL\n---\n"
+	writePack(t, work, "contoso", "1.1.0", files)
+	git(work, "add", "-A")
+	git(work, "commit", "--quiet", "-m", "v1.1")
+	git(work, "push", "--quiet", bare, "HEAD:refs/heads/main")
+
+	updates, err := UpdatePacks(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("UpdatePacks() error = %v", err)
+	}
+	if len(updates) != 1 || updates[0] != (PackUpdate{Name: "contoso", From: "1.0.0", To: "1.1.0"}) {
+		t.Errorf("updates = %+v", updates)
+	}
+	packs, _ := ListPacks()
+	if len(packs) != 1 || !slices.Equal(packs[0].Skills, []string{"kql-extra", "kusto-lite"}) {
+		t.Errorf("packs after update = %+v", packs)
+	}
+	if _, err := UpdatePacks(context.Background(), []string{"missing"}); err == nil {
+		t.Error("UpdatePacks(missing) error = nil")
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Search weights per occurrence of a query term. Term frequency is damped
+// logarithmically so long reference docs don't drown out names.
+const (
+	weightName        = 10.0
+	weightDescription = 4.0
+	weightBody        = 1.0
+	weightReference   = 0.5
+)
+
+// SkillMatch is a skill ranked by a search
+type SkillMatch struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Layer       Layer   `json:"layer"`
+	Score       float64 `json:"score"`
+	File        string  `json:"file,omitempty"`    // Best matching file
+	Snippet     string  `json:"snippet,omitempty"` // First matching line in File
+}
+
+// searchField is searchable text of a skill; file is "" for frontmatter fields
+type searchField struct {
+	file   string
+	text   string
+	weight float64
+}
+
+// SearchSkills ranks skills by full-text relevance to query over names,
+// descriptions, SKILL.md bodies, and reference docs. Every query term must
+// match somewhere in a skill for it to be returned.
+func SearchSkills(projectRoot, query string) ([]SkillMatch, error) {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	resolved, err := ResolveSkills(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []SkillMatch
+	for _, r := range resolved {
+		main := skillKind.main(r.Name)
+		info := parseSkillInfo(r.Name, r.Files[main])
+		_, body := splitFrontmatter(r.Files[main])
+
+		fields := []searchField{
+			{"", r.Name, weightName},
+			{"", info.Description, weightDescription},
+			{main, body, weightBody},
+		}
+		for _, rel := range sortedKeys(r.Files) {
+			if rel != main && strings.HasSuffix(rel, ".md") {
+				fields = append(fields, searchField{rel, string(r.Files[rel]), weightReference})
+			}
+		}
+
+		match := SkillMatch{Name: r.Name, Description: info.Description, Layer: r.Layer}
+		bestFile := 0.0
+		matchedAll := true
+		for _, term := range terms {
+			termScore := 0.0
+			for _, f := range fields {
+				n := countTerm(f.text, term)
+				if n == 0 {
+					continue
+				}
+				s := f.weight * (1 + math.Log(float64(n)))
+				termScore += s
+				if f.file != "" && s > bestFile {
+					bestFile, match.File = s, f.file
+				}
+			}
+			if termScore == 0 {
+				matchedAll = false
+				break
+			}
+			match.Score += termScore
+		}
+		if !matchedAll {
+			continue
+		}
+		if match.File != "" {
+			match.Snippet = snippet(string(r.Files[match.File]), terms)
+		}
+		match.Score = math.Round(match.Score*100) / 100
+		matches = append(matches, match)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Name < matches[j].Name
+	})
+	return matches, nil
+}
+
+// tokenize splits text into lowercase words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// countTerm counts words in text that start with term, so "func" matches "functions"
+func countTerm(text, term string) int {
+	n := 0
+	for _, word := range tokenize(text) {
+		if strings.HasPrefix(word, term) {
+			n++
+		}
+	}
+	return n
+}
+
+// snippet returns the first non-frontmatter line of text containing a term
+func snippet(text string, terms []string) string {
+	_, body := splitFrontmatter([]byte(text))
+	for _, line := range strings.Split(body, "\n") {
+		lower := strings.ToLower(line)
+		for _, term := range terms {
+			if strings.Contains(lower, term) {
+				line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#->*|"))
+				if len(line) > 100 {
+					line = line[:97] + "..."
+				}
+				return line
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/stack"
+)
+
+func TestSearchSkills(t *testing.T) {
+	_, project := setupLayers(t)
+	skills := filepath.Join(project, ".azd", "copilot", "skills")
+	writeFile(t, filepath.Join(skills, "zebra-cache", "SKILL.md"), "---\nname: zebra-cache\ndescription: Caching for zebra services\n---\n\nUse zebra caches.\n")
+	writeFile(t, filepath.Join(skills, "other", "SKILL.md"), "---\nname: other\ndescription: Unrelated\n---\n\nSee references.\n")
+	writeFile(t, filepath.Join(skills, "other", "references", "notes.md"), "A zebra appears once, with a cache.\n")
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"zebra", []string{"zebra-cache", "other"}},      // Name outranks reference docs
+		{"zebra caching", []string{"zebra-cache"}},       // Every term must match
+		{"ZEBRA cach", []string{"zebra-cache", "other"}}, // Case-insensitive prefix match
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matches, err := SearchSkills("", tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, m := range matches {
+				got = append(got, m.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SearchSkills(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	matches, _ := SearchSkills("", "zebra")
+	if other := matches[1]; other.File != "other/references/notes.md" || other.Snippet == "" {
+		t.Errorf("reference match = %+v, want the notes file and a snippet", other)
+	}
+}
+
+func TestSuggestSkills(t *testing.T) {
+	setupLayers(t)
+	s := &stack.Stack{
+		HasAzureYAML: true,
+		Signals: []stack.Signal{
+			{Kind: stack.KindHost, Value: "function", Source: "azure.yaml"},
+			{Kind: stack.KindResource, Value: "Microsoft.DocumentDB/databaseAccounts", Source: "infra/main.bicep"},
+			{Kind: stack.KindDependency, Value: "@azure/functions", Source: "api/package.json"},
+		},
+	}
+
+	suggestions, err := SuggestSkills("", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, sg := range suggestions {
+		names = append(names, sg.Name)
+		if len(sg.Reasons) == 0 || sg.Description == "" {
+			t.Errorf("suggestion %+v needs reasons and a description", sg)
+		}
+	}
+	for _, want := range []string{"azure-functions", "avm-bicep-rules", "secure-defaults", "azure-validate"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("SuggestSkills() = %v, missing %s", names, want)
+		}
+	}
+	if names[0] != "azure-functions" {
+		t.Errorf("first suggestion = %s, want azure-functions (two signals)", names[0])
+	}
+	if slices.Contains(names, "azure-prepare") {
+		t.Error("azure-prepare should only be suggested without azure.yaml")
+	}
+}
+
+func TestSkillPrompt(t *testing.T) {
+	if got := SkillPrompt(nil); got != "" {
+		t.Errorf("SkillPrompt(nil) = %q, want empty", got)
+	}
+	want := "Skills relevant to this project: @a, @b. Consult them where they apply."
+	if got := SkillPrompt([]string{"a", "b"}); got != want {
+		t.Errorf("SkillPrompt() = %q, want %q", got, want)
+	}
+}
@@ -7,11 +7,9 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
-	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 
-	"github.com/jongio/azd-core/fileutil"
 	"gopkg.in/yaml.v3"
 )
 
@@ -32,6 +30,10 @@ type SkillInfo struct {
 	Name        string `yaml:"name"`
 	Description string `yaml:"description"`
 	Path        string
+	Layer       Layer   // Layer the skill resolves to
+	Shadows     []Layer // Lower layers it overrides
+	Merged      bool    // Extends the lower layer instead of replacing it
+	Disabled    bool    // Disabled in the project's .copilot.json
 }
 
 // allSkillSources returns all embedded skill filesystems with their root prefix.
@@ -48,61 +50,27 @@ func allSkillSources() []struct {
 	}
 }
 
-// InstallSkills extracts embedded skills to ~/.azd/copilot/skills/
-func InstallSkills() (string, int, error) {
-	home, err := os.UserHomeDir()
+// InstallSkills installs embedded skills to ~/.azd/copilot/skills/,
+// writing only changed files and pruning ones no longer shipped
+func InstallSkills() (*InstallReport, error) {
+	destDir, err := SkillsDir()
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
-
-	destDir := filepath.Join(home, ".azd", "copilot", "skills")
-	if err := fileutil.EnsureDir(destDir); err != nil {
-		return "", 0, fmt.Errorf("failed to create skills directory: %w", err)
+	files, err := embeddedSkillFiles()
+	if err != nil {
+		return nil, err
 	}
-
-	installed := 0
-	for _, src := range allSkillSources() {
-		err = fs.WalkDir(src.fs, src.prefix, func(path string, d fs.DirEntry, walkErr error) error {
-			if walkErr != nil {
-				return nil //nolint:nilerr // continue walking on individual entry errors
-			}
-			if d.IsDir() {
-				return nil
-			}
-
-			data, readErr := src.fs.ReadFile(path)
-			if readErr != nil {
-				return nil //nolint:nilerr // continue walking on individual file read errors
-			}
-
-			// Strip prefix for destination (both map to the same output dir)
-			relPath := strings.TrimPrefix(path, src.prefix+"/")
-			destPath := filepath.Join(destDir, relPath)
-
-			// Create parent directories
-			if err := fileutil.EnsureDir(filepath.Dir(destPath)); err != nil {
-				return nil //nolint:nilerr // continue walking on directory creation errors
-			}
-
-			// Write file atomically
-			if err := fileutil.AtomicWriteFile(destPath, data, 0644); err != nil {
-				return nil //nolint:nilerr // continue walking on file write errors
-			}
-
-			// Count top-level skill directories
-			parts := strings.Split(relPath, "/")
-			if strings.HasSuffix(relPath, "SKILL.md") && len(parts) == 2 {
-				installed++
-			}
-
-			return nil
-		})
-		if err != nil {
-			return destDir, installed, err
+	report, err := installFiles(destDir, files)
+	if err != nil {
+		return report, fmt.Errorf("failed to install skills: %w", err)
+	}
+	for rel := range files {
+		if name := skillKind.name(rel); name != "" && rel == skillKind.main(name) {
+			report.Count++
 		}
 	}
-
-	return destDir, installed, nil
+	return report, nil
 }
 
 // ListSkills returns information about all embedded skills
@@ -127,6 +95,7 @@ func ListSkills() ([]SkillInfo, error) {
 			}
 
 			skill := parseSkillInfo(entry.Name(), data)
+			skill.Layer = LayerEmbedded
 			skills = append(skills, skill)
 		}
 	}
@@ -134,9 +103,28 @@ func ListSkills() ([]SkillInfo, error) {
 	return skills, nil
 }
 
-// GetSkill returns information about a specific skill
+// ListLayeredSkills returns skills after applying user and project
+// overrides, with the layer each one comes from and whether it is disabled
+func ListLayeredSkills(projectRoot string) ([]SkillInfo, error) {
+	resolved, err := ResolveSkills(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	disabled := DisabledSkills()
+	skills := make([]SkillInfo, 0, len(resolved))
+	for _, r := range resolved {
+		skill := parseSkillInfo(r.Name, r.Files[skillKind.main(r.Name)])
+		skill.Layer, skill.Shadows, skill.Merged = r.Layer, r.Shadows, r.Merged
+		skill.Disabled = slices.Contains(disabled, r.Name)
+		skills = append(skills, skill)
+	}
+	return skills, nil
+}
+
+// GetSkill returns information about a specific skill, including overrides
+// for the current project
 func GetSkill(name string) (*SkillInfo, error) {
-	skills, err := ListSkills()
+	skills, err := ListLayeredSkills("")
 	if err != nil {
 		return nil, err
 	}
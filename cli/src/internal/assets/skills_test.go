@@ -164,15 +164,15 @@ invalid yaml content here
 }
 
 func TestInstallSkills(t *testing.T) {
-	dir, count, err := InstallSkills()
+	report, err := InstallSkills()
 	if err != nil {
 		t.Logf("InstallSkills() error = %v (may be expected if no home directory)", err)
 		return
 	}
 
-	t.Logf("InstallSkills() installed %d skills to %s", count, dir)
+	t.Logf("InstallSkills() installed %d skills to %s (%s)", report.Count, report.Dir, report.Summary())
 
-	if count == 0 {
+	if report.Count == 0 {
 		t.Log("InstallSkills() installed 0 skills (may be expected if already installed)")
 	}
 }
@@ -0,0 +1,158 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// InstallStatus compares installed asset files with the embedded copies
+type InstallStatus struct {
+	Dir      string   `json:"dir"`
+	Files    int      `json:"files"`
+	Missing  []string `json:"missing,omitempty"`
+	Modified []string `json:"modified,omitempty"`
+}
+
+// UpToDate reports whether every embedded file is installed unchanged
+func (s *InstallStatus) UpToDate() bool {
+	return len(s.Missing) == 0 && len(s.Modified) == 0
+}
+
+// AgentsDir returns the agent install directory (~/.azd/copilot/agents)
+func AgentsDir() (string, error) {
+	return installDir("agents")
+}
+
+// SkillsDir returns the skill install directory (~/.azd/copilot/skills)
+func SkillsDir() (string, error) {
+	return installDir("skills")
+}
+
+func installDir(kind string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".azd", "copilot", kind), nil
+}
+
+// CheckAgents compares installed agents with the embedded agents
+func CheckAgents() (*InstallStatus, error) {
+	dir, err := AgentsDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := embeddedAgentFiles()
+	if err != nil {
+		return nil, err
+	}
+	return compareInstalled(dir, files), nil
+}
+
+// CheckSkills compares installed skills with the embedded skills
+func CheckSkills() (*InstallStatus, error) {
+	dir, err := SkillsDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := embeddedSkillFiles()
+	if err != nil {
+		return nil, err
+	}
+	return compareInstalled(dir, files), nil
+}
+
+// Hash returns a digest of every embedded agent and skill file. It changes
+// whenever the assets shipped with the extension change.
+func Hash() (string, error) {
+	h := sha256.New()
+	for _, load := range []func() (map[string][]byte, error){embeddedAgentFiles, embeddedSkillFiles} {
+		files, err := load()
+		if err != nil {
+			return "", err
+		}
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(h, "%s\x00%d\x00", name, len(files[name]))
+			h.Write(files[name])
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// embeddedAgentFiles returns embedded agent files keyed by install-relative path
+func embeddedAgentFiles() (map[string][]byte, error) {
+	entries, err := fs.ReadDir(embeddedAgents, "agents")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded agents: %w", err)
+	}
+
+	files := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		data, err := embeddedAgents.ReadFile("agents/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read embedded agent %s: %w", entry.Name(), err)
+		}
+		files[entry.Name()] = data
+	}
+	return files, nil
+}
+
+// embeddedSkillFiles returns embedded skill files from all sources keyed by
+// install-relative path (slash-separated)
+func embeddedSkillFiles() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	for _, src := range allSkillSources() {
+		err := fs.WalkDir(src.fs, src.prefix, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+			if d.IsDir() {
+				return nil
+			}
+			data, err := src.fs.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files[strings.TrimPrefix(path, src.prefix+"/")] = data
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read embedded skills from %s: %w", src.prefix, err)
+		}
+	}
+	return files, nil
+}
+
+func compareInstalled(dir string, files map[string][]byte) *InstallStatus {
+	status := &InstallStatus{Dir: dir, Files: len(files)}
+	for rel, want := range files {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel))) //nolint:gosec // G304: path is under the asset install directory
+		switch {
+		case err != nil:
+			status.Missing = append(status.Missing, rel)
+		case !bytes.Equal(got, want):
+			status.Modified = append(status.Modified, rel)
+		}
+	}
+	sort.Strings(status.Missing)
+	sort.Strings(status.Modified)
+	return status
+}
@@ -0,0 +1,133 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/stack"
+)
+
+// SkillSuggestion is a skill recommended for the detected stack
+type SkillSuggestion struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Reasons     []string `json:"reasons"` // Stack signals that triggered it, e.g. "host function (azure.yaml)"
+}
+
+// skillRule recommends skills when any of its hosts or patterns match
+type skillRule struct {
+	skills   []string
+	hosts    []string // azure.yaml service hosts
+	patterns []string // Lowercase substrings of Bicep resource types, AVM modules, or dependency names
+}
+
+// skillRules map stack signals to skills. Skills that are not installed are skipped.
+var skillRules = []skillRule{
+	{skills: []string{"azure-functions"}, hosts: []string{"function"},
+		patterns: []string{"@azure/functions", "azure-functions", "azure/azure-functions-go"}},
+	{skills: []string{"container-app-acr-auth"}, hosts: []string{"containerapp", "aks"},
+		patterns: []string{"microsoft.app/containerapps", "microsoft.containerregistry", "avm/res/app/container-app", "avm/res/container-registry"}},
+	{skills: []string{"secure-defaults"},
+		patterns: []string{"microsoft.documentdb", "microsoft.storage", "microsoft.sql", "microsoft.dbforpostgresql", "microsoft.keyvault",
+			"avm/res/document-db", "avm/res/storage", "avm/res/sql", "avm/res/db-for-postgre-sql", "avm/res/key-vault",
+			"@azure/cosmos", "azure-cosmos", "azcosmos", "@azure/identity", "azure-identity", "azidentity"}},
+	{skills: []string{"azure-storage"},
+		patterns: []string{"microsoft.storage", "avm/res/storage", "@azure/storage-", "azure-storage-", "azblob", "azqueue"}},
+	{skills: []string{"azure-postgres"},
+		patterns: []string{"microsoft.dbforpostgresql", "avm/res/db-for-postgre-sql", "psycopg", "asyncpg", "jackc/pgx", "lib/pq"}},
+	{skills: []string{"azure-messaging"},
+		patterns: []string{"microsoft.servicebus", "microsoft.eventhub", "microsoft.eventgrid", "avm/res/service-bus", "avm/res/event-hub", "avm/res/event-grid",
+			"@azure/service-bus", "@azure/event-hubs", "azure-servicebus", "azure-eventhub", "azservicebus", "azeventhubs"}},
+	{skills: []string{"microsoft-foundry", "azure-ai"},
+		patterns: []string{"microsoft.cognitiveservices", "microsoft.machinelearningservices", "avm/res/cognitive-services", "avm/res/machine-learning-services",
+			"openai", "azure-ai-", "@azure-rest/ai-", "@azure/ai-", "semantic-kernel", "semantic_kernel", "langchain"}},
+	{skills: []string{"azure-aigateway"},
+		patterns: []string{"microsoft.apimanagement", "avm/res/api-management"}},
+	{skills: []string{"appinsights-instrumentation"},
+		patterns: []string{"microsoft.insights/components", "avm/res/insights/component", "applicationinsights", "monitor-opentelemetry"}},
+	{skills: []string{"azure-kusto"},
+		patterns: []string{"microsoft.kusto", "avm/res/kusto", "azure-kusto", "azure-kusto-data"}},
+	{skills: []string{"azure-rbac"},
+		patterns: []string{"microsoft.authorization/roleassignments", "avm/ptn/authorization"}},
+}
+
+// SuggestSkills recommends installed skills for a detected stack, most
+// strongly indicated first
+func SuggestSkills(projectRoot string, s *stack.Stack) ([]SkillSuggestion, error) {
+	skills, err := ListLayeredSkills(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	descriptions := map[string]string{}
+	for _, skill := range skills {
+		if !skill.Disabled {
+			descriptions[skill.Name] = skill.Description
+		}
+	}
+
+	reasons := map[string][]string{}
+	addReason := func(skill, reason string) {
+		if _, installed := descriptions[skill]; installed && !slices.Contains(reasons[skill], reason) {
+			reasons[skill] = append(reasons[skill], reason)
+		}
+	}
+
+	for _, rule := range skillRules {
+		for _, sig := range s.Signals {
+			value := strings.ToLower(sig.Value)
+			matched := sig.Kind == stack.KindHost && slices.Contains(rule.hosts, value)
+			if sig.Kind == stack.KindResource || sig.Kind == stack.KindDependency {
+				matched = slices.ContainsFunc(rule.patterns, func(p string) bool { return strings.Contains(value, p) })
+			}
+			if !matched {
+				continue
+			}
+			for _, skill := range rule.skills {
+				addReason(skill, fmt.Sprintf("%s %s (%s)", sig.Kind, sig.Value, sig.Source))
+			}
+		}
+	}
+
+	// Infrastructure as code and project lifecycle
+	for _, sig := range s.Signals {
+		if sig.Kind == stack.KindResource {
+			addReason("avm-bicep-rules", fmt.Sprintf("Bicep infrastructure (%s)", sig.Source))
+			break
+		}
+	}
+	if s.HasAzureYAML {
+		addReason("azure-validate", "azd project (azure.yaml)")
+	} else {
+		addReason("azure-prepare", "no azure.yaml yet")
+	}
+
+	suggestions := make([]SkillSuggestion, 0, len(reasons))
+	for name, r := range reasons {
+		suggestions = append(suggestions, SkillSuggestion{Name: name, Description: descriptions[name], Reasons: r})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if len(suggestions[i].Reasons) != len(suggestions[j].Reasons) {
+			return len(suggestions[i].Reasons) > len(suggestions[j].Reasons)
+		}
+		return suggestions[i].Name < suggestions[j].Name
+	})
+	return suggestions, nil
+}
+
+// SkillPrompt returns a prompt preamble pointing the session at skills,
+// or "" when there are none
+func SkillPrompt(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	refs := make([]string, len(names))
+	for i, name := range names {
+		refs[i] = "@" + name
+	}
+	return fmt.Sprintf("Skills relevant to this project: %s. Consult them where they apply.", strings.Join(refs, ", "))
+}
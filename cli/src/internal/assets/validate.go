@@ -0,0 +1,374 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity of a validation finding. The values match SARIF result levels.
+type Severity string
+
+// SeverityError through SeverityNote are the finding severities, most severe first.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// Validation rule IDs
+const (
+	RuleFrontmatter  = "frontmatter"   // Missing or invalid frontmatter, name, or description
+	RuleUnknownTool  = "unknown-tool"  // Agent tools entry that is not a Copilot CLI tool
+	RuleNameMismatch = "name-mismatch" // Frontmatter name differs from the file or directory name
+	RuleBrokenLink   = "broken-link"   // Relative markdown link to a file that does not exist
+	RuleUnknownSkill = "unknown-skill" // Agent references a skill that does not exist
+	RuleDuplicate    = "duplicate"     // Same name declared twice, or overridden by a higher layer
+	RuleOversized    = "oversized"     // File large enough to crowd the context window
+	RuleBudget       = "budget"        // Agent whose total loaded context is over the limit
+)
+
+// Rules describes each validation rule, keyed by ID
+var Rules = map[string]string{
+	RuleFrontmatter:  "Agents and skills need YAML frontmatter with name and description",
+	RuleUnknownTool:  "Agent tools must be Copilot CLI tools or MCP tools (server/tool)",
+	RuleNameMismatch: "The frontmatter name must match the file or directory name",
+	RuleBrokenLink:   "Relative links to markdown files must resolve",
+	RuleUnknownSkill: "Skills referenced by agents must exist",
+	RuleDuplicate:    "Asset names must be unique; overrides shadow lower layers",
+	RuleOversized:    "Files should fit comfortably in the context budget",
+	RuleBudget:       "Agents, with the skills they reference, should fit in the context budget",
+}
+
+// KnownTools are the tool names agents may list, besides MCP tools written
+// as "server/tool" or "server/*"
+var KnownTools = []string{"*", "read", "edit", "execute", "search", "agent", "web", "todo", "ask_user", "shell", "write", "custom-agent"}
+
+// Finding is a validation problem in an agent or skill file
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Layer    Layer    `json:"layer,omitempty"` // Empty for --dir sources
+	Path     string   `json:"path"`            // File path, relative to the current directory where possible
+	Line     int      `json:"line,omitempty"`
+}
+
+// ValidateOptions selects what to validate
+type ValidateOptions struct {
+	ProjectRoot string             // "" means the current directory
+	Layers      []Layer            // Layers to report on; empty means all
+	Dir         string             // Validate this directory instead of the layers
+	Limits      *spec.BudgetLimits // Token limits; nil means LoadBudgetLimits
+}
+
+var (
+	// linkPattern matches markdown links, capturing the target
+	linkPattern = regexp.MustCompile(`\]\(([^)\s]+)\)`)
+	// skillRefPattern matches @skill references in agent skill tables
+	skillRefPattern = regexp.MustCompile(`^\|\s*@([a-z0-9][a-z0-9-]*)\s*\|`)
+)
+
+// source is one directory of agent or skill files being validated
+type source struct {
+	layer Layer
+	root  string // Display prefix for paths
+	files map[string][]byte
+}
+
+// ValidateAgents checks agent files in the selected layers
+func ValidateAgents(opts ValidateOptions) ([]Finding, error) {
+	return validateKind(agentKind, opts)
+}
+
+// ValidateSkills checks skill files in the selected layers
+func ValidateSkills(opts ValidateOptions) ([]Finding, error) {
+	return validateKind(skillKind, opts)
+}
+
+// HasErrors reports whether any finding is an error
+func HasErrors(findings []Finding) bool {
+	return slices.ContainsFunc(findings, func(f Finding) bool { return f.Severity == SeverityError })
+}
+
+func validateKind(kind assetKind, opts ValidateOptions) ([]Finding, error) {
+	sources, err := loadSources(kind, opts)
+	if err != nil {
+		return nil, err
+	}
+	// Skill references resolve against every layer, plus the validated directory
+	skillNames, err := knownSkillNames(opts, sources, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := LoadBudgetLimits()
+	if opts.Limits != nil {
+		limits = *opts.Limits
+	}
+
+	var findings []Finding
+	seen := map[string]Layer{} // Asset name → lowest layer that provides it
+	for _, src := range sources {
+		report := opts.Dir != "" || len(opts.Layers) == 0 || slices.Contains(opts.Layers, src.layer)
+		for _, name := range sortedKeys(groupByAsset(kind, src.files)) {
+			if lower, ok := seen[name]; ok && report {
+				findings = append(findings, src.finding(RuleDuplicate, SeverityNote, kind.main(name), 0,
+					fmt.Sprintf("%s overrides the %s %s of the same name", name, lower, strings.TrimSuffix(kind.dir, "s"))))
+			}
+			if _, ok := seen[name]; !ok {
+				seen[name] = src.layer
+			}
+		}
+		if report {
+			findings = append(findings, validateSource(kind, src, skillNames, limits)...)
+		}
+	}
+	if kind.dir == agentKind.dir && opts.Dir == "" {
+		budget, err := budgetFindings(opts, sources, limits)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, budget...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings, nil
+}
+
+// loadSources returns the directories to validate, lowest layer first
+func loadSources(kind assetKind, opts ValidateOptions) ([]source, error) {
+	if opts.Dir != "" {
+		files, err := readDirFiles(opts.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", opts.Dir, err)
+		}
+		return []source{{root: opts.Dir, files: files}}, nil
+	}
+
+	embedded, err := kind.embedded()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := overrideDirs(kind, opts.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+	sources := []source{{layer: LayerEmbedded, root: "embedded:" + kind.dir, files: embedded}}
+	for _, layer := range OverrideLayers {
+		files, err := readDirFiles(dirs[layer])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s %s: %w", layer, kind.dir, err)
+		}
+		sources = append(sources, source{layer: layer, root: dirs[layer], files: files})
+	}
+	return sources, nil
+}
+
+// knownSkillNames returns every skill name an agent may reference
+func knownSkillNames(opts ValidateOptions, sources []source, kind assetKind) (map[string]bool, error) {
+	names := map[string]bool{}
+	resolved, err := ResolveSkills(opts.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resolved {
+		names[r.Name] = true
+	}
+	if kind.dir == skillKind.dir {
+		for _, src := range sources {
+			for name := range groupByAsset(skillKind, src.files) {
+				names[name] = true
+			}
+		}
+	}
+	return names, nil
+}
+
+func validateSource(kind assetKind, src source, skillNames map[string]bool, limits spec.BudgetLimits) []Finding {
+	var findings []Finding
+	declared := map[string]string{} // Frontmatter name → main file
+
+	for _, rel := range sortedKeys(src.files) {
+		data := src.files[rel]
+		if tokens := EstimateTokens(data); limits.FileTokens > 0 && tokens > limits.FileTokens {
+			findings = append(findings, src.finding(RuleOversized, SeverityWarning, rel, 0,
+				fmt.Sprintf("~%d tokens exceeds the %d-token budget for a single file", tokens, limits.FileTokens)))
+		}
+		if strings.HasSuffix(rel, ".md") {
+			findings = append(findings, checkLinks(src, rel)...)
+		}
+
+		name := kind.name(rel)
+		if name == "" || rel != kind.main(name) {
+			continue
+		}
+		findings = append(findings, checkFrontmatter(kind, src, rel, name, declared)...)
+		if kind.dir == agentKind.dir {
+			findings = append(findings, checkSkillRefs(src, rel, skillNames)...)
+		}
+	}
+
+	// A skill directory without SKILL.md is never loaded
+	if kind.dir == skillKind.dir {
+		dirs := map[string]bool{}
+		for rel := range src.files {
+			if name := kind.name(rel); name != "" {
+				dirs[name] = true
+			}
+		}
+		for _, name := range sortedKeys(dirs) {
+			if _, ok := src.files[kind.main(name)]; !ok {
+				findings = append(findings, src.finding(RuleFrontmatter, SeverityError, name, 0,
+					fmt.Sprintf("skill directory %s has no SKILL.md", name)))
+			}
+		}
+	}
+	return findings
+}
+
+// budgetFindings reports agents whose total loaded context is over the
+// limit, at the file of the layer the agent resolves to
+func budgetFindings(opts ValidateOptions, sources []source, limits spec.BudgetLimits) ([]Finding, error) {
+	if limits.AgentTokens <= 0 {
+		return nil, nil
+	}
+	report, err := AnalyzeBudget(opts.ProjectRoot, limits)
+	if err != nil {
+		return nil, err
+	}
+	layers := map[string]Layer{}
+	for _, f := range report.Files {
+		if f.Kind == BudgetAgent {
+			layers[f.Asset] = f.Layer
+		}
+	}
+
+	var findings []Finding
+	for _, b := range report.Agents {
+		layer := layers[b.Agent]
+		if b.Total <= limits.AgentTokens || (len(opts.Layers) > 0 && !slices.Contains(opts.Layers, layer)) {
+			continue
+		}
+		for _, src := range sources {
+			if src.layer == layer {
+				findings = append(findings, src.finding(RuleBudget, SeverityWarning, agentKind.main(b.Agent), 0,
+					fmt.Sprintf("loads ~%d tokens with its %d skills, over the %d-token agent budget", b.Total, len(b.Skills), limits.AgentTokens)))
+			}
+		}
+	}
+	return findings, nil
+}
+
+func checkFrontmatter(kind assetKind, src source, rel, name string, declared map[string]string) []Finding {
+	fm, _ := splitFrontmatter(src.files[rel])
+	if fm == "" {
+		return []Finding{src.finding(RuleFrontmatter, SeverityError, rel, 1, "missing YAML frontmatter (--- ... ---)")}
+	}
+	var frontmatter struct {
+		Name        string   `yaml:"name"`
+		Description string   `yaml:"description"`
+		Tools       []string `yaml:"tools"`
+		Merge       string   `yaml:"merge"`
+	}
+	if err := yaml.Unmarshal([]byte(fm), &frontmatter); err != nil {
+		return []Finding{src.finding(RuleFrontmatter, SeverityError, rel, 1, fmt.Sprintf("invalid frontmatter: %v", err))}
+	}
+
+	var findings []Finding
+	if frontmatter.Merge != "" && frontmatter.Merge != MergeAppend {
+		findings = append(findings, src.finding(RuleFrontmatter, SeverityError, rel, 1,
+			fmt.Sprintf("unknown merge mode %q (only %q is supported)", frontmatter.Merge, MergeAppend)))
+	}
+	// An extending override inherits name and description from the lower layer
+	if frontmatter.Merge != MergeAppend {
+		if frontmatter.Name == "" {
+			findings = append(findings, src.finding(RuleFrontmatter, SeverityError, rel, 1, "frontmatter is missing name"))
+		}
+		if frontmatter.Description == "" {
+			findings = append(findings, src.finding(RuleFrontmatter, SeverityError, rel, 1, "frontmatter is missing description"))
+		}
+	}
+	if frontmatter.Name != "" && frontmatter.Name != name {
+		findings = append(findings, src.finding(RuleNameMismatch, SeverityError, rel, 1,
+			fmt.Sprintf("name %q does not match %s", frontmatter.Name, path.Base(strings.TrimSuffix(kind.main(name), "/SKILL.md")))))
+	}
+	if frontmatter.Name != "" {
+		if other, ok := declared[frontmatter.Name]; ok {
+			findings = append(findings, src.finding(RuleDuplicate, SeverityError, rel, 1,
+				fmt.Sprintf("name %q is also declared by %s", frontmatter.Name, other)))
+		} else {
+			declared[frontmatter.Name] = rel
+		}
+	}
+	for _, tool := range frontmatter.Tools {
+		if !slices.Contains(KnownTools, tool) && !strings.Contains(tool, "/") {
+			findings = append(findings, src.finding(RuleUnknownTool, SeverityError, rel, 1,
+				fmt.Sprintf("unknown tool %q (known: %s, or server/tool for MCP tools)", tool, strings.Join(KnownTools, ", "))))
+		}
+	}
+	return findings
+}
+
+// checkLinks reports relative links to markdown files that do not exist in the source
+func checkLinks(src source, rel string) []Finding {
+	var findings []Finding
+	forEachLine(src.files[rel], func(line int, text string) {
+		for _, m := range linkPattern.FindAllStringSubmatch(text, -1) {
+			target, _, _ := strings.Cut(m[1], "#")
+			if !strings.HasSuffix(target, ".md") || strings.Contains(target, "://") || strings.HasPrefix(target, "/") {
+				continue
+			}
+			resolved := path.Join(path.Dir(rel), target)
+			if _, ok := src.files[resolved]; !ok {
+				findings = append(findings, src.finding(RuleBrokenLink, SeverityWarning, rel, line,
+					fmt.Sprintf("link target %s does not exist", target)))
+			}
+		}
+	})
+	return findings
+}
+
+// checkSkillRefs reports @skill references in an agent that match no skill
+func checkSkillRefs(src source, rel string, skillNames map[string]bool) []Finding {
+	var findings []Finding
+	forEachLine(src.files[rel], func(line int, text string) {
+		if m := skillRefPattern.FindStringSubmatch(text); m != nil && !skillNames[m[1]] {
+			findings = append(findings, src.finding(RuleUnknownSkill, SeverityWarning, rel, line,
+				fmt.Sprintf("skill @%s does not exist", m[1])))
+		}
+	})
+	return findings
+}
+
+func forEachLine(data []byte, fn func(line int, text string)) {
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		fn(line, scanner.Text())
+	}
+}
+
+func (s source) finding(rule string, severity Severity, rel string, line int, message string) Finding {
+	p := s.root + "/" + rel
+	if s.layer != LayerEmbedded {
+		p = filepath.Join(s.root, filepath.FromSlash(rel))
+	}
+	return Finding{Rule: rule, Severity: severity, Message: message, Layer: s.layer, Path: p, Line: line}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package assets
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateAgents(t *testing.T) {
+	_, project := setupLayers(t)
+	dir := filepath.Join(project, ".azd", "copilot", "agents")
+	writeFile(t, filepath.Join(dir, "good.md"), "---\nname: good\ndescription: Fine\ntools: [\"read\", \"azure/*\"]\n---\n\n| Skill | Purpose |\n|---|---|\n| @azure-prepare | Prepare |\n")
+	writeFile(t, filepath.Join(dir, "bad.md"), "---\nname: wrong\ntools: [\"teleport\"]\n---\n\n| @no-such-skill | Nope |\n\nSee [guide](references/guide.md).\n")
+	writeFile(t, filepath.Join(dir, "plain.md"), "No frontmatter here")
+	writeFile(t, filepath.Join(dir, "azure-dev.md"), "---\nmerge: append\n---\n\nExtra rules.\n")
+
+	findings, err := ValidateAgents(ValidateOptions{Layers: []Layer{LayerProject}})
+	if err != nil {
+		t.Fatalf("ValidateAgents() error = %v", err)
+	}
+
+	want := map[string][]string{
+		"bad.md":       {RuleFrontmatter, RuleNameMismatch, RuleUnknownTool, RuleUnknownSkill, RuleBrokenLink},
+		"plain.md":     {RuleFrontmatter},
+		"azure-dev.md": {RuleDuplicate},
+	}
+	got := map[string][]string{}
+	for _, f := range findings {
+		if f.Layer != LayerProject {
+			t.Errorf("finding outside the requested layer: %+v", f)
+		}
+		got[filepath.Base(f.Path)] = append(got[filepath.Base(f.Path)], f.Rule)
+	}
+	for file, rules := range want {
+		for _, rule := range rules {
+			if !strings.Contains(strings.Join(got[file], ","), rule) {
+				t.Errorf("%s: missing %s finding, got %v", file, rule, got[file])
+			}
+		}
+	}
+	if len(got["good.md"]) != 0 {
+		t.Errorf("good.md findings = %v, want none", got["good.md"])
+	}
+	if !HasErrors(findings) {
+		t.Error("HasErrors() = false, want true")
+	}
+}
+
+func TestValidateSkills_Dir(t *testing.T) {
+	setupLayers(t)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ok", "SKILL.md"), "---\nname: ok\ndescription: Fine\n---\n\nSee [a](references/a.md) and [site](https://example.com/x.md).\n")
+	writeFile(t, filepath.Join(dir, "ok", "references", "a.md"), "# A\n")
+	writeFile(t, filepath.Join(dir, "big", "SKILL.md"), "---\nname: big\ndescription: Large\n---\n"+strings.Repeat("word ", DefaultBudgetLimits.FileTokens+1))
+	writeFile(t, filepath.Join(dir, "orphan", "notes.md"), "no SKILL.md")
+
+	findings, err := ValidateSkills(ValidateOptions{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := map[string]Severity{}
+	for _, f := range findings {
+		rules[f.Rule] = f.Severity
+		if strings.HasPrefix(f.Path, filepath.Join(dir, "ok")+string(filepath.Separator)) {
+			t.Errorf("unexpected finding for ok skill: %+v", f)
+		}
+	}
+	if rules[RuleOversized] != SeverityWarning || rules[RuleFrontmatter] != SeverityError {
+		t.Errorf("findings = %+v, want an oversized warning and a missing SKILL.md error", findings)
+	}
+}
+
+func TestValidate_Embedded(t *testing.T) {
+	setupLayers(t)
+	for name, validate := range map[string]func(ValidateOptions) ([]Finding, error){"agents": ValidateAgents, "skills": ValidateSkills} {
+		findings, err := validate(ValidateOptions{Layers: []Layer{LayerEmbedded}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range findings {
+			if f.Severity == SeverityError {
+				t.Errorf("embedded %s: %s:%d %s [%s]", name, f.Path, f.Line, f.Message, f.Rule)
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package audit records agent-driven actions in an append-only JSONL log
+// under the project.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/redact"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+	"github.com/jongio/azd-core/fileutil"
+)
+
+// DefaultFile is the audit log location relative to the project root
+var DefaultFile = filepath.Join(spec.DocsDir, ".copilot-audit.jsonl")
+
+// Kind groups audit events by source
+type Kind string
+
+// KindTool through KindHook represent audited event sources.
+const (
+	KindTool       Kind = "tool"       // MCP tool invocation from the azd-copilot server
+	KindLaunch     Kind = "launch"     // Copilot CLI session
+	KindEnv        Kind = "env"        // azd environment value change
+	KindCheckpoint Kind = "checkpoint" // Checkpoint creation or deletion
+	KindHook       Kind = "hook"       // azd lifecycle event received by 'listen'
+)
+
+// Kinds lists every event kind
+var Kinds = []Kind{KindTool, KindLaunch, KindEnv, KindCheckpoint, KindHook}
+
+// Outcome is the result of an audited action
+type Outcome string
+
+// OutcomeOK through OutcomeConfirmationRequired represent audited outcomes.
+const (
+	OutcomeOK                   Outcome = "ok"
+	OutcomeError                Outcome = "error"
+	OutcomeDenied               Outcome = "denied"
+	OutcomeDeclined             Outcome = "declined"
+	OutcomeDryRun               Outcome = "dry_run"
+	OutcomeConfirmationRequired Outcome = "confirmation_required"
+)
+
+// Outcomes lists every outcome
+var Outcomes = []Outcome{OutcomeOK, OutcomeError, OutcomeDenied, OutcomeDeclined, OutcomeDryRun, OutcomeConfirmationRequired}
+
+// Event is one audited action
+type Event struct {
+	Time    time.Time              `json:"time"`
+	Kind    Kind                   `json:"kind"`
+	Name    string                 `json:"name"`
+	Args    map[string]interface{} `json:"args,omitempty"`
+	Outcome Outcome                `json:"outcome"`
+	Detail  string                 `json:"detail,omitempty"`
+}
+
+var mu sync.Mutex
+
+// Path returns the audit log for the current project, or "" outside a
+// project, where nothing is audited. The project is the nearest directory
+// holding azure.yaml or .copilot.json.
+func Path() string {
+	root, ok := spec.ProjectRoot()
+	if !ok {
+		return ""
+	}
+	m, err := spec.LoadMetadataIn(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using the default audit log\n", err)
+	}
+	file := DefaultFile
+	if m.AuditFile != "" {
+		file = m.AuditFile
+	}
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(root, file)
+}
+
+// Record appends an event to the audit log, stamping the time when unset.
+// Outside a project nothing is recorded.
+func Record(e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit event: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	p := Path()
+	if p == "" {
+		return nil // Not in a project
+	}
+	if err := fileutil.EnsureDir(filepath.Dir(p)); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // G304: path comes from project metadata
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// RecordBestEffort appends an event to the audit log for callers where
+// auditing must never fail the action. Failures are logged at debug level.
+func RecordBestEffort(e Event) {
+	if err := Record(e); err != nil {
+		slog.Debug("Failed to write audit log", "kind", e.Kind, "name", e.Name, "error", err)
+	}
+}
+
+// Hash returns a short, stable fingerprint of s so prompts can be correlated
+// without storing them
+func Hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Redacted replaces secret argument values in the audit log
+const Redacted = redact.Mask
+
+// secretArgNames are substrings of argument names whose values are never logged
+var secretArgNames = []string{"value", "secret", "password", "token", "apikey", "api_key", "connection_string", "credential"}
+
+// RedactArgs returns a copy of args with the values of secret-looking
+// arguments replaced by Redacted and secrets embedded in other string
+// arguments masked. --show-secrets does not apply to the audit log.
+func RedactArgs(args map[string]interface{}) map[string]interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	r := redact.LoadForStorage()
+	out := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		out[k] = v
+		if isSecretArgName(k) {
+			out[k] = Redacted
+		} else if s, ok := v.(string); ok {
+			out[k] = r.Text(r.Value(k, s))
+		}
+	}
+	return out
+}
+
+func isSecretArgName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, n := range secretArgNames {
+		if strings.Contains(lower, n) {
+			return true
+		}
+	}
+	return false
+}
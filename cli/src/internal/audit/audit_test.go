@@ -0,0 +1,101 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+// chdirProject moves into a new project directory, where events are audited
+func chdirProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile("azure.yaml", []byte("name: app\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRecord(t *testing.T) {
+	chdirProject(t)
+
+	for _, outcome := range []Outcome{OutcomeOK, OutcomeDenied} {
+		if err := Record(Event{Kind: KindTool, Name: "run_workflow", Outcome: outcome}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(DefaultFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("log has %d lines, want 2", len(lines))
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Outcome != OutcomeDenied || e.Time.IsZero() {
+		t.Errorf("second event = %+v", e)
+	}
+}
+
+func TestPath_FromMetadata(t *testing.T) {
+	chdirProject(t)
+	if Path() != DefaultFile {
+		t.Errorf("Path() = %s, want %s", Path(), DefaultFile)
+	}
+
+	m := spec.DefaultMetadata()
+	m.AuditFile = "audit/log.jsonl"
+	if err := spec.SaveMetadata(m); err != nil {
+		t.Fatal(err)
+	}
+	if Path() != "audit/log.jsonl" {
+		t.Errorf("Path() = %s, want audit/log.jsonl", Path())
+	}
+}
+
+func TestRecord_OutsideProject(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	if p := Path(); p != "" {
+		t.Errorf("Path() outside a project = %q, want empty", p)
+	}
+	if err := Record(Event{Kind: KindLaunch, Name: "azd copilot", Outcome: OutcomeOK}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Record() outside a project created %v", entries)
+	}
+}
+
+func TestRecord_FromSubdirectory(t *testing.T) {
+	root := chdirProject(t)
+	sub := filepath.Join(root, "src", "api")
+	if err := os.MkdirAll(sub, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+
+	if err := Record(Event{Kind: KindTool, Name: "list_agents", Outcome: OutcomeOK}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, DefaultFile)); err != nil {
+		t.Errorf("audit log not written at the project root: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(sub, spec.DocsDir)); !os.IsNotExist(err) {
+		t.Error("Record() created docs/ in the subdirectory")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package audit
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter selects audit events. Zero fields match everything.
+type Filter struct {
+	Since    time.Time
+	Until    time.Time
+	Kinds    []Kind
+	Name     string // Glob, e.g. "run_*"
+	Outcomes []Outcome
+}
+
+// Match reports whether e passes the filter
+func (f Filter) Match(e Event) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, e.Kind) {
+		return false
+	}
+	if len(f.Outcomes) > 0 && !slices.Contains(f.Outcomes, e.Outcome) {
+		return false
+	}
+	if f.Name != "" {
+		if matched, err := path.Match(f.Name, e.Name); err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Read returns the events in the audit log that pass the filter, oldest
+// first. A missing log, or no project, has no events. Lines that cannot be
+// parsed, such as a write torn by a crash, are skipped.
+func Read(f Filter) ([]Event, error) {
+	p := Path()
+	if p == "" {
+		return nil, nil // Not in a project
+	}
+	file, err := os.Open(p) //nolint:gosec // G304: path comes from project metadata
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if f.Match(e) {
+			events = append(events, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return events, nil
+}
+
+// WriteCSV writes events as CSV with a header row. Args are encoded as JSON.
+func WriteCSV(w io.Writer, events []Event) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"time", "kind", "name", "outcome", "detail", "args"}); err != nil {
+		return err
+	}
+	for _, e := range events {
+		args := ""
+		if len(e.Args) > 0 {
+			data, err := json.Marshal(e.Args)
+			if err != nil {
+				return fmt.Errorf("failed to encode args for %s: %w", e.Name, err)
+			}
+			args = string(data)
+		}
+		if err := cw.Write([]string{e.Time.Format(time.RFC3339), string(e.Kind), e.Name, string(e.Outcome), e.Detail, args}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ParseTime parses a filter bound relative to now: an RFC 3339 timestamp,
+// a local date (2006-01-02), or an age such as "90m", "24h", or "7d".
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use an RFC 3339 time, a date (2006-01-02), or an age like 24h or 7d", s)
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilter_Match(t *testing.T) {
+	at := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	e := Event{Time: at, Kind: KindTool, Name: "run_workflow", Outcome: OutcomeDenied}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"since before", Filter{Since: at.Add(-time.Hour)}, true},
+		{"since after", Filter{Since: at.Add(time.Hour)}, false},
+		{"until is exclusive", Filter{Until: at}, false},
+		{"kind", Filter{Kinds: []Kind{KindLaunch, KindTool}}, true},
+		{"other kind", Filter{Kinds: []Kind{KindEnv}}, false},
+		{"name glob", Filter{Name: "run_*"}, true},
+		{"other name", Filter{Name: "set_*"}, false},
+		{"outcome", Filter{Outcomes: []Outcome{OutcomeDenied}}, true},
+		{"other outcome", Filter{Outcomes: []Outcome{OutcomeOK}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(e); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	chdirProject(t)
+
+	events, err := Read(Filter{})
+	if err != nil || len(events) != 0 {
+		t.Fatalf("Read() without a log = %v, %v", events, err)
+	}
+
+	for _, e := range []Event{
+		{Kind: KindLaunch, Name: "azd copilot build", Outcome: OutcomeOK},
+		{Kind: KindTool, Name: "read_spec", Outcome: OutcomeOK},
+		{Kind: KindTool, Name: "run_workflow", Outcome: OutcomeDenied},
+	} {
+		if err := Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A torn write is skipped rather than failing the whole log
+	f, err := os.OpenFile(filepath.Clean(DefaultFile), os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"time":"2026-`)
+	_ = f.Close()
+
+	events, err = Read(Filter{Kinds: []Kind{KindTool}})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(events) != 2 || events[0].Name != "read_spec" || events[1].Name != "run_workflow" {
+		t.Errorf("Read() = %+v, want the two tool events in order", events)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	at := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []Event{{
+		Time:    at,
+		Kind:    KindTool,
+		Name:    "run_workflow",
+		Args:    map[string]interface{}{"workflow_name": "ship", "steps": "up"},
+		Outcome: OutcomeDenied,
+		Detail:  "down --purge, by policy",
+	}})
+	if err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	want := "time,kind,name,outcome,detail,args\n" +
+		`2026-03-10T12:00:00Z,tool,run_workflow,denied,"down --purge, by policy","{""steps"":""up"",""workflow_name"":""ship""}"` + "\n"
+	if buf.String() != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2026-03-09T08:30:00Z", time.Date(2026, 3, 9, 8, 30, 0, 0, time.UTC), false},
+		{"2026-03-03", time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), false},
+		{"7d", now.AddDate(0, 0, -7), false},
+		{"90m", now.Add(-90 * time.Minute), false},
+		{"last tuesday", time.Time{}, true},
+		{"-2h", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTime(tt.in, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	got := RedactArgs(map[string]interface{}{
+		"key":          "AZURE_LOCATION",
+		"value":        "eastus",
+		"api_key":      "abc",
+		"DB_PASSWORD":  "hunter2",
+		"workflow":     "ship",
+		"access_token": "eyJ",
+	})
+	for k, want := range map[string]interface{}{
+		"key":          "AZURE_LOCATION",
+		"value":        Redacted,
+		"api_key":      Redacted,
+		"DB_PASSWORD":  Redacted,
+		"workflow":     "ship",
+		"access_token": Redacted,
+	} {
+		if got[k] != want {
+			t.Errorf("RedactArgs()[%s] = %v, want %v", k, got[k], want)
+		}
+	}
+	if RedactArgs(nil) != nil {
+		t.Error("RedactArgs(nil) should be nil")
+	}
+}
+
+func TestHash(t *testing.T) {
+	if h := Hash("prompt"); len(h) != 16 || strings.Trim(h, "0123456789abcdef") != "" {
+		t.Errorf("Hash() = %q, want 16 hex characters", h)
+	}
+	if Hash("a") == Hash("b") {
+		t.Error("Hash() should differ for different input")
+	}
+}
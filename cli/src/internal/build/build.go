@@ -0,0 +1,336 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package build drives 'azd copilot build' through its phases: one Copilot
+// turn per phase, with each phase's outputs verified and checkpointed here
+// rather than left to the agent.
+package build
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/checkpoint"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+// Phases are the phases run after the spec is approved, in order
+var Phases = []checkpoint.Phase{checkpoint.PhaseDesign, checkpoint.PhaseDevelop, checkpoint.PhaseQuality, checkpoint.PhaseDeploy}
+
+// Output is something a phase must leave in the project
+type Output struct {
+	Description string
+	Paths       []string // Globs matched against project-relative paths
+	Names       []string // Globs matched against file names anywhere in the project
+}
+
+// Outputs are the expected outputs of each phase
+var Outputs = map[checkpoint.Phase][]Output{
+	checkpoint.PhaseDesign: {
+		{Description: "azd project configuration (azure.yaml)", Paths: []string{"azure.yaml", "azure.yml"}},
+	},
+	checkpoint.PhaseDevelop: {
+		{Description: "application source code", Names: []string{"*.go", "*.py", "*.js", "*.mjs", "*.ts", "*.tsx", "*.jsx", "*.cs", "*.java"}},
+	},
+	checkpoint.PhaseQuality: {
+		{Description: "tests", Names: []string{"*_test.go", "test_*.py", "*_test.py", "*.test.js", "*.test.ts", "*.test.tsx", "*.spec.js", "*.spec.ts", "*Tests.cs", "*Test.java"}},
+	},
+	checkpoint.PhaseDeploy: {
+		{Description: "infrastructure as code (infra/*.bicep or infra/*.tf)", Paths: []string{"infra/*.bicep", "infra/*.tf"}},
+	},
+}
+
+// phaseNotes are extra instructions for a phase's turn
+var phaseNotes = map[checkpoint.Phase]string{
+	checkpoint.PhaseDeploy: "Generate the infrastructure, pipeline, and documentation, and run the preflight checks, but don't run `azd up` or `azd deploy`: the user deploys after reviewing the build.",
+}
+
+// Launcher runs one Copilot turn with a prompt
+type Launcher func(ctx context.Context, phase checkpoint.Phase, prompt string) error
+
+// Options configures a build run
+type Options struct {
+	Spec     string                 // Approved spec content
+	Resume   *checkpoint.Checkpoint // Continue from this checkpoint; nil starts after the spec
+	Launch   Launcher
+	Started  func(phase checkpoint.Phase, n, total int) // Called before each phase's turn
+	Finished func(PhaseResult)                          // Called after each phase, passed or failed
+
+	SessionID func(since time.Time) string // Finds the Copilot session of a turn started at since
+
+	SkipGates   bool                                       // Don't run the build, lint, and test gates
+	GateTimeout time.Duration                              // Per gate command; 0 means DefaultGateTimeout
+	Checked     func(phase checkpoint.Phase, r GateResult) // Called after each gate
+}
+
+// PhaseResult is the outcome of one phase
+type PhaseResult struct {
+	Phase      checkpoint.Phase `json:"phase"`
+	Files      []string         `json:"files,omitempty"` // Created or modified during the phase
+	Gates      []GateResult     `json:"gates,omitempty"`
+	SessionID  string           `json:"sessionId,omitempty"`
+	Checkpoint string           `json:"checkpoint,omitempty"`
+	Err        error            `json:"-"`
+}
+
+// Result is the outcome of a build run
+type Result struct {
+	Completed []checkpoint.Phase     `json:"completed"`
+	Phases    []PhaseResult          `json:"phases"`
+	Recovery  *checkpoint.Checkpoint `json:"recovery,omitempty"` // Saved when a phase failed
+}
+
+// Run executes the remaining build phases. Each phase is one Copilot turn;
+// when it ends, the files it changed are collected, its outputs verified,
+// its quality gates run, and a phase checkpoint saved. A failed turn,
+// missing output, or failed gate saves a recovery checkpoint and stops the
+// build, which Resume can continue.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	start, completed, err := startPhase(opts.Resume)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Completed: completed}
+	if opts.Resume == nil {
+		if _, err := checkpoint.SavePhaseCheckpoint(checkpoint.PhaseSpec, "Spec approved", []string{spec.GetSpecPath()}, completed); err != nil {
+			return nil, fmt.Errorf("failed to save spec checkpoint: %w", err)
+		}
+	}
+
+	for i := start; i < len(Phases); i++ {
+		phase := Phases[i]
+		if opts.Started != nil {
+			opts.Started(phase, i+1, len(Phases))
+		}
+		prompt := PhasePrompt(phase, opts.Spec, completed)
+		if i == start && opts.Resume != nil {
+			prompt = resumePrompt(opts.Resume, phase, opts.Spec)
+		}
+
+		pr := runPhase(ctx, opts, phase, prompt)
+		if pr.Err == nil {
+			var cp *checkpoint.Checkpoint
+			cp, pr.Err = checkpoint.SaveWithOptions(checkpoint.SaveOptions{
+				Phase:           phase,
+				Type:            checkpoint.TypePhase,
+				Trigger:         checkpoint.TriggerPhaseCompleted,
+				SessionID:       pr.SessionID,
+				Description:     fmt.Sprintf("%s phase completed (%d files)", phase, len(pr.Files)),
+				Files:           checkpoint.FileState{Created: pr.Files},
+				CompletedPhases: append(slices.Clone(completed), phase),
+				Context:         checkpoint.Context{SessionID: pr.SessionID},
+			})
+			if cp != nil {
+				pr.Checkpoint = cp.ID
+			}
+		}
+		if pr.Err == nil {
+			pr.Err = spec.AddGeneratedFiles(pr.Files)
+		}
+		result.Phases = append(result.Phases, pr)
+
+		if pr.Err != nil {
+			recovery, err := saveRecovery(phase, pr, prompt, completed)
+			if err != nil {
+				return result, fmt.Errorf("%s phase failed: %w (and the recovery checkpoint could not be saved: %v)", phase, pr.Err, err)
+			}
+			result.Recovery = recovery
+			result.Phases[len(result.Phases)-1].Checkpoint = recovery.ID
+			if opts.Finished != nil {
+				opts.Finished(result.Phases[len(result.Phases)-1])
+			}
+			return result, fmt.Errorf("%s phase failed: %w", phase, pr.Err)
+		}
+		completed = append(completed, phase)
+		result.Completed = completed
+		if opts.Finished != nil {
+			opts.Finished(pr)
+		}
+	}
+	return result, nil
+}
+
+// startPhase returns the index in Phases to start from and the phases
+// already completed
+func startPhase(resume *checkpoint.Checkpoint) (int, []checkpoint.Phase, error) {
+	if resume == nil {
+		return 0, []checkpoint.Phase{checkpoint.PhaseSpec}, nil
+	}
+	if resume.Phase == checkpoint.PhaseDeploy && resume.Type != checkpoint.TypeRecovery {
+		return 0, nil, fmt.Errorf("checkpoint %s is after the last phase; the build is complete", resume.ID)
+	}
+	next := checkpoint.ResumePhase(resume)
+	start := slices.Index(Phases, next)
+	if start < 0 {
+		return 0, nil, fmt.Errorf("checkpoint %s has no build phase to resume (phase %s)", resume.ID, next)
+	}
+	completed := slices.Clone(resume.CompletedPhases)
+	if !slices.Contains(completed, checkpoint.PhaseSpec) {
+		completed = append([]checkpoint.Phase{checkpoint.PhaseSpec}, completed...)
+	}
+	return start, completed, nil
+}
+
+// runPhase runs one turn, verifies its outputs, and runs its gates
+func runPhase(ctx context.Context, opts Options, phase checkpoint.Phase, prompt string) PhaseResult {
+	pr := PhaseResult{Phase: phase}
+	before, err := takeSnapshot(".")
+	if err != nil {
+		pr.Err = err
+		return pr
+	}
+	started := time.Now()
+	launchErr := opts.Launch(ctx, phase, prompt)
+	if opts.SessionID != nil {
+		pr.SessionID = opts.SessionID(started)
+	}
+	after, err := takeSnapshot(".")
+	if err != nil {
+		pr.Err = err
+		return pr
+	}
+	pr.Files = before.changes(after)
+
+	switch {
+	case launchErr != nil:
+		pr.Err = fmt.Errorf("copilot turn failed: %w", launchErr)
+	case ctx.Err() != nil:
+		pr.Err = fmt.Errorf("interrupted: %w", ctx.Err())
+	default:
+		if missing := Verify(phase, after.paths()); len(missing) > 0 {
+			var names []string
+			for _, o := range missing {
+				names = append(names, o.Description)
+			}
+			pr.Err = fmt.Errorf("missing expected outputs: %s", strings.Join(names, "; "))
+		} else if kinds := GateKinds[phase]; len(kinds) > 0 && !opts.SkipGates {
+			pr.Gates, pr.Err = runGates(ctx, opts, phase, kinds)
+		}
+	}
+	return pr
+}
+
+// runGates runs the gates of the given kinds that the project defines
+func runGates(ctx context.Context, opts Options, phase checkpoint.Phase, kinds []string) ([]GateResult, error) {
+	gates, err := DetectGates(".", kinds)
+	if err != nil {
+		return nil, err
+	}
+	return RunGates(ctx, ".", gates, opts.GateTimeout, func(r GateResult) {
+		if opts.Checked != nil {
+			opts.Checked(phase, r)
+		}
+	})
+}
+
+// Verify returns the outputs of a phase that none of the project-relative
+// paths satisfy
+func Verify(phase checkpoint.Phase, paths []string) []Output {
+	var missing []Output
+	for _, o := range Outputs[phase] {
+		if !slices.ContainsFunc(paths, o.matches) {
+			missing = append(missing, o)
+		}
+	}
+	return missing
+}
+
+func (o Output) matches(rel string) bool {
+	for _, p := range o.Paths {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+	}
+	name := rel[strings.LastIndex(rel, "/")+1:]
+	for _, p := range o.Names {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// saveRecovery records a failed phase. Retries counts earlier consecutive
+// failures of the same phase. Gate failures keep the commands' own output
+// as the error, so the resume prompt shows what actually broke.
+func saveRecovery(phase checkpoint.Phase, pr PhaseResult, prompt string, completed []checkpoint.Phase) (*checkpoint.Checkpoint, error) {
+	retries := 0
+	if latest, _ := checkpoint.LatestBuild(); latest != nil && latest.Type == checkpoint.TypeRecovery && latest.Phase == phase {
+		for _, f := range latest.Tasks.FailedTasks {
+			if f.Task == string(phase) {
+				retries = f.Retries + 1
+			}
+		}
+	}
+
+	var pending []string
+	for _, p := range Phases[slices.Index(Phases, phase):] {
+		pending = append(pending, string(p))
+	}
+	return checkpoint.SaveWithOptions(checkpoint.SaveOptions{
+		Phase:           phase,
+		Type:            checkpoint.TypeRecovery,
+		SessionID:       pr.SessionID,
+		Trigger:         checkpoint.TriggerErrorRecovery,
+		Description:     fmt.Sprintf("%s phase failed: %v", phase, pr.Err),
+		Files:           checkpoint.FileState{Created: pr.Files},
+		CompletedPhases: completed,
+		Tasks: checkpoint.TaskState{
+			PendingTasks: pending,
+			FailedTasks:  []checkpoint.TaskFailure{{Task: string(phase), Error: pr.Err.Error(), Retries: retries, Timestamp: time.Now()}},
+		},
+		Context: checkpoint.Context{ErrorMessage: ErrorReport(pr.Err), LastPrompt: prompt, SessionID: pr.SessionID},
+	})
+}
+
+// PhasePrompt is the prompt for one phase's turn
+func PhasePrompt(phase checkpoint.Phase, specContent string, completed []checkpoint.Phase) string {
+	var sb strings.Builder
+	n := slices.Index(Phases, phase) + 1
+	fmt.Fprintf(&sb, "# Build Phase %d/%d: %s\n\n", n, len(Phases), phase)
+	fmt.Fprintf(&sb, "The user approved the spec below. The build runs one phase per turn, and this turn covers only the **%s** phase. Don't start later phases; they run next.\n\n", phase)
+	sb.WriteString("## Approved Spec\n\n")
+	sb.WriteString(strings.TrimSpace(specContent))
+	sb.WriteString("\n\n## Completed Phases\n\n")
+	for _, p := range completed {
+		fmt.Fprintf(&sb, "- [x] %s\n", p)
+	}
+	fmt.Fprintf(&sb, "- [ ] %s ← **this turn**\n\n", phase)
+	sb.WriteString(checkpoint.PhaseGuidance(phase))
+	sb.WriteString(phaseContract(phase))
+	return sb.String()
+}
+
+// resumePrompt continues a build from a checkpoint, carrying the previous
+// error for recovery checkpoints
+func resumePrompt(cp *checkpoint.Checkpoint, phase checkpoint.Phase, specContent string) string {
+	var sb strings.Builder
+	sb.WriteString(checkpoint.GenerateBuildResumePrompt(cp))
+	sb.WriteString("\n## Approved Spec\n\n")
+	sb.WriteString(strings.TrimSpace(specContent))
+	sb.WriteString("\n\n")
+	fmt.Fprintf(&sb, "This turn covers only the **%s** phase; later phases run as separate turns.\n\n", phase)
+	sb.WriteString(phaseContract(phase))
+	return sb.String()
+}
+
+// phaseContract lists what the build checks when the turn ends
+func phaseContract(phase checkpoint.Phase) string {
+	var sb strings.Builder
+	if note := phaseNotes[phase]; note != "" {
+		fmt.Fprintf(&sb, "\n%s\n", note)
+	}
+	sb.WriteString("\n## Expected Outputs\n\n")
+	sb.WriteString("When this turn ends, azd copilot checks that these exist and saves the phase checkpoint itself:\n\n")
+	for _, o := range Outputs[phase] {
+		fmt.Fprintf(&sb, "- %s\n", o.Description)
+	}
+	if kinds := GateKinds[phase]; len(kinds) > 0 {
+		fmt.Fprintf(&sb, "\nIt then runs the project's %s commands (package.json scripts, go build/vet/test, pytest, dotnet, mvn). The phase fails unless they pass, so run them yourself before finishing.\n", strings.Join(kinds, ", "))
+	}
+	return sb.String()
+}
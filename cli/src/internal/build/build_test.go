@@ -0,0 +1,209 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package build
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/checkpoint"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+// phaseFiles are what a well-behaved agent writes in each phase
+var phaseFiles = map[checkpoint.Phase][]string{
+	checkpoint.PhaseDesign:  {"azure.yaml"},
+	checkpoint.PhaseDevelop: {"src/api/main.py", "src/api/models.py"},
+	checkpoint.PhaseQuality: {"src/api/test_main.py"},
+	checkpoint.PhaseDeploy:  {"infra/main.bicep"},
+}
+
+// fakeLauncher writes each phase's files, skipping the phases in skip, and
+// records the prompts it was given
+func fakeLauncher(t *testing.T, prompts map[checkpoint.Phase]string, skip ...checkpoint.Phase) Launcher {
+	return func(ctx context.Context, phase checkpoint.Phase, prompt string) error {
+		prompts[phase] = prompt
+		if slices.Contains(skip, phase) {
+			return nil
+		}
+		for _, f := range phaseFiles[phase] {
+			if err := os.MkdirAll(filepath.Dir(f), 0o750); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(f, []byte(string(phase)), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := spec.Write("# Todo API\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	prompts := map[checkpoint.Phase]string{}
+	var started []checkpoint.Phase
+	result, err := Run(context.Background(), Options{
+		Spec:    "# Todo API\n",
+		Launch:  fakeLauncher(t, prompts),
+		Started: func(phase checkpoint.Phase, n, total int) { started = append(started, phase) },
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !slices.Equal(started, Phases) {
+		t.Errorf("started = %v, want %v", started, Phases)
+	}
+	want := append([]checkpoint.Phase{checkpoint.PhaseSpec}, Phases...)
+	if !slices.Equal(result.Completed, want) {
+		t.Errorf("Completed = %v, want %v", result.Completed, want)
+	}
+	if files := result.Phases[1].Files; !slices.Equal(files, phaseFiles[checkpoint.PhaseDevelop]) {
+		t.Errorf("develop files = %v, want %v", files, phaseFiles[checkpoint.PhaseDevelop])
+	}
+	if p := prompts[checkpoint.PhaseQuality]; !strings.Contains(p, "Build Phase 3/4: quality") || !strings.Contains(p, "- [x] develop") || !strings.Contains(p, "# Todo API") {
+		t.Errorf("quality prompt missing header, progress, or spec:\n%s", p)
+	}
+
+	latest, err := checkpoint.Latest()
+	if err != nil || latest == nil {
+		t.Fatalf("Latest() = %v, %v", latest, err)
+	}
+	if latest.Phase != checkpoint.PhaseDeploy || !slices.Equal(latest.CompletedPhases, want) {
+		t.Errorf("latest checkpoint = %s %v, want deploy with every phase completed", latest.Phase, latest.CompletedPhases)
+	}
+	if cp, _ := checkpoint.DetectInterrupted(); cp != nil {
+		t.Errorf("DetectInterrupted() = %s after a complete build", cp.ID)
+	}
+
+	m, err := spec.LoadMetadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(m.GeneratedFiles, "infra/main.bicep") || !slices.Contains(m.GeneratedFiles, "azure.yaml") {
+		t.Errorf("GeneratedFiles = %v", m.GeneratedFiles)
+	}
+}
+
+func TestRun_MissingOutputsAndResume(t *testing.T) {
+	t.Chdir(t.TempDir())
+	prompts := map[checkpoint.Phase]string{}
+
+	// The develop turn writes no code
+	result, err := Run(context.Background(), Options{Spec: "# Todo API\n", Launch: fakeLauncher(t, prompts, checkpoint.PhaseDevelop)})
+	if err == nil || !strings.Contains(err.Error(), "application source code") {
+		t.Fatalf("Run() error = %v, want missing source code", err)
+	}
+	if _, ran := prompts[checkpoint.PhaseQuality]; ran {
+		t.Error("quality phase ran after develop failed")
+	}
+	rec := result.Recovery
+	if rec == nil || rec.Type != checkpoint.TypeRecovery || rec.Phase != checkpoint.PhaseDevelop {
+		t.Fatalf("Recovery = %+v, want a develop recovery checkpoint", rec)
+	}
+	if !slices.Equal(rec.CompletedPhases, []checkpoint.Phase{checkpoint.PhaseSpec, checkpoint.PhaseDesign}) {
+		t.Errorf("recovery CompletedPhases = %v", rec.CompletedPhases)
+	}
+	if len(rec.Tasks.FailedTasks) != 1 || rec.Tasks.FailedTasks[0].Retries != 0 || !strings.Contains(rec.Context.ErrorMessage, "missing expected outputs") {
+		t.Errorf("recovery tasks = %+v, context = %+v", rec.Tasks, rec.Context)
+	}
+
+	// A resumed turn that fails again counts a retry, even with a snapshot
+	// saved in between
+	if _, err := checkpoint.SaveBeforeDestructive([]string{"azd down"}); err != nil {
+		t.Fatal(err)
+	}
+	interrupted, err := checkpoint.DetectInterrupted()
+	if err != nil || interrupted == nil {
+		t.Fatalf("DetectInterrupted() = %v, %v", interrupted, err)
+	}
+	result, err = Run(context.Background(), Options{Spec: "# Todo API\n", Resume: interrupted, Launch: fakeLauncher(t, prompts, checkpoint.PhaseDevelop)})
+	if err == nil || result.Recovery == nil || result.Recovery.Tasks.FailedTasks[0].Retries != 1 {
+		t.Fatalf("resumed Run() = %+v, %v; want a second failure with 1 retry", result, err)
+	}
+	if p := prompts[checkpoint.PhaseDevelop]; !strings.Contains(p, "recovery checkpoint") || !strings.Contains(p, "missing expected outputs") {
+		t.Errorf("resume prompt does not carry the previous error:\n%s", p)
+	}
+
+	// Resuming with a working turn finishes the build
+	interrupted, _ = checkpoint.DetectInterrupted()
+	result, err = Run(context.Background(), Options{Spec: "# Todo API\n", Resume: interrupted, Launch: fakeLauncher(t, prompts)})
+	if err != nil {
+		t.Fatalf("resumed Run() error = %v", err)
+	}
+	if len(result.Phases) != 3 || result.Phases[0].Phase != checkpoint.PhaseDevelop {
+		t.Errorf("resumed phases = %+v, want develop through deploy", result.Phases)
+	}
+	if !slices.Equal(result.Completed, append([]checkpoint.Phase{checkpoint.PhaseSpec}, Phases...)) {
+		t.Errorf("Completed = %v", result.Completed)
+	}
+}
+
+func TestRun_ResumeAfterPhase(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cp, err := checkpoint.SavePhaseCheckpoint(checkpoint.PhaseQuality, "quality done", nil, []checkpoint.Phase{checkpoint.PhaseSpec, checkpoint.PhaseDesign, checkpoint.PhaseDevelop, checkpoint.PhaseQuality})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prompts := map[checkpoint.Phase]string{}
+	if _, err := Run(context.Background(), Options{Spec: "# Todo API\n", Resume: cp, Launch: fakeLauncher(t, prompts)}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	p := prompts[checkpoint.PhaseDeploy]
+	if strings.Contains(p, "Save a checkpoint") || !strings.Contains(p, "saves the phase checkpoint itself") {
+		t.Errorf("resume prompt leaves checkpoints to the agent:\n%s", p)
+	}
+}
+
+func TestRun_LaunchError(t *testing.T) {
+	t.Chdir(t.TempDir())
+	result, err := Run(context.Background(), Options{
+		Spec:   "# Todo API\n",
+		Launch: func(context.Context, checkpoint.Phase, string) error { return errors.New("exit status 1") },
+	})
+	if err == nil || !strings.Contains(err.Error(), "exit status 1") {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Recovery == nil || result.Recovery.Phase != checkpoint.PhaseDesign {
+		t.Errorf("Recovery = %+v, want a design recovery checkpoint", result.Recovery)
+	}
+}
+
+func TestRun_ResumeComplete(t *testing.T) {
+	cp := &checkpoint.Checkpoint{ID: "phase-deploy-1", Type: checkpoint.TypePhase, Phase: checkpoint.PhaseDeploy}
+	if _, err := Run(context.Background(), Options{Resume: cp}); err == nil {
+		t.Error("Run() resumed a complete build")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		phase   checkpoint.Phase
+		paths   []string
+		missing int
+	}{
+		{checkpoint.PhaseDesign, []string{"azure.yaml"}, 0},
+		{checkpoint.PhaseDesign, []string{"docs/azure.yaml"}, 1},
+		{checkpoint.PhaseDevelop, []string{"src/web/App.tsx"}, 0},
+		{checkpoint.PhaseDevelop, []string{"README.md"}, 1},
+		{checkpoint.PhaseQuality, []string{"internal/api/handler_test.go"}, 0},
+		{checkpoint.PhaseQuality, []string{"internal/api/handler.go"}, 1},
+		{checkpoint.PhaseDeploy, []string{"infra/main.bicep"}, 0},
+		{checkpoint.PhaseDeploy, []string{"infra/modules/app.bicep"}, 1},
+	}
+	for _, tt := range tests {
+		if got := Verify(tt.phase, tt.paths); len(got) != tt.missing {
+			t.Errorf("Verify(%s, %v) = %v, want %d missing", tt.phase, tt.paths, got, tt.missing)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package build
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/checkpoint"
+)
+
+// Gate kinds
+const (
+	GateBuild = "build"
+	GateTest  = "test"
+	GateLint  = "lint"
+)
+
+// DefaultGateTimeout bounds each gate command
+const DefaultGateTimeout = 10 * time.Minute
+
+// GateKinds are the gates run after a phase before the build moves on
+var GateKinds = map[checkpoint.Phase][]string{
+	checkpoint.PhaseDevelop: {GateBuild},
+	checkpoint.PhaseQuality: {GateBuild, GateLint, GateTest},
+}
+
+// maxGateDepth limits how deep DetectGates looks for project manifests
+const maxGateDepth = 3
+
+// maxReportLines is how much of a failed gate's output a report keeps
+const maxReportLines = 80
+
+// Gate is a command that checks the project
+type Gate struct {
+	Kind    string   `json:"kind"`
+	Command []string `json:"command"`
+	Dir     string   `json:"dir"`    // Project-relative, slash-separated; "." is the root
+	Source  string   `json:"source"` // Manifest the gate was detected from
+}
+
+// String returns the command line as a user would type it
+func (g Gate) String() string {
+	cmd := strings.Join(g.Command, " ")
+	if g.Dir != "." {
+		return fmt.Sprintf("(cd %s && %s)", g.Dir, cmd)
+	}
+	return cmd
+}
+
+// Diagnostic is a file position reported by a failed gate
+type Diagnostic struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// GateResult is the outcome of running one gate
+type GateResult struct {
+	Gate        Gate          `json:"gate"`
+	Passed      bool          `json:"passed"`
+	Skipped     string        `json:"skipped,omitempty"` // Why the gate did not run, e.g. the tool is not installed
+	ExitCode    int           `json:"exitCode"`
+	TimedOut    bool          `json:"timedOut,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	Output      string        `json:"output,omitempty"` // Last lines of combined output, for failures
+	Diagnostics []Diagnostic  `json:"diagnostics,omitempty"`
+}
+
+// Failed reports whether the gate ran and did not pass
+func (r GateResult) Failed() bool {
+	return !r.Passed && r.Skipped == ""
+}
+
+// GateError is returned when one or more gates fail
+type GateError struct {
+	Results []GateResult
+}
+
+func (e *GateError) Error() string {
+	var failed []string
+	for _, r := range e.Results {
+		if r.Failed() {
+			failed = append(failed, fmt.Sprintf("%s (%s)", r.Gate, exitLabel(r)))
+		}
+	}
+	return fmt.Sprintf("quality gates failed: %s", strings.Join(failed, ", "))
+}
+
+// Report is the failing commands with their real output, for the recovery
+// checkpoint and the resume prompt
+func (e *GateError) Report() string {
+	var sb strings.Builder
+	for _, r := range e.Results {
+		if !r.Failed() {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "$ %s\n%s\n", r.Gate, exitLabel(r))
+		for _, d := range r.Diagnostics {
+			fmt.Fprintf(&sb, "  %s:%d: %s\n", d.File, d.Line, d.Message)
+		}
+		if r.Output != "" {
+			sb.WriteString(r.Output)
+			if !strings.HasSuffix(r.Output, "\n") {
+				sb.WriteString("\n")
+			}
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func exitLabel(r GateResult) string {
+	if r.TimedOut {
+		return fmt.Sprintf("timed out after %s", r.Duration.Round(time.Second))
+	}
+	return fmt.Sprintf("exit %d", r.ExitCode)
+}
+
+// ErrorReport returns the text a recovery checkpoint records for err: the
+// gate output for gate failures, otherwise the error message
+func ErrorReport(err error) string {
+	var gateErr *GateError
+	if errors.As(err, &gateErr) {
+		return gateErr.Report()
+	}
+	return err.Error()
+}
+
+// DetectGates finds the build, test, and lint commands of the project at
+// root from its manifests: package.json scripts, go.mod, pyproject.toml or
+// requirements.txt, *.csproj, and pom.xml. Only the kinds asked for are
+// returned.
+func DetectGates(root string, kinds []string) ([]Gate, error) {
+	var gates []Gate
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable entries are skipped
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if p != root && (skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".") || strings.Count(rel, "/") >= maxGateDepth) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		dir := filepath.ToSlash(filepath.Dir(rel))
+		switch name := d.Name(); {
+		case name == "package.json":
+			gates = append(gates, npmGates(p, dir, rel)...)
+		case name == "go.mod":
+			gates = append(gates,
+				Gate{Kind: GateBuild, Command: []string{"go", "build", "./..."}, Dir: dir, Source: rel},
+				Gate{Kind: GateLint, Command: []string{"go", "vet", "./..."}, Dir: dir, Source: rel},
+				Gate{Kind: GateTest, Command: []string{"go", "test", "./..."}, Dir: dir, Source: rel})
+		case name == "pyproject.toml" || name == "requirements.txt":
+			gates = append(gates, pythonGates(root, p, dir, rel)...)
+		case strings.HasSuffix(name, ".csproj"):
+			gates = append(gates, dotnetGates(p, dir, rel)...)
+		case name == "pom.xml":
+			gates = append(gates,
+				Gate{Kind: GateBuild, Command: []string{"mvn", "-q", "-B", "compile"}, Dir: dir, Source: rel},
+				Gate{Kind: GateTest, Command: []string{"mvn", "-q", "-B", "test"}, Dir: dir, Source: rel})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan project manifests: %w", err)
+	}
+
+	// A Python project with both manifests gets one set of gates
+	seen := map[string]bool{}
+	gates = slices.DeleteFunc(gates, func(g Gate) bool {
+		key := g.Dir + "\x00" + g.String()
+		if seen[key] || !slices.Contains(kinds, g.Kind) {
+			return true
+		}
+		seen[key] = true
+		return false
+	})
+	order := map[string]int{GateBuild: 0, GateLint: 1, GateTest: 2}
+	sort.SliceStable(gates, func(i, j int) bool {
+		if gates[i].Dir != gates[j].Dir {
+			return gates[i].Dir < gates[j].Dir
+		}
+		return order[gates[i].Kind] < order[gates[j].Kind]
+	})
+	return gates, nil
+}
+
+// npmGates runs the build, lint, and test scripts a package.json defines
+func npmGates(path, dir, rel string) []Gate {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: package.json found in the project
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return nil
+	}
+
+	manager := "npm"
+	for _, lock := range []struct{ file, manager string }{{"pnpm-lock.yaml", "pnpm"}, {"yarn.lock", "yarn"}} {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(path), lock.file)); err == nil {
+			manager = lock.manager
+			break
+		}
+	}
+	var gates []Gate
+	for _, kind := range []string{GateBuild, GateLint, GateTest} {
+		script := pkg.Scripts[kind]
+		// npm init's placeholder test script always fails
+		if script == "" || strings.Contains(script, "no test specified") {
+			continue
+		}
+		gates = append(gates, Gate{Kind: kind, Command: []string{manager, "run", kind}, Dir: dir, Source: rel})
+	}
+	return gates
+}
+
+// pythonGates byte-compiles the sources and runs ruff and pytest when the
+// project uses them
+func pythonGates(root, path, dir, rel string) []Gate {
+	data, _ := os.ReadFile(path) //nolint:gosec // G304: Python manifest found in the project
+	manifest := strings.ToLower(string(data))
+	python := pythonCommand(root, dir)
+	gates := []Gate{{Kind: GateBuild, Command: []string{python, "-m", "compileall", "-q", "."}, Dir: dir, Source: rel}}
+	if strings.Contains(manifest, "ruff") {
+		gates = append(gates, Gate{Kind: GateLint, Command: []string{"ruff", "check", "."}, Dir: dir, Source: rel})
+	}
+	if strings.Contains(manifest, "pytest") || hasPythonTests(filepath.Dir(path)) {
+		gates = append(gates, Gate{Kind: GateTest, Command: []string{python, "-m", "pytest", "-q"}, Dir: dir, Source: rel})
+	}
+	return gates
+}
+
+// pythonCommand returns the interpreter for a Python project in dir: the
+// interpreter of a .venv in dir or at the project root, relative to dir,
+// otherwise python3 or python from PATH. Stock Linux and macOS installs
+// have only python3, while Windows installs have python and may have a
+// python3 alias that opens the Store.
+func pythonCommand(root, dir string) string {
+	venvPython := filepath.Join(".venv", "bin", "python")
+	if runtime.GOOS == "windows" {
+		venvPython = filepath.Join(".venv", "Scripts", "python.exe")
+	}
+	for _, venvDir := range []string{dir, "."} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(venvDir), venvPython)); err != nil {
+			continue
+		}
+		if fromDir, err := filepath.Rel(filepath.FromSlash(dir), filepath.Join(filepath.FromSlash(venvDir), venvPython)); err == nil {
+			return filepath.ToSlash(fromDir)
+		}
+	}
+
+	candidates := []string{"python3", "python"}
+	if runtime.GOOS == "windows" {
+		candidates = []string{"python", "python3"}
+	}
+	for _, name := range candidates {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return candidates[0] // Reported as not installed when the gate runs
+}
+
+func hasPythonTests(dir string) bool {
+	for _, pattern := range []string{"test_*.py", "*_test.py", "tests/test_*.py", "tests/*_test.py"} {
+		if matches, _ := filepath.Glob(filepath.Join(dir, pattern)); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dotnetGates builds every project and tests the ones that reference the
+// test SDK
+func dotnetGates(path, dir, rel string) []Gate {
+	name := filepath.Base(path)
+	gates := []Gate{{Kind: GateBuild, Command: []string{"dotnet", "build", name}, Dir: dir, Source: rel}}
+	data, _ := os.ReadFile(path) //nolint:gosec // G304: project file found in the project
+	if bytes.Contains(data, []byte("Microsoft.NET.Test.Sdk")) {
+		gates = append(gates, Gate{Kind: GateTest, Command: []string{"dotnet", "test", name}, Dir: dir, Source: rel})
+	}
+	return gates
+}
+
+// RunGates runs gates in order under root. A gate whose tool is not
+// installed is skipped rather than failed. The result is a *GateError when
+// any gate fails.
+func RunGates(ctx context.Context, root string, gates []Gate, timeout time.Duration, done func(GateResult)) ([]GateResult, error) {
+	if timeout <= 0 {
+		timeout = DefaultGateTimeout
+	}
+	results := make([]GateResult, 0, len(gates))
+	failed := false
+	for _, g := range gates {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		r := runGate(ctx, root, g, timeout)
+		failed = failed || r.Failed()
+		results = append(results, r)
+		if done != nil {
+			done(r)
+		}
+	}
+	if failed {
+		return results, &GateError{Results: results}
+	}
+	return results, nil
+}
+
+func runGate(ctx context.Context, root string, g Gate, timeout time.Duration) GateResult {
+	r := GateResult{Gate: g}
+	dir := filepath.Join(root, filepath.FromSlash(g.Dir))
+	if !commandExists(dir, g.Command[0]) {
+		r.Skipped = fmt.Sprintf("%s not installed", g.Command[0])
+		return r
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, g.Command[0], g.Command[1:]...) //nolint:gosec // G204: commands are built from a fixed set of tools
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CI=true") // Keeps test runners out of watch and interactive modes
+	cmd.WaitDelay = 5 * time.Second           // Don't hang on grandchildren holding the output pipe
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	start := time.Now()
+	err := cmd.Run()
+	r.Duration = time.Since(start)
+	r.TimedOut = errors.Is(ctx.Err(), context.DeadlineExceeded)
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		r.Passed = true
+		return r
+	case errors.As(err, &exitErr):
+		r.ExitCode = exitErr.ExitCode()
+	default:
+		r.ExitCode = -1
+		out.WriteString(err.Error())
+	}
+	r.Output = tailLines(out.String(), maxReportLines)
+	r.Diagnostics = parseDiagnostics(out.String(), g.Dir)
+	return r
+}
+
+// commandExists reports whether a command can run from dir. Paths, such as
+// a virtual environment's interpreter, are relative to dir; names are looked
+// up on PATH.
+func commandExists(dir, name string) bool {
+	if strings.ContainsAny(name, `/\`) {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		return err == nil
+	}
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// tailLines keeps the last n lines of s
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) <= n {
+		return strings.Join(lines, "\n")
+	}
+	return fmt.Sprintf("... (%d lines omitted)\n%s", len(lines)-n, strings.Join(lines[len(lines)-n:], "\n"))
+}
+
+var (
+	// colonDiagnostic matches "file.go:12:5: message" (Go, TypeScript with --pretty false, ruff, gcc)
+	colonDiagnostic = regexp.MustCompile(`^\s*([\w./\\-]+\.\w+):(\d+)(?::\d+)?:?\s+(.+)$`)
+	// parenDiagnostic matches "File.cs(12,5): error CS1002: message" (MSBuild, tsc)
+	parenDiagnostic = regexp.MustCompile(`^\s*([\w./\\-]+\.\w+)\((\d+)(?:,\d+)?\):\s+(.+)$`)
+)
+
+// maxDiagnostics caps the diagnostics kept per gate
+const maxDiagnostics = 20
+
+// parseDiagnostics pulls file positions out of compiler and linter output,
+// making the files relative to the project root
+func parseDiagnostics(output, dir string) []Diagnostic {
+	var diags []Diagnostic
+	seen := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		m := colonDiagnostic.FindStringSubmatch(line)
+		if m == nil {
+			m = parenDiagnostic.FindStringSubmatch(line)
+		}
+		if m == nil || seen[line] {
+			continue
+		}
+		seen[line] = true
+		var n int
+		_, _ = fmt.Sscanf(m[2], "%d", &n)
+		file := filepath.ToSlash(m[1])
+		if dir != "." && !filepath.IsAbs(m[1]) {
+			file = dir + "/" + strings.TrimPrefix(file, "./")
+		}
+		diags = append(diags, Diagnostic{File: file, Line: n, Message: strings.TrimSpace(m[3])})
+		if len(diags) == maxDiagnostics {
+			break
+		}
+	}
+	return diags
+}
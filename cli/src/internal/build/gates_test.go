@@ -0,0 +1,218 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package build
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/checkpoint"
+)
+
+func writeProjectFile(t *testing.T, rel, content string) {
+	t.Helper()
+	path := filepath.FromSlash(rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetectGates(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeProjectFile(t, "go.mod", "module example.com/api\n")
+	writeProjectFile(t, "web/package.json", `{"scripts":{"build":"tsc","lint":"eslint .","test":"vitest run","dev":"vite"}}`)
+	writeProjectFile(t, "web/pnpm-lock.yaml", "")
+	writeProjectFile(t, "worker/package.json", `{"scripts":{"test":"echo \"Error: no test specified\" && exit 1"}}`)
+	writeProjectFile(t, "ml/pyproject.toml", "[project]\nname = \"ml\"\n[tool.ruff]\n")
+	writeProjectFile(t, "ml/requirements.txt", "fastapi\n")
+	writeProjectFile(t, "ml/test_model.py", "")
+	writeProjectFile(t, "ml/"+venvPython(), "")
+	writeProjectFile(t, "Api.Tests/Api.Tests.csproj", `<PackageReference Include="Microsoft.NET.Test.Sdk" />`)
+	writeProjectFile(t, "web/node_modules/dep/package.json", `{"scripts":{"build":"x"}}`)
+
+	gates, err := DetectGates(".", []string{GateBuild, GateLint, GateTest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, g := range gates {
+		got = append(got, g.Kind+": "+g.String())
+	}
+	want := []string{
+		"build: go build ./...",
+		"lint: go vet ./...",
+		"test: go test ./...",
+		"build: (cd Api.Tests && dotnet build Api.Tests.csproj)",
+		"test: (cd Api.Tests && dotnet test Api.Tests.csproj)",
+		"build: (cd ml && " + venvPython() + " -m compileall -q .)",
+		"lint: (cd ml && ruff check .)",
+		"test: (cd ml && " + venvPython() + " -m pytest -q)",
+		"build: (cd web && pnpm run build)",
+		"lint: (cd web && pnpm run lint)",
+		"test: (cd web && pnpm run test)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("DetectGates() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	builds, _ := DetectGates(".", GateKinds[checkpoint.PhaseDevelop])
+	for _, g := range builds {
+		if g.Kind != GateBuild {
+			t.Errorf("develop gates include %s", g)
+		}
+	}
+}
+
+// venvPython is a virtual environment's interpreter relative to its parent
+func venvPython() string {
+	if runtime.GOOS == "windows" {
+		return ".venv/Scripts/python.exe"
+	}
+	return ".venv/bin/python"
+}
+
+func TestPythonCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	// Only python3 on PATH, as on stock Debian, Ubuntu, and macOS
+	bin := t.TempDir()
+	name := "python3"
+	if runtime.GOOS == "windows" {
+		name = "python3.exe"
+	}
+	writeProjectFile(t, filepath.Join(bin, name), "")
+	if err := os.Chmod(filepath.Join(bin, name), 0o700); err != nil { //nolint:gosec // G302: the fake interpreter must be executable
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	if got := pythonCommand(".", "api"); got != "python3" {
+		t.Errorf("pythonCommand() = %q, want python3", got)
+	}
+
+	// A virtual environment at the project root wins
+	writeProjectFile(t, venvPython(), "")
+	if got, want := pythonCommand(".", "src/api"), "../../"+venvPython(); got != want {
+		t.Errorf("pythonCommand(root venv) = %q, want %q", got, want)
+	}
+	if got := pythonCommand(".", "."); got != venvPython() {
+		t.Errorf("pythonCommand(.) = %q, want %q", got, venvPython())
+	}
+	if !commandExists("src/api", "../../"+venvPython()) {
+		t.Error("commandExists() = false for the virtual environment interpreter")
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	output := `# example.com/api
+./main.go:12:5: undefined: handler
+src/App.tsx(3,10): error TS2304: Cannot find name 'useState'.
+main.go:12:5: undefined: handler
+FAIL	example.com/api [build failed]`
+	got := parseDiagnostics(output, "api")
+	want := []Diagnostic{
+		{File: "api/main.go", Line: 12, Message: "undefined: handler"},
+		{File: "api/src/App.tsx", Line: 3, Message: "error TS2304: Cannot find name 'useState'."},
+		{File: "api/main.go", Line: 12, Message: "undefined: handler"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseDiagnostics() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	if got := tailLines("a\nb\nc\n", 5); got != "a\nb\nc" {
+		t.Errorf("tailLines(short) = %q", got)
+	}
+	if got := tailLines("a\nb\nc\nd", 2); got != "... (2 lines omitted)\nc\nd" {
+		t.Errorf("tailLines(long) = %q", got)
+	}
+}
+
+func TestRunGates(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not installed")
+	}
+	t.Chdir(t.TempDir())
+	writeProjectFile(t, "api/go.mod", "module example.com/api\n\ngo 1.21\n")
+	writeProjectFile(t, "api/main.go", "package main\n\nfunc main() {\n\thandler()\n}\n")
+
+	gates := []Gate{
+		{Kind: GateBuild, Command: []string{"go", "build", "./..."}, Dir: "api"},
+		{Kind: GateLint, Command: []string{"no-such-linter-xyz"}, Dir: "api"},
+	}
+	results, err := RunGates(context.Background(), ".", gates, time.Minute, nil)
+	var gateErr *GateError
+	if !errors.As(err, &gateErr) {
+		t.Fatalf("RunGates() error = %v, want a *GateError", err)
+	}
+	if results[0].Passed || results[0].ExitCode == 0 || len(results[0].Diagnostics) == 0 {
+		t.Errorf("build result = %+v, want a failure with diagnostics", results[0])
+	}
+	if results[0].Diagnostics[0].File != "api/main.go" || results[0].Diagnostics[0].Line != 4 {
+		t.Errorf("diagnostic = %+v, want api/main.go:4", results[0].Diagnostics[0])
+	}
+	if results[1].Skipped == "" || results[1].Failed() {
+		t.Errorf("missing tool result = %+v, want skipped", results[1])
+	}
+	report := gateErr.Report()
+	if !strings.Contains(report, "$ (cd api && go build ./...)") || !strings.Contains(report, "undefined: handler") {
+		t.Errorf("Report() =\n%s", report)
+	}
+	if strings.Contains(report, "no-such-linter-xyz") {
+		t.Error("Report() includes a skipped gate")
+	}
+
+	writeProjectFile(t, "api/main.go", "package main\n\nfunc main() {}\n")
+	if _, err := RunGates(context.Background(), ".", gates[:1], time.Minute, nil); err != nil {
+		t.Errorf("RunGates(fixed) error = %v", err)
+	}
+}
+
+func TestRun_GateFailureBlocksBuild(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not installed")
+	}
+	t.Chdir(t.TempDir())
+	prompts := map[checkpoint.Phase]string{}
+	launch := func(ctx context.Context, phase checkpoint.Phase, prompt string) error {
+		prompts[phase] = prompt
+		switch phase {
+		case checkpoint.PhaseDesign:
+			writeProjectFile(t, "azure.yaml", "name: api\n")
+		case checkpoint.PhaseDevelop:
+			writeProjectFile(t, "go.mod", "module example.com/api\n\ngo 1.21\n")
+			writeProjectFile(t, "main.go", "package main\n\nfunc main() {\n\tundefinedCall()\n}\n")
+		}
+		return nil
+	}
+
+	result, err := Run(context.Background(), Options{Spec: "# API\n", Launch: launch})
+	if err == nil || !strings.Contains(err.Error(), "quality gates failed") {
+		t.Fatalf("Run() error = %v, want a gate failure", err)
+	}
+	if _, ran := prompts[checkpoint.PhaseQuality]; ran {
+		t.Error("quality phase ran after the develop gates failed")
+	}
+	rec := result.Recovery
+	if rec == nil || rec.Phase != checkpoint.PhaseDevelop {
+		t.Fatalf("Recovery = %+v, want a develop recovery checkpoint", rec)
+	}
+	if !strings.Contains(rec.Context.ErrorMessage, "$ go build ./...") || !strings.Contains(rec.Context.ErrorMessage, "undefined: undefinedCall") {
+		t.Errorf("recovery ErrorMessage does not hold the compiler output:\n%s", rec.Context.ErrorMessage)
+	}
+	if !strings.Contains(checkpoint.GenerateResumePrompt(rec), "main.go:4") {
+		t.Error("resume prompt does not include the compiler output")
+	}
+}
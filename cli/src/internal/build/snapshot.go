@@ -0,0 +1,86 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package build
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/audit"
+	"github.com/jongio/azd-copilot/cli/src/internal/checkpoint"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+// skipDirs hold dependencies and build output, which phases don't author
+var skipDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, ".venv": true, "venv": true,
+	"__pycache__": true, "bin": true, "obj": true, "dist": true, ".azure": true,
+}
+
+// fileStamp is enough to tell whether a file changed during a turn
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// snapshot maps project-relative, slash-separated paths to their stamps
+type snapshot map[string]fileStamp
+
+// takeSnapshot records the project's files, leaving out skipped directories,
+// checkpoints, project metadata, and the audit log
+func takeSnapshot(root string) (snapshot, error) {
+	checkpoints := filepath.Clean(checkpoint.GetCheckpointDir())
+	auditLog := filepath.Clean(audit.Path())
+	s := snapshot{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable entries are skipped
+		}
+		rel, _ := filepath.Rel(root, p)
+		if d.IsDir() {
+			if p != root && (skipDirs[d.Name()] || rel == checkpoints) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == spec.MetadataFile || rel == auditLog || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		s[filepath.ToSlash(rel)] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan project files: %w", err)
+	}
+	return s, nil
+}
+
+// changes returns the files created or modified since s, sorted
+func (s snapshot) changes(after snapshot) []string {
+	var files []string
+	for rel, stamp := range after {
+		if before, ok := s[rel]; !ok || before != stamp {
+			files = append(files, rel)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// paths returns every file in the snapshot, sorted
+func (s snapshot) paths() []string {
+	files := make([]string, 0, len(s))
+	for rel := range s {
+		files = append(files, rel)
+	}
+	sort.Strings(files)
+	return files
+}
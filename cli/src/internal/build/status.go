@@ -0,0 +1,170 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package build
+
+import (
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/checkpoint"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+// Phase states in a status timeline
+const (
+	StateDone    = "done"
+	StateFailed  = "failed"
+	StateCurrent = "current" // Next to run
+	StatePending = "pending"
+)
+
+// PhaseStatus is one phase in the build timeline
+type PhaseStatus struct {
+	Phase       checkpoint.Phase         `json:"phase"`
+	State       string                   `json:"state"`
+	Checkpoint  string                   `json:"checkpoint,omitempty"` // Newest phase checkpoint
+	CompletedAt *time.Time               `json:"completedAt,omitempty"`
+	Files       int                      `json:"files,omitempty"`
+	Failures    []checkpoint.TaskFailure `json:"failures,omitempty"` // Failed attempts, oldest first
+}
+
+// Drift is how the workspace changed since the checkpoints recorded it
+type Drift struct {
+	SpecChanged bool     `json:"specChanged"`         // The spec differs from the one the latest checkpoint saw
+	Modified    []string `json:"modified,omitempty"`  // Checkpointed files whose content changed
+	Deleted     []string `json:"deleted,omitempty"`   // Checkpointed files that no longer exist
+	Untracked   []string `json:"untracked,omitempty"` // Files written after the latest checkpoint that none record
+}
+
+// Drifted reports whether anything changed
+func (d Drift) Drifted() bool {
+	return d.SpecChanged || len(d.Modified)+len(d.Deleted)+len(d.Untracked) > 0
+}
+
+// Status is where a build stands
+type Status struct {
+	Spec           *spec.Info             `json:"spec"`
+	Phases         []PhaseStatus          `json:"phases"`
+	Current        checkpoint.Phase       `json:"current,omitempty"` // Phase the build continues with; empty when complete or not started
+	Complete       bool                   `json:"complete"`
+	Latest         *checkpoint.Checkpoint `json:"-"`
+	LastCheckpoint string                 `json:"lastCheckpoint,omitempty"` // Newest phase or recovery checkpoint
+	UpdatedAt      *time.Time             `json:"updatedAt,omitempty"`      // When it was saved
+	SessionID      string                 `json:"sessionId,omitempty"`      // Newest Copilot session recorded by a checkpoint
+	GeneratedFiles []string               `json:"generatedFiles"`
+	Drift          *Drift                 `json:"drift,omitempty"` // Nil before the first checkpoint
+}
+
+// GetStatus combines the spec, the checkpoint timeline, generated files, and
+// workspace drift into the status of the project's build
+func GetStatus() (*Status, error) {
+	info, err := spec.Stat()
+	if err != nil {
+		return nil, err
+	}
+	checkpoints, err := checkpoint.List() // Newest first
+	if err != nil {
+		return nil, err
+	}
+	// Snapshots, such as those saved before destructive commands, are not
+	// part of the build timeline
+	checkpoints = slices.DeleteFunc(checkpoints, func(cp checkpoint.Checkpoint) bool { return !cp.IsBuild() })
+	m, err := spec.LoadMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Spec: info, GeneratedFiles: m.GeneratedFiles}
+	if status.GeneratedFiles == nil {
+		status.GeneratedFiles = []string{}
+	}
+	for _, cp := range checkpoints {
+		if id := sessionOf(cp); id != "" {
+			status.SessionID = id
+			break
+		}
+	}
+
+	var completed []checkpoint.Phase
+	if len(checkpoints) > 0 {
+		latest := checkpoints[0]
+		status.Latest, status.LastCheckpoint, status.UpdatedAt = &latest, latest.ID, &latest.CreatedAt
+		completed = latest.CompletedPhases
+		status.Complete = latest.Phase == checkpoint.PhaseDeploy && latest.Type != checkpoint.TypeRecovery
+		if !status.Complete {
+			status.Current = checkpoint.ResumePhase(&latest)
+		}
+	}
+
+	for _, phase := range append([]checkpoint.Phase{checkpoint.PhaseSpec}, Phases...) {
+		ps := PhaseStatus{Phase: phase, State: StatePending}
+		for i := len(checkpoints) - 1; i >= 0; i-- { // Oldest first
+			cp := checkpoints[i]
+			if cp.Phase != phase {
+				continue
+			}
+			switch cp.Type {
+			case checkpoint.TypePhase:
+				at := cp.CreatedAt
+				ps.Checkpoint, ps.CompletedAt, ps.Files = cp.ID, &at, len(cp.Files.Created)
+			case checkpoint.TypeRecovery:
+				ps.Failures = append(ps.Failures, cp.Tasks.FailedTasks...)
+			}
+		}
+		switch {
+		case slices.Contains(completed, phase) || status.Complete:
+			ps.State = StateDone
+		case status.Latest != nil && status.Latest.Type == checkpoint.TypeRecovery && status.Latest.Phase == phase:
+			ps.State = StateFailed
+		case phase == status.Current:
+			ps.State = StateCurrent
+		}
+		status.Phases = append(status.Phases, ps)
+	}
+
+	if status.Latest != nil {
+		drift, err := detectDrift(status.Latest, info)
+		if err != nil {
+			return nil, err
+		}
+		status.Drift = drift
+	}
+	return status, nil
+}
+
+func sessionOf(cp checkpoint.Checkpoint) string {
+	if cp.SessionID != "" {
+		return cp.SessionID
+	}
+	return cp.Context.SessionID
+}
+
+// detectDrift compares the workspace with what the checkpoints recorded
+func detectDrift(latest *checkpoint.Checkpoint, info *spec.Info) (*Drift, error) {
+	drift := &Drift{SpecChanged: latest.Context.SpecHash != "" && latest.Context.SpecHash != info.Hash}
+	var err error
+	if drift.Modified, drift.Deleted, err = checkpoint.ChangedFiles(); err != nil {
+		return nil, err
+	}
+	// The spec checkpoint records the spec; SpecChanged already covers it
+	isSpec := func(path string) bool { return filepath.Clean(path) == filepath.Clean(info.Path) }
+	drift.Modified = slices.DeleteFunc(drift.Modified, isSpec)
+	drift.Deleted = slices.DeleteFunc(drift.Deleted, isSpec)
+
+	files, err := takeSnapshot(".")
+	if err != nil {
+		return nil, err
+	}
+	tracked, err := checkpoint.GetProjectFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range files.paths() {
+		if files[rel].modTime.After(latest.CreatedAt) && !slices.Contains(tracked, rel) && !isSpec(rel) {
+			drift.Untracked = append(drift.Untracked, rel)
+		}
+	}
+	return drift, nil
+}
@@ -0,0 +1,156 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package build
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/checkpoint"
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+func TestGetStatus_NotStarted(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	status, err := GetStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Spec.Exists || status.LastCheckpoint != "" || status.Drift != nil || status.Complete {
+		t.Errorf("status = %+v, want nothing started", status)
+	}
+	for _, p := range status.Phases {
+		if p.State != StatePending {
+			t.Errorf("%s state = %s, want pending", p.Phase, p.State)
+		}
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := spec.Write("# Todo API\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := spec.Approve("# Todo API\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// develop fails twice, so the build stops there
+	prompts := map[checkpoint.Phase]string{}
+	opts := Options{
+		Spec:      "# Todo API\n",
+		Launch:    fakeLauncher(t, prompts, checkpoint.PhaseDevelop),
+		SessionID: func(time.Time) string { return "session-1" },
+	}
+	if _, err := Run(context.Background(), opts); err == nil {
+		t.Fatal("Run() error = nil, want a develop failure")
+	}
+	opts.Resume, _ = checkpoint.DetectInterrupted()
+	opts.SessionID = func(time.Time) string { return "session-2" }
+	if _, err := Run(context.Background(), opts); err == nil {
+		t.Fatal("resumed Run() error = nil, want a develop failure")
+	}
+
+	status, err := GetStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Spec.Approved || status.Complete || status.Current != checkpoint.PhaseDevelop || status.SessionID != "session-2" {
+		t.Errorf("status = approved %v, complete %v, current %s, session %s", status.Spec.Approved, status.Complete, status.Current, status.SessionID)
+	}
+	var states []string
+	for _, p := range status.Phases {
+		states = append(states, p.State)
+	}
+	if want := []string{StateDone, StateDone, StateFailed, StatePending, StatePending}; !slices.Equal(states, want) {
+		t.Errorf("states = %v, want %v", states, want)
+	}
+	develop := status.Phases[2]
+	if len(develop.Failures) != 2 || develop.Failures[1].Retries != 1 {
+		t.Errorf("develop failures = %+v, want two attempts with the last at retry 1", develop.Failures)
+	}
+	if status.Phases[1].CompletedAt == nil || status.Phases[1].Files != 1 {
+		t.Errorf("design = %+v, want completed with 1 file", status.Phases[1])
+	}
+	if !slices.Equal(status.GeneratedFiles, []string{"azure.yaml"}) {
+		t.Errorf("GeneratedFiles = %v", status.GeneratedFiles)
+	}
+	if status.Drift == nil || status.Drift.Drifted() {
+		t.Errorf("Drift = %+v, want none", status.Drift)
+	}
+
+	// Edit a checkpointed file, add one, and change the spec
+	time.Sleep(10 * time.Millisecond)
+	writeProjectFile(t, "azure.yaml", "name: edited\n")
+	writeProjectFile(t, "notes.md", "todo\n")
+	if err := spec.Write("# Todo API v2\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err = GetStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := status.Drift
+	if d == nil || !d.SpecChanged || !slices.Equal(d.Modified, []string{"azure.yaml"}) || !slices.Equal(d.Untracked, []string{"notes.md"}) {
+		t.Errorf("Drift = %+v, want the spec, azure.yaml, and notes.md", d)
+	}
+	if status.Spec.Approved {
+		t.Error("Spec.Approved = true after the spec changed")
+	}
+}
+
+func TestGetStatus_IgnoresSnapshots(t *testing.T) {
+	t.Chdir(t.TempDir())
+	prompts := map[checkpoint.Phase]string{}
+	if _, err := Run(context.Background(), Options{Spec: "# Todo API\n", Launch: fakeLauncher(t, prompts, checkpoint.PhaseDevelop)}); err == nil {
+		t.Fatal("Run() error = nil, want a develop failure")
+	}
+	// A plain session auto-approving 'azd down' saves a deploy snapshot
+	if _, err := checkpoint.SaveBeforeDestructive([]string{"azd down"}); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := GetStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Complete || status.Current != checkpoint.PhaseDevelop || status.Phases[2].State != StateFailed {
+		t.Errorf("status = complete %v, current %q, develop %s; want develop failed", status.Complete, status.Current, status.Phases[2].State)
+	}
+	if status.Phases[4].State != StatePending {
+		t.Errorf("deploy state = %s, want pending", status.Phases[4].State)
+	}
+}
+
+func TestGetStatus_Complete(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if _, err := Run(context.Background(), Options{Spec: "# Todo API\n", Launch: fakeLauncher(t, map[checkpoint.Phase]string{})}); err != nil {
+		t.Fatal(err)
+	}
+	writeProjectFile(t, "src/api/models.py", "edited\n")
+	if err := os.Remove("infra/main.bicep"); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := GetStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Complete || status.Current != "" {
+		t.Errorf("Complete = %v, Current = %q; want a complete build", status.Complete, status.Current)
+	}
+	for _, p := range status.Phases {
+		if p.State != StateDone {
+			t.Errorf("%s state = %s, want done", p.Phase, p.State)
+		}
+	}
+	if d := status.Drift; !slices.Equal(d.Modified, []string{"src/api/models.py"}) || !slices.Equal(d.Deleted, []string{"infra/main.bicep"}) {
+		t.Errorf("Drift = %+v", d)
+	}
+}
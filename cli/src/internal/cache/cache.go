@@ -7,15 +7,14 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
-	"time"
 
 	corecache "github.com/jongio/azd-core/cache"
 )
 
-const (
-	cacheKey      = "copilot-setup-cache"
-	cacheDuration = 24 * time.Hour
-)
+// cacheKey holds the setup results. The entry does not expire: setup runs
+// again when the extension version, embedded assets, or Copilot CLI
+// executable change, or with --refresh.
+const cacheKey = "copilot-setup-cache"
 
 // SetupCache stores the results of one-time setup checks
 type SetupCache struct {
@@ -23,6 +22,20 @@ type SetupCache struct {
 	SkillsInstalled   bool `json:"skillsInstalled"`
 	MCPConfigured     bool `json:"mcpConfigured"`
 	ExtensionsChecked bool `json:"extensionsChecked"`
+
+	// Version and AssetsHash identify the extension build that ran setup;
+	// a mismatch means setup must run again
+	Version    string `json:"version,omitempty"`
+	AssetsHash string `json:"assetsHash,omitempty"`
+	MCPOffline bool   `json:"mcpOffline,omitempty"`
+
+	// CLIPath, CLIStamp, and CLIVersion record the last detected Copilot CLI
+	// so the version is only probed again when the executable changes, either
+	// moving or being upgraded in place
+	CLIPath    string `json:"cliPath,omitempty"`
+	CLIStamp   string `json:"cliStamp,omitempty"` // Size and modification time of the executable
+	CLIVersion string `json:"cliVersion,omitempty"`
+	CLIIsNode  bool   `json:"cliIsNode,omitempty"`
 }
 
 var (
@@ -46,7 +59,6 @@ func getManager() (*corecache.Manager, error) {
 	}
 	manager = corecache.NewManager(corecache.Options{
 		Dir:     filepath.Join(home, ".azd"),
-		TTL:     cacheDuration,
 		Version: "1",
 	})
 	return manager, nil
@@ -94,3 +106,8 @@ func (c *SetupCache) NeedsSetup() bool {
 	}
 	return !c.AgentsInstalled || !c.SkillsInstalled || !c.MCPConfigured || !c.ExtensionsChecked
 }
+
+// Current returns true if setup completed for the given extension version and assets
+func (c *SetupCache) Current(version, assetsHash string) bool {
+	return !c.NeedsSetup() && c.Version == version && c.AssetsHash == assetsHash
+}
@@ -9,12 +9,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/jongio/azd-copilot/cli/src/internal/audit"
+	"github.com/jongio/azd-copilot/cli/src/internal/redact"
 	"github.com/jongio/azd-copilot/cli/src/internal/spec"
 	"github.com/jongio/azd-core/fileutil"
 )
@@ -180,6 +184,27 @@ func Latest() (*Checkpoint, error) {
 	return &checkpoints[0], nil
 }
 
+// LatestBuild returns the most recent checkpoint saved by a build phase,
+// ignoring task checkpoints and snapshots such as SaveBeforeDestructive's
+func LatestBuild() (*Checkpoint, error) {
+	checkpoints, err := List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range checkpoints {
+		if checkpoints[i].IsBuild() {
+			return &checkpoints[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// IsBuild reports whether a build saved the checkpoint when a phase
+// completed or failed
+func (c *Checkpoint) IsBuild() bool {
+	return c.Type == TypePhase || c.Type == TypeRecovery
+}
+
 // LatestForPhase returns the most recent checkpoint for a specific phase
 func LatestForPhase(phase Phase) (*Checkpoint, error) {
 	checkpoints, err := List()
@@ -243,6 +268,11 @@ func SaveWithOptions(opts SaveOptions) (*Checkpoint, error) {
 		opts.Context.SpecHash = getSpecHash()
 	}
 
+	// Checkpoints are written to the project, so never persist secrets
+	r := redact.LoadForStorage()
+	opts.Description = r.Text(opts.Description)
+	opts.Context = redactContext(r, opts.Context)
+
 	checkpoint := Checkpoint{
 		ID:              id,
 		ProjectID:       opts.ProjectID,
@@ -286,6 +316,14 @@ func SaveWithOptions(opts SaveOptions) (*Checkpoint, error) {
 		fmt.Fprintf(os.Stderr, "warning: failed to update latest.json: %v\n", writeErr)
 	}
 
+	audit.RecordBestEffort(audit.Event{
+		Kind:    audit.KindCheckpoint,
+		Name:    checkpoint.ID,
+		Args:    map[string]interface{}{"action": "create", "type": checkpoint.Type, "trigger": checkpoint.Trigger, "phase": checkpoint.Phase},
+		Outcome: audit.OutcomeOK,
+		Detail:  checkpoint.Description,
+	})
+
 	return &checkpoint, nil
 }
 
@@ -339,6 +377,41 @@ func SaveBeforeDeployment(files []string) (*Checkpoint, error) {
 	})
 }
 
+// SaveBeforeDestructive creates a snapshot checkpoint of the project files
+// before destructive commands are allowed to run without confirmation. When
+// neither the files nor the commands changed since the last such snapshot,
+// that snapshot is returned instead of saving a new one.
+func SaveBeforeDestructive(commands []string) (*Checkpoint, error) {
+	files, err := GetProjectFiles()
+	if err != nil {
+		return nil, err
+	}
+	hashes := computeFileHashes(files)
+
+	checkpoints, err := List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range checkpoints {
+		if checkpoints[i].Trigger != TriggerBeforeDestructive {
+			continue
+		}
+		if slices.Equal(checkpoints[i].Tasks.PendingTasks, commands) && maps.Equal(checkpoints[i].Files.Hashes, hashes) {
+			return &checkpoints[i], nil
+		}
+		break
+	}
+
+	return SaveWithOptions(SaveOptions{
+		Phase:       PhaseDeploy,
+		Type:        TypeSnapshot,
+		Trigger:     TriggerBeforeDestructive,
+		Description: fmt.Sprintf("Before destructive commands: %s", strings.Join(commands, ", ")),
+		Files:       FileState{Created: files, Hashes: hashes},
+		Tasks:       TaskState{PendingTasks: commands},
+	})
+}
+
 // computeFileHashes computes SHA256 hashes for files
 func computeFileHashes(files []string) map[string]string {
 	hashes := make(map[string]string)
@@ -404,12 +477,40 @@ func Delete(id string) error {
 		return fmt.Errorf("checkpoint not found: %s", id)
 	}
 
-	return saveIndex(updated)
+	if err := saveIndex(updated); err != nil {
+		return err
+	}
+	recordDelete(id, "")
+	return nil
 }
 
 // Clear removes all checkpoints
 func Clear() error {
-	return os.RemoveAll(GetCheckpointDir())
+	if err := os.RemoveAll(GetCheckpointDir()); err != nil {
+		return err
+	}
+	recordDelete("*", "cleared all checkpoints")
+	return nil
+}
+
+// redactContext masks secrets in the free-text context fields
+func redactContext(r *redact.Redactor, c Context) Context {
+	c.LastPrompt = r.Text(c.LastPrompt)
+	c.LastAgentResponse = r.Text(c.LastAgentResponse)
+	c.ErrorMessage = r.Text(c.ErrorMessage)
+	c.ErrorStack = r.Text(c.ErrorStack)
+	return c
+}
+
+// recordDelete appends a checkpoint deletion to the audit log
+func recordDelete(id, detail string) {
+	audit.RecordBestEffort(audit.Event{
+		Kind:    audit.KindCheckpoint,
+		Name:    id,
+		Args:    map[string]interface{}{"action": "delete"},
+		Outcome: audit.OutcomeOK,
+		Detail:  detail,
+	})
 }
 
 func saveIndex(checkpoints []Checkpoint) error {
@@ -420,7 +521,17 @@ func saveIndex(checkpoints []Checkpoint) error {
 
 // GenerateResumePrompt generates a prompt for resuming from a checkpoint
 func GenerateResumePrompt(checkpoint *Checkpoint) string {
-	nextPhase := NextPhase(checkpoint.Phase)
+	return generateResumePrompt(checkpoint, true)
+}
+
+// GenerateBuildResumePrompt generates a resume prompt for a build whose
+// checkpoints azd copilot saves itself, so the agent is not asked to
+func GenerateBuildResumePrompt(checkpoint *Checkpoint) string {
+	return generateResumePrompt(checkpoint, false)
+}
+
+func generateResumePrompt(checkpoint *Checkpoint, agentSaves bool) string {
+	nextPhase := ResumePhase(checkpoint)
 
 	var sb strings.Builder
 
@@ -477,15 +588,27 @@ func GenerateResumePrompt(checkpoint *Checkpoint) string {
 		sb.WriteString("1. Read the spec at `docs/spec.md`\n")
 		sb.WriteString("2. Review existing files - do NOT regenerate them unless changes are needed\n")
 		fmt.Fprintf(&sb, "3. Proceed with the **%s** phase\n", nextPhase)
-		fmt.Fprintf(&sb, "4. Save a checkpoint when %s is complete\n\n", nextPhase)
+		if agentSaves {
+			fmt.Fprintf(&sb, "4. Save a checkpoint when %s is complete\n", nextPhase)
+		}
+		sb.WriteString("\n")
 	}
 
 	// Phase-specific guidance
-	sb.WriteString(getPhaseGuidance(nextPhase))
+	sb.WriteString(PhaseGuidance(nextPhase))
 
 	return sb.String()
 }
 
+// ResumePhase returns the phase a build continues with after a checkpoint:
+// the failed phase for recovery checkpoints, otherwise the next one
+func ResumePhase(checkpoint *Checkpoint) Phase {
+	if checkpoint.Type == TypeRecovery {
+		return checkpoint.Phase
+	}
+	return NextPhase(checkpoint.Phase)
+}
+
 // NextPhase returns the phase that follows the given phase
 func NextPhase(phase Phase) Phase {
 	switch phase {
@@ -504,8 +627,8 @@ func NextPhase(phase Phase) Phase {
 	}
 }
 
-// getPhaseGuidance returns guidance text for a phase
-func getPhaseGuidance(phase Phase) string {
+// PhaseGuidance returns guidance text for a phase
+func PhaseGuidance(phase Phase) string {
 	switch phase {
 	case PhaseDesign:
 		return `### Design Phase Tasks
@@ -579,24 +702,62 @@ func KeepLatest(n int) error {
 	}
 
 	// Update index with only the kept checkpoints
-	return saveIndex(checkpoints[:n])
+	if err := saveIndex(checkpoints[:n]); err != nil {
+		return err
+	}
+	for _, cp := range checkpoints[n:] {
+		recordDelete(cp.ID, fmt.Sprintf("pruned to the latest %d", n))
+	}
+	return nil
 }
 
 // DetectInterrupted checks if there's an incomplete build that can be resumed
 func DetectInterrupted() (*Checkpoint, error) {
-	latest, err := Latest()
+	latest, err := LatestBuild()
 	if err != nil || latest == nil {
 		return nil, err
 	}
 
-	// If the latest checkpoint is not for deploy phase, build was interrupted
-	if latest.Phase != PhaseDeploy && latest.CanResume {
+	// If the latest checkpoint is not for deploy phase, or deploy failed,
+	// the build was interrupted
+	if (latest.Phase != PhaseDeploy || latest.Type == TypeRecovery) && latest.CanResume {
 		return latest, nil
 	}
 
 	return nil, nil
 }
 
+// ChangedFiles compares the files recorded across checkpoints with the
+// workspace, using the newest hash recorded for each file. It returns the
+// files whose content differs and the files that no longer exist.
+func ChangedFiles() (modified, deleted []string, err error) {
+	checkpoints, err := List()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recorded := make(map[string]string)
+	for _, cp := range checkpoints { // Newest first
+		for path, hash := range cp.Files.Hashes {
+			if _, ok := recorded[path]; !ok {
+				recorded[path] = hash
+			}
+		}
+	}
+	for path, hash := range recorded {
+		current, err := hashFile(path)
+		switch {
+		case os.IsNotExist(err):
+			deleted = append(deleted, path)
+		case err != nil || current != hash:
+			modified = append(modified, path)
+		}
+	}
+	sort.Strings(modified)
+	sort.Strings(deleted)
+	return modified, deleted, nil
+}
+
 // GetProjectFiles returns all files tracked across checkpoints
 func GetProjectFiles() ([]string, error) {
 	checkpoints, err := List()
@@ -4,10 +4,14 @@
 package checkpoint
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/audit"
 )
 
 func TestPhaseConstants(t *testing.T) {
@@ -301,7 +305,7 @@ func TestGenerateResumePrompt_RecoveryCheckpoint(t *testing.T) {
 	}
 }
 
-func TestGetPhaseGuidance(t *testing.T) {
+func TestPhaseGuidance(t *testing.T) {
 	tests := []struct {
 		phase    Phase
 		contains string
@@ -315,9 +319,9 @@ func TestGetPhaseGuidance(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(string(tt.phase), func(t *testing.T) {
-			guidance := getPhaseGuidance(tt.phase)
+			guidance := PhaseGuidance(tt.phase)
 			if tt.contains != "" && !contains(guidance, tt.contains) {
-				t.Errorf("getPhaseGuidance(%q) should contain %q", tt.phase, tt.contains)
+				t.Errorf("PhaseGuidance(%q) should contain %q", tt.phase, tt.contains)
 			}
 		})
 	}
@@ -356,3 +360,201 @@ func containsString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestSaveBeforeDestructive(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	cp, err := SaveBeforeDestructive([]string{"azd down", "az group delete"})
+	if err != nil {
+		t.Fatalf("SaveBeforeDestructive() error = %v", err)
+	}
+
+	if cp.Trigger != TriggerBeforeDestructive {
+		t.Errorf("Trigger = %q, want %q", cp.Trigger, TriggerBeforeDestructive)
+	}
+	if cp.Type != TypeSnapshot {
+		t.Errorf("Type = %q, want %q", cp.Type, TypeSnapshot)
+	}
+	if len(cp.Tasks.PendingTasks) != 2 {
+		t.Errorf("PendingTasks = %v, want 2 commands", cp.Tasks.PendingTasks)
+	}
+
+	saved, err := Get(cp.ID)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", cp.ID, err)
+	}
+	if saved.Trigger != TriggerBeforeDestructive {
+		t.Errorf("saved Trigger = %q, want %q", saved.Trigger, TriggerBeforeDestructive)
+	}
+}
+
+func TestSaveBeforeDestructive_RecordsProjectFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if err := os.WriteFile("main.go", []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Save(PhaseDevelop, "develop done", []string{"main.go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots := func() int {
+		t.Helper()
+		checkpoints, err := List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, cp := range checkpoints {
+			if cp.Trigger == TriggerBeforeDestructive {
+				n++
+			}
+		}
+		return n
+	}
+
+	cp, err := SaveBeforeDestructive([]string{"azd down"})
+	if err != nil {
+		t.Fatalf("SaveBeforeDestructive() error = %v", err)
+	}
+	if _, ok := cp.Files.Hashes["main.go"]; !ok {
+		t.Errorf("Files = %+v, want main.go recorded", cp.Files)
+	}
+
+	// Nothing changed, so the last snapshot is reused
+	if _, err := SaveBeforeDestructive([]string{"azd down"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := snapshots(); n != 1 {
+		t.Errorf("snapshots after an unchanged launch = %d, want 1", n)
+	}
+
+	// A changed file or command list saves a new snapshot
+	if err := os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SaveBeforeDestructive([]string{"azd down"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SaveBeforeDestructive([]string{"azd down", "az group delete"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := snapshots(); n != 3 {
+		t.Errorf("snapshots after two changes = %d, want 3", n)
+	}
+}
+
+func TestCheckpointsAreAudited(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("azure.yaml", []byte("name: app\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cp, err := Save(PhaseDesign, "before refactor", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(cp.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := audit.Read(audit.Filter{Kinds: []audit.Kind{audit.KindCheckpoint}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("audited %d checkpoint events, want 2: %+v", len(events), events)
+	}
+	for i, action := range []string{"create", "delete"} {
+		if events[i].Name != cp.ID || events[i].Args["action"] != action {
+			t.Errorf("event %d = %+v, want %s of %s", i, events[i], action, cp.ID)
+		}
+	}
+}
+
+func TestSaveRedactsContext(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	cp, err := SaveRecoveryCheckpoint(PhaseDeploy, errors.New("deploy failed: AccountKey=abc123== rejected"), "AZURE_STORAGE_KEY=abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := Get(cp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(saved.Context.ErrorMessage, "abc123") || !strings.Contains(saved.Context.ErrorMessage, "deploy failed") {
+		t.Errorf("ErrorMessage = %q, want the secret masked", saved.Context.ErrorMessage)
+	}
+	if strings.Contains(saved.Context.LastPrompt, "abc123") || strings.Contains(saved.Description, "abc123") {
+		t.Errorf("checkpoint = %+v, want secrets masked everywhere", saved)
+	}
+}
+
+func TestResumePhase(t *testing.T) {
+	tests := []struct {
+		cp   Checkpoint
+		want Phase
+	}{
+		{Checkpoint{Type: TypePhase, Phase: PhaseSpec}, PhaseDesign},
+		{Checkpoint{Type: TypePhase, Phase: PhaseDevelop}, PhaseQuality},
+		{Checkpoint{Type: TypeRecovery, Phase: PhaseDevelop}, PhaseDevelop},
+		{Checkpoint{Type: TypeRecovery, Phase: PhaseDeploy}, PhaseDeploy},
+	}
+	for _, tt := range tests {
+		if got := ResumePhase(&tt.cp); got != tt.want {
+			t.Errorf("ResumePhase(%s %s) = %s, want %s", tt.cp.Type, tt.cp.Phase, got, tt.want)
+		}
+	}
+}
+
+func TestDetectInterrupted(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if _, err := SavePhaseCheckpoint(PhaseDeploy, "deploy done", nil, []Phase{PhaseSpec, PhaseDesign, PhaseDevelop, PhaseQuality, PhaseDeploy}); err != nil {
+		t.Fatal(err)
+	}
+	if cp, err := DetectInterrupted(); err != nil || cp != nil {
+		t.Errorf("DetectInterrupted() after deploy = %v, %v; want nil", cp, err)
+	}
+
+	if _, err := SaveRecoveryCheckpoint(PhaseDeploy, errors.New("preflight failed"), ""); err != nil {
+		t.Fatal(err)
+	}
+	cp, err := DetectInterrupted()
+	if err != nil || cp == nil || cp.Type != TypeRecovery {
+		t.Errorf("DetectInterrupted() after a failed deploy = %v, %v; want the recovery checkpoint", cp, err)
+	}
+}
+
+func TestGenerateBuildResumePrompt(t *testing.T) {
+	cp := &Checkpoint{ID: "phase-design-1", Type: TypePhase, Phase: PhaseDesign, CompletedPhases: []Phase{PhaseSpec, PhaseDesign}}
+
+	if !contains(GenerateResumePrompt(cp), "Save a checkpoint") {
+		t.Error("GenerateResumePrompt() should ask the agent to save a checkpoint")
+	}
+	if prompt := GenerateBuildResumePrompt(cp); contains(prompt, "Save a checkpoint") || !contains(prompt, "Proceed with the **develop** phase") {
+		t.Errorf("GenerateBuildResumePrompt() =\n%s\nwant the develop instructions without saving a checkpoint", prompt)
+	}
+}
+
+func TestDetectInterrupted_IgnoresSnapshots(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	recovery, err := SaveRecoveryCheckpoint(PhaseDevelop, errors.New("build failed"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A session auto-approving 'azd down' saves a deploy snapshot afterwards
+	if _, err := SaveBeforeDestructive([]string{"azd down"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cp, err := DetectInterrupted()
+	if err != nil || cp == nil || cp.ID != recovery.ID {
+		t.Errorf("DetectInterrupted() = %v, %v; want the develop recovery checkpoint %s", cp, err, recovery.ID)
+	}
+	if latest, _ := LatestBuild(); latest == nil || latest.ID != recovery.ID {
+		t.Errorf("LatestBuild() = %v, want %s", latest, recovery.ID)
+	}
+}
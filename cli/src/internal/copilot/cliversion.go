@@ -0,0 +1,53 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package copilot
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// versionPattern matches the first semantic version in `copilot --version` output.
+var versionPattern = regexp.MustCompile(`\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?`)
+
+// DetectCLIVersion runs `copilot --version` and returns the reported version.
+func DetectCLIVersion(ctx context.Context, copilotPath *CopilotPath) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	out, err := newCopilotCmd(ctx, copilotPath, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run copilot --version: %w", err)
+	}
+
+	version := parseCLIVersion(string(out))
+	if version == "" {
+		return "", fmt.Errorf("unrecognized copilot --version output: %q", strings.TrimSpace(string(out)))
+	}
+	return version, nil
+}
+
+// parseCLIVersion extracts the version from `copilot --version` output.
+func parseCLIVersion(output string) string {
+	return versionPattern.FindString(output)
+}
+
+// newCopilotCmd builds the command that runs the Copilot CLI with args,
+// going through node or cmd.exe when the install requires it.
+func newCopilotCmd(ctx context.Context, copilotPath *CopilotPath, args ...string) *exec.Cmd {
+	if copilotPath.IsNode {
+		nodeArgs := append([]string{copilotPath.Path}, args...)
+		return exec.CommandContext(ctx, "node", nodeArgs...) //nolint:gosec // G204: copilotPath is resolved from internal lookup, not user input
+	}
+	if runtime.GOOS == "windows" && (strings.HasSuffix(copilotPath.Path, ".bat") || strings.HasSuffix(copilotPath.Path, ".cmd")) {
+		cmdArgs := append([]string{"/c", copilotPath.Path}, args...)
+		return exec.CommandContext(ctx, "cmd.exe", cmdArgs...) //nolint:gosec // G204: copilotPath is resolved from internal lookup, not user input
+	}
+	return exec.CommandContext(ctx, copilotPath.Path, args...) //nolint:gosec // G204: copilotPath is resolved from internal lookup, not user input
+}
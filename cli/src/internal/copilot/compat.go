@@ -0,0 +1,220 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package copilot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/cache"
+)
+
+// Feature is a Copilot CLI capability that azd copilot depends on
+type Feature string
+
+// Features gated by the compatibility table.
+const (
+	FeatureAllowAllTools Feature = "allow-all-tools"
+	FeatureAddDir        Feature = "add-dir"
+	FeatureToolRules     Feature = "allow-tool"
+	FeatureYolo          Feature = "yolo"
+	FeatureAgent         Feature = "agent"
+	FeatureAdditionalMCP Feature = "additional-mcp-config"
+	FeatureDisableMCP    Feature = "disable-mcp-server"
+)
+
+// Requirement describes the minimum Copilot CLI version for a feature
+type Requirement struct {
+	Feature    Feature `json:"feature"`
+	Flag       string  `json:"flag"`
+	MinVersion string  `json:"minVersion"`
+	Source     string  `json:"source"` // Release notes entry that introduced the flag
+}
+
+// cliChangelog is the Copilot CLI release notes the minimum versions come from
+const cliChangelog = "https://github.com/github/copilot-cli/blob/main/changelog.md"
+
+// Compatibility maps features to the first Copilot CLI release that supports
+// them, sorted by MinVersion
+var Compatibility = []Requirement{
+	{Feature: FeatureAllowAllTools, Flag: "--allow-all-tools", MinVersion: "0.0.328", Source: cliChangelog + " (0.0.328)"},
+	{Feature: FeatureAddDir, Flag: "--add-dir", MinVersion: "0.0.330", Source: cliChangelog + " (0.0.330)"},
+	{Feature: FeatureToolRules, Flag: "--allow-tool/--deny-tool", MinVersion: "0.0.330", Source: cliChangelog + " (0.0.330)"},
+	{Feature: FeatureYolo, Flag: "--yolo", MinVersion: "0.0.340", Source: cliChangelog + " (0.0.340)"},
+	{Feature: FeatureAdditionalMCP, Flag: "--additional-mcp-config", MinVersion: "0.0.343", Source: cliChangelog + " (0.0.343)"},
+	{Feature: FeatureDisableMCP, Flag: "--disable-mcp-server", MinVersion: "0.0.343", Source: cliChangelog + " (0.0.343)"},
+	{Feature: FeatureAgent, Flag: "--agent", MinVersion: "0.0.353", Source: cliChangelog + " (0.0.353)"},
+}
+
+// upgradeCommand is suggested whenever the installed Copilot CLI is too old
+const upgradeCommand = "npm install -g @github/copilot@latest"
+
+// Supports reports whether the given Copilot CLI version supports a feature.
+// An empty or unparsable version is assumed to support everything so that
+// detection failures never block a launch.
+func Supports(version string, feature Feature) bool {
+	if version == "" {
+		return true
+	}
+	for _, r := range Compatibility {
+		if r.Feature == feature {
+			cmp, ok := compareVersions(version, r.MinVersion)
+			return !ok || cmp >= 0
+		}
+	}
+	return true
+}
+
+// Unsupported returns the requirements the given version does not meet
+func Unsupported(version string) []Requirement {
+	var missing []Requirement
+	for _, r := range Compatibility {
+		if !Supports(version, r.Feature) {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// ResolveCLIVersion returns the Copilot CLI version, using the setup cache
+// when it was detected for the same executable at the same size and
+// modification time.
+func ResolveCLIVersion(ctx context.Context, copilotPath *CopilotPath) (string, error) {
+	c, _ := cache.Load()
+	stamp := CLIStamp(copilotPath.Path)
+	if c != nil && c.CLIPath == copilotPath.Path && stamp != "" && c.CLIStamp == stamp && c.CLIVersion != "" {
+		return c.CLIVersion, nil
+	}
+
+	version, err := DetectCLIVersion(ctx, copilotPath)
+	if err != nil {
+		return "", err
+	}
+
+	if c == nil {
+		c = &cache.SetupCache{}
+	}
+	c.CLIPath = copilotPath.Path
+	c.CLIStamp = stamp
+	c.CLIVersion = version
+	_ = cache.Save(c) // best-effort; detection simply reruns next time
+
+	return version, nil
+}
+
+// CLIStamp identifies one build of the Copilot CLI executable by its size
+// and modification time, or returns "" when it cannot be read
+func CLIStamp(path string) string {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", fi.Size(), fi.ModTime().UnixNano())
+}
+
+// gateOptions adapts opts to the installed Copilot CLI version. Options that
+// only add convenience are dropped or replaced with a warning; options whose
+// loss would weaken the user's tool policy produce an upgrade error instead.
+func gateOptions(opts Options, version string) (Options, []string, error) {
+	var warnings []string
+
+	if (len(opts.AllowTools) > 0 || len(opts.DenyTools) > 0) && !Supports(version, FeatureToolRules) {
+		return opts, nil, upgradeError(version, FeatureToolRules)
+	}
+
+	if opts.Yolo && !Supports(version, FeatureYolo) {
+		if !Supports(version, FeatureAllowAllTools) {
+			return opts, nil, upgradeError(version, FeatureYolo)
+		}
+		opts.Yolo = false
+		opts.AllowAllTools = true
+		warnings = append(warnings, fmt.Sprintf("Copilot CLI %s does not support --yolo; using --allow-all-tools instead", version))
+	}
+
+	if !opts.NoAgent && !Supports(version, FeatureAgent) {
+		warnings = append(warnings, fmt.Sprintf("Copilot CLI %s does not support --agent; starting without the %q agent (upgrade with: %s)", version, defaultAgent(opts.Agent), upgradeCommand))
+		opts.NoAgent = true
+	}
+
+	// A project that disables a server may depend on it not running
+	if len(opts.DisableMCPServers) > 0 && !Supports(version, FeatureDisableMCP) {
+		return opts, nil, upgradeError(version, FeatureDisableMCP)
+	}
+
+	if opts.AdditionalMCPConfig != "" && !Supports(version, FeatureAdditionalMCP) {
+		warnings = append(warnings, fmt.Sprintf("Copilot CLI %s does not support --additional-mcp-config; project MCP servers are not loaded (upgrade with: %s)", version, upgradeCommand))
+		opts.AdditionalMCPConfig = ""
+	}
+
+	if len(opts.AddDirs) > 0 && !Supports(version, FeatureAddDir) {
+		warnings = append(warnings, fmt.Sprintf("Copilot CLI %s does not support --add-dir; ignoring %d additional directories (upgrade with: %s)", version, len(opts.AddDirs), upgradeCommand))
+		opts.AddDirs = nil
+	}
+
+	return opts, warnings, nil
+}
+
+func upgradeError(version string, feature Feature) error {
+	for _, r := range Compatibility {
+		if r.Feature == feature {
+			return fmt.Errorf("copilot CLI %s does not support %s (requires %s or later); upgrade with: %s", version, r.Flag, r.MinVersion, upgradeCommand)
+		}
+	}
+	return fmt.Errorf("copilot CLI %s does not support %s; upgrade with: %s", version, feature, upgradeCommand)
+}
+
+// compareVersions compares two semantic versions, returning -1, 0 or 1.
+// ok is false when either version cannot be parsed.
+func compareVersions(a, b string) (cmp int, ok bool) {
+	pa, prea, okA := parseSemver(a)
+	pb, preb, okB := parseSemver(b)
+	if !okA || !okB {
+		return 0, false
+	}
+
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+
+	// A pre-release sorts before the corresponding release
+	switch {
+	case prea == preb:
+		return 0, true
+	case prea == "":
+		return 1, true
+	case preb == "":
+		return -1, true
+	case prea < preb:
+		return -1, true
+	default:
+		return 1, true
+	}
+}
+
+func parseSemver(v string) ([3]int, string, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	core, pre, _ := strings.Cut(v, "-")
+
+	fields := strings.Split(core, ".")
+	if len(fields) != 3 {
+		return parts, "", false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return parts, "", false
+		}
+		parts[i] = n
+	}
+	return parts, pre, true
+}
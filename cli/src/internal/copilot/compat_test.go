@@ -0,0 +1,186 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package copilot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/cache"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{"0.0.354", "0.0.353", 1, true},
+		{"0.0.353", "0.0.353", 0, true},
+		{"0.0.99", "0.0.353", -1, true},
+		{"1.0.0", "0.0.400", 1, true},
+		{"v0.0.353", "0.0.353", 0, true},
+		{"0.0.353-beta.1", "0.0.353", -1, true},
+		{"0.0.353-beta.2", "0.0.353-beta.1", 1, true},
+		{"unknown", "0.0.353", 0, false},
+		{"1.2", "0.0.353", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := compareVersions(tt.a, tt.b)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("compareVersions(%q, %q) = (%d, %v), want (%d, %v)", tt.a, tt.b, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSupports(t *testing.T) {
+	tests := []struct {
+		version string
+		feature Feature
+		want    bool
+	}{
+		{"", FeatureAgent, true},
+		{"garbage", FeatureAgent, true},
+		{"0.0.353", FeatureAgent, true},
+		{"0.0.352", FeatureAgent, false},
+		{"0.0.339", FeatureYolo, false},
+		{"0.0.339", FeatureAllowAllTools, true},
+		{"0.0.300", Feature("unknown"), true},
+	}
+
+	for _, tt := range tests {
+		if got := Supports(tt.version, tt.feature); got != tt.want {
+			t.Errorf("Supports(%q, %q) = %v, want %v", tt.version, tt.feature, got, tt.want)
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	if got := Unsupported("9.9.9"); len(got) != 0 {
+		t.Errorf("Unsupported(9.9.9) = %v, want none", got)
+	}
+	if got := Unsupported("0.0.1"); len(got) != len(Compatibility) {
+		t.Errorf("Unsupported(0.0.1) returned %d requirements, want %d", len(got), len(Compatibility))
+	}
+}
+
+func TestCompatibility_Sorted(t *testing.T) {
+	for i := 1; i < len(Compatibility); i++ {
+		prev, r := Compatibility[i-1], Compatibility[i]
+		if cmp, ok := compareVersions(prev.MinVersion, r.MinVersion); !ok || cmp > 0 {
+			t.Errorf("%s (%s) is listed after %s (%s); keep the table sorted by MinVersion", r.Feature, r.MinVersion, prev.Feature, prev.MinVersion)
+		}
+		if r.Source == "" {
+			t.Errorf("%s has no source for its minimum version", r.Feature)
+		}
+	}
+}
+
+func TestGateOptions(t *testing.T) {
+	t.Run("current version keeps every option", func(t *testing.T) {
+		in := Options{Yolo: true, Agent: "azure-dev", AddDirs: []string{"../shared"}, AllowTools: []string{"shell(git status)"}}
+		out, warnings, err := gateOptions(in, "0.0.400")
+		if err != nil {
+			t.Fatalf("gateOptions() error = %v", err)
+		}
+		if len(warnings) != 0 {
+			t.Errorf("gateOptions() warnings = %v, want none", warnings)
+		}
+		if strings.Join(buildArgs(out), " ") != strings.Join(buildArgs(in), " ") {
+			t.Errorf("gateOptions() changed args: %v", buildArgs(out))
+		}
+	})
+
+	t.Run("unknown version keeps every option", func(t *testing.T) {
+		out, warnings, err := gateOptions(Options{Yolo: true}, "")
+		if err != nil || len(warnings) != 0 || !out.Yolo {
+			t.Errorf("gateOptions() = (%+v, %v, %v), want unchanged", out, warnings, err)
+		}
+	})
+
+	t.Run("old version degrades convenience flags", func(t *testing.T) {
+		in := Options{Yolo: true, AddDirs: []string{"../shared"}}
+		out, warnings, err := gateOptions(in, "0.0.332")
+		if err != nil {
+			t.Fatalf("gateOptions() error = %v", err)
+		}
+		args := strings.Join(buildArgs(out), " ")
+		for _, unwanted := range []string{"--yolo", "--agent"} {
+			if strings.Contains(args, unwanted) {
+				t.Errorf("args %q should not contain %s", args, unwanted)
+			}
+		}
+		if !strings.Contains(args, "--allow-all-tools") {
+			t.Errorf("args %q should fall back to --allow-all-tools", args)
+		}
+		if !strings.Contains(args, "--add-dir") {
+			t.Errorf("args %q should keep --add-dir", args)
+		}
+		if len(warnings) != 2 {
+			t.Errorf("gateOptions() warnings = %v, want 2", warnings)
+		}
+	})
+
+	t.Run("project MCP settings on old version", func(t *testing.T) {
+		out, warnings, err := gateOptions(Options{AdditionalMCPConfig: "/tmp/session.json"}, "0.0.342")
+		if err != nil {
+			t.Fatalf("gateOptions() error = %v", err)
+		}
+		if out.AdditionalMCPConfig != "" || len(warnings) == 0 {
+			t.Errorf("gateOptions() = (%q, %v), want config dropped with a warning", out.AdditionalMCPConfig, warnings)
+		}
+		if _, _, err := gateOptions(Options{DisableMCPServers: []string{"playwright"}}, "0.0.342"); err == nil {
+			t.Error("gateOptions() should refuse to load servers a project disabled")
+		}
+	})
+
+	t.Run("tool rules require upgrade", func(t *testing.T) {
+		_, _, err := gateOptions(Options{DenyTools: []string{"shell(az group delete)"}}, "0.0.329")
+		if err == nil {
+			t.Fatal("gateOptions() should refuse to drop deny rules")
+		}
+		if !strings.Contains(err.Error(), upgradeCommand) {
+			t.Errorf("error %q should include the upgrade command", err)
+		}
+	})
+}
+
+func TestResolveCLIVersion_UsesCache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	// The file is not executable, so a cache miss fails to run it
+	cliPath := filepath.Join(home, "copilot")
+	if err := os.WriteFile(cliPath, []byte("0.0.360"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Save(&cache.SetupCache{CLIPath: cliPath, CLIStamp: CLIStamp(cliPath), CLIVersion: "0.0.360"}); err != nil {
+		t.Fatalf("cache.Save() error = %v", err)
+	}
+
+	version, err := ResolveCLIVersion(context.Background(), &CopilotPath{Path: cliPath})
+	if err != nil {
+		t.Fatalf("ResolveCLIVersion() error = %v", err)
+	}
+	if version != "0.0.360" {
+		t.Errorf("ResolveCLIVersion() = %q, want cached 0.0.360", version)
+	}
+
+	if _, err := ResolveCLIVersion(context.Background(), &CopilotPath{Path: filepath.Join(home, "other-copilot")}); err == nil {
+		t.Error("ResolveCLIVersion() should re-detect when the executable changes")
+	}
+
+	// An in-place upgrade changes the size and modification time
+	if err := os.WriteFile(cliPath, []byte("0.0.400 upgraded"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ResolveCLIVersion(context.Background(), &CopilotPath{Path: cliPath}); err == nil {
+		t.Error("ResolveCLIVersion() should re-detect when the executable is upgraded in place")
+	}
+}
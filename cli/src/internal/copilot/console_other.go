@@ -20,3 +20,12 @@ func attachConsole() (*consoleHandles, error) {
 }
 
 func (h *consoleHandles) restore() {}
+
+// CheckConsole reports whether /dev/tty can be opened for interactive sessions.
+func CheckConsole() (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "/dev/tty", err
+	}
+	return "/dev/tty", tty.Close()
+}
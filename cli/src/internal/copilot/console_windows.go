@@ -72,3 +72,15 @@ func (h *consoleHandles) restore() {
 	_ = h.conin.Close()
 	_ = h.conout.Close()
 }
+
+// CheckConsole reports whether CONIN$/CONOUT$ can be opened for interactive sessions.
+func CheckConsole() (string, error) {
+	for _, name := range []string{"CONOUT$", "CONIN$"} {
+		f, err := os.OpenFile(name, os.O_RDWR, 0)
+		if err != nil {
+			return name, err
+		}
+		_ = f.Close()
+	}
+	return "CONIN$/CONOUT$", nil
+}
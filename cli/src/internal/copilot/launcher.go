@@ -5,7 +5,7 @@ package copilot
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -14,7 +14,9 @@ import (
 	"strings"
 	"time"
 
-	"github.com/jongio/azd-core/fileutil"
+	"github.com/jongio/azd-copilot/cli/src/internal/audit"
+	"github.com/jongio/azd-copilot/cli/src/internal/mcpconfig"
+	"github.com/jongio/azd-copilot/cli/src/internal/redact"
 )
 
 // Version is set by the main package at startup.
@@ -22,6 +24,7 @@ var Version = "0.1.0"
 
 // Options configures the Copilot CLI launch
 type Options struct {
+	Command        string // azd copilot command that started the session, for the audit log
 	Prompt         string
 	Resume         bool
 	Continue       bool
@@ -32,6 +35,14 @@ type Options struct {
 	Verbose        bool
 	Debug          bool
 	ProjectContext *ProjectContext
+	AllowTools     []string     // Tool specs approved without prompting (--allow-tool)
+	DenyTools      []string     // Tool specs that are always refused (--deny-tool)
+	AllowAllTools  bool         // Fallback for --yolo on older Copilot CLI versions
+	NoAgent        bool         // Omit --agent for Copilot CLI versions without custom agents
+	CopilotPath    *CopilotPath // Previously resolved executable; skips probing when still present
+
+	AdditionalMCPConfig string   // Per-session MCP config file (--additional-mcp-config)
+	DisableMCPServers   []string // MCP servers not loaded for this session (--disable-mcp-server)
 }
 
 // ProjectContext contains azd project information
@@ -42,6 +53,7 @@ type ProjectContext struct {
 	Environment    map[string]string
 	AzureAccount   *AzureAccountInfo
 	Infrastructure *InfrastructureInfo
+	Skills         []string // Skills suggested for the detected stack
 }
 
 // ServiceInfo contains service details
@@ -68,15 +80,36 @@ type InfrastructureInfo struct {
 }
 
 // Launch starts the GitHub Copilot CLI with configured options
-func Launch(ctx context.Context, opts Options) error {
-	copilotPath, err := FindCopilotCLI()
+func Launch(ctx context.Context, opts Options) (err error) {
+	copilotPath := opts.CopilotPath
+	if copilotPath == nil || !copilotPath.Exists() {
+		found, err := FindCopilotCLI()
+		if err != nil {
+			return err
+		}
+		copilotPath = found
+	}
+
+	// Adapt flags to the installed Copilot CLI; detection failures are not fatal
+	version, err := ResolveCLIVersion(ctx, copilotPath)
+	if err != nil && opts.Debug {
+		fmt.Printf("DEBUG: Could not detect Copilot CLI version: %v\n", err)
+	}
+	opts, warnings, err := gateOptions(opts, version)
 	if err != nil {
 		return err
 	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
 
 	args := buildArgs(opts)
 
+	started := time.Now().UTC()
+	defer func() { recordLaunch(opts, started, err) }()
+
 	if opts.Debug {
+		fmt.Printf("DEBUG: Copilot CLI version: %s\n", version)
 		fmt.Printf("DEBUG: Copilot path: %s (IsNode: %v)\n", copilotPath.Path, copilotPath.IsNode)
 		fmt.Printf("DEBUG: Args: %v\n", args)
 	}
@@ -98,24 +131,9 @@ func Launch(ctx context.Context, opts Options) error {
 		return launchViaConsole(ctx, copilotPath, args, opts)
 	}
 
-	var cmd *exec.Cmd
-
-	if copilotPath.IsNode {
-		// Run via node
-		nodeArgs := append([]string{copilotPath.Path}, args...)
-		cmd = exec.CommandContext(ctx, "node", nodeArgs...) //nolint:gosec // G204: copilotPath is resolved from internal lookup, not user input
-		if opts.Debug || os.Getenv("AZD_COPILOT_DEBUG") == "true" {
-			fmt.Printf("DEBUG: Running via node\n")
-		}
-	} else if runtime.GOOS == "windows" && (strings.HasSuffix(copilotPath.Path, ".bat") || strings.HasSuffix(copilotPath.Path, ".cmd")) {
-		// On Windows, .bat/.cmd files need to be run via cmd.exe
-		cmdArgs := append([]string{"/c", copilotPath.Path}, args...)
-		cmd = exec.CommandContext(ctx, "cmd.exe", cmdArgs...) //nolint:gosec // G204: copilotPath is resolved from internal lookup, not user input
-		if opts.Debug || os.Getenv("AZD_COPILOT_DEBUG") == "true" {
-			fmt.Printf("DEBUG: Running via cmd.exe /c\n")
-		}
-	} else {
-		cmd = exec.CommandContext(ctx, copilotPath.Path, args...) //nolint:gosec // G204: copilotPath is resolved from internal lookup, not user input
+	cmd := newCopilotCmd(ctx, copilotPath, args...)
+	if opts.Debug || os.Getenv("AZD_COPILOT_DEBUG") == "true" {
+		fmt.Printf("DEBUG: Running %v\n", cmd.Args)
 	}
 
 	cmd.Stdin = os.Stdin
@@ -136,6 +154,28 @@ func Launch(ctx context.Context, opts Options) error {
 	return cmd.Run()
 }
 
+// recordLaunch appends a Copilot CLI session to the audit log. The prompt
+// is recorded only as a hash.
+func recordLaunch(opts Options, started time.Time, err error) {
+	command := opts.Command
+	if command == "" {
+		command = "azd copilot"
+	}
+	args := map[string]interface{}{
+		"agent": opts.Agent,
+		"model": opts.Model,
+		"yolo":  opts.Yolo,
+	}
+	if opts.Prompt != "" {
+		args["promptHash"] = audit.Hash(opts.Prompt)
+	}
+	e := audit.Event{Time: started, Kind: audit.KindLaunch, Name: command, Args: args, Outcome: audit.OutcomeOK}
+	if err != nil {
+		e.Outcome, e.Detail = audit.OutcomeError, err.Error()
+	}
+	audit.RecordBestEffort(e)
+}
+
 // launchViaConsole bypasses azd's stdio capture for interactive TUI apps.
 // On macOS/Linux it opens /dev/tty directly.
 // On Windows it uses SetStdHandle to redirect the process's standard handles
@@ -148,17 +188,7 @@ func launchViaConsole(ctx context.Context, copilotPath *CopilotPath, args []stri
 		fmt.Printf("DEBUG: Opening console/tty directly for interactive mode\n")
 	}
 
-	var cmd *exec.Cmd
-
-	if copilotPath.IsNode {
-		nodeArgs := append([]string{copilotPath.Path}, args...)
-		cmd = exec.CommandContext(ctx, "node", nodeArgs...) //nolint:gosec // G204: copilotPath is resolved from internal lookup, not user input
-	} else if runtime.GOOS == "windows" && (strings.HasSuffix(copilotPath.Path, ".bat") || strings.HasSuffix(copilotPath.Path, ".cmd")) {
-		cmdArgs := append([]string{"/c", copilotPath.Path}, args...)
-		cmd = exec.CommandContext(ctx, "cmd.exe", cmdArgs...) //nolint:gosec // G204: copilotPath is resolved from internal lookup, not user input
-	} else {
-		cmd = exec.CommandContext(ctx, copilotPath.Path, args...) //nolint:gosec // G204: copilotPath is resolved from internal lookup, not user input
-	}
+	cmd := newCopilotCmd(ctx, copilotPath, args...)
 
 	if runtime.GOOS == "windows" {
 		// On Windows, use SetStdHandle to point our process's standard handles
@@ -215,10 +245,90 @@ type CopilotPath struct {
 	IsNode bool   // If true, run via "node <path>"
 }
 
+// Exists reports whether the resolved executable or script is still on disk
+func (p *CopilotPath) Exists() bool {
+	_, err := os.Stat(p.Path)
+	return err == nil
+}
+
+// Candidate is a location checked while looking for the Copilot CLI.
+type Candidate struct {
+	Path   string `json:"path"`
+	IsNode bool   `json:"isNode"`
+	Found  bool   `json:"found"`
+	Note   string `json:"note,omitempty"`
+}
+
 // FindCopilotCLI locates the GitHub Copilot CLI executable
 func FindCopilotCLI() (*CopilotPath, error) {
-	// Platform-specific locations - check these FIRST before PATH
-	// to avoid finding npm shims or .ps1 launchers (which cause file lock issues)
+	_, found := ProbeCopilotCLI()
+	if found != nil {
+		return found, nil
+	}
+
+	return nil, fmt.Errorf("GitHub Copilot CLI not found. Install with: %s", InstallHint())
+}
+
+// InstallHint returns the platform-specific command for installing the Copilot CLI.
+func InstallHint() string {
+	if runtime.GOOS == "windows" {
+		return "winget install GitHub.Copilot"
+	}
+	return "npm install -g @github/copilot"
+}
+
+// ProbeCopilotCLI checks every known Copilot CLI location in priority order.
+// It returns all candidates that were checked and the first one found, or nil.
+func ProbeCopilotCLI() ([]Candidate, *CopilotPath) {
+	candidates := copilotCandidates()
+
+	// Fall back to PATH lookup (may find .cmd/.bat but that's last resort)
+	// Skip .ps1 files - they cause file lock issues on Windows and aren't cross-platform
+	if path, err := exec.LookPath("copilot"); err == nil {
+		c := Candidate{Path: path}
+		if strings.HasSuffix(strings.ToLower(path), ".ps1") {
+			c.Note = "PowerShell launchers are skipped"
+		}
+		candidates = append(candidates, c)
+	} else {
+		candidates = append(candidates, Candidate{Path: "copilot", Note: "not on PATH"})
+	}
+
+	var found *CopilotPath
+	for i := range candidates {
+		c := &candidates[i]
+		if c.Note != "" {
+			continue
+		}
+		c.Path = filepath.Clean(c.Path)
+		if _, err := os.Stat(c.Path); err != nil { // #nosec G703 -- known install paths
+			continue
+		}
+		c.Found = true
+		if found == nil {
+			found = &CopilotPath{Path: c.Path, IsNode: c.IsNode}
+		}
+	}
+
+	return candidates, found
+}
+
+// copilotCandidates returns the platform-specific install locations in priority order.
+// Platform-specific locations are checked FIRST before PATH
+// to avoid finding npm shims or .ps1 launchers (which cause file lock issues)
+func copilotCandidates() []Candidate {
+	var candidates []Candidate
+	node := func(paths ...string) {
+		for _, p := range paths {
+			candidates = append(candidates, Candidate{Path: p, IsNode: true})
+		}
+	}
+	binary := func(paths ...string) {
+		for _, p := range paths {
+			candidates = append(candidates, Candidate{Path: p})
+		}
+	}
+
 	if runtime.GOOS == "windows" {
 		home := os.Getenv("USERPROFILE")
 		appData := os.Getenv("APPDATA")
@@ -233,129 +343,71 @@ func FindCopilotCLI() (*CopilotPath, error) {
 			}
 		}
 		if npmGlobalPath != "" {
-			npmLoader := filepath.Clean(filepath.Join(npmGlobalPath, "node_modules", "@github", "copilot", "npm-loader.js"))
-			if _, err := os.Stat(npmLoader); err == nil { // #nosec G703 -- path from known npm config
-				return &CopilotPath{Path: npmLoader, IsNode: true}, nil
-			}
+			node(filepath.Join(npmGlobalPath, "node_modules", "@github", "copilot", "npm-loader.js"))
 		}
 
 		// NVM for Windows
-		nvmRoot := os.Getenv("NVM_HOME")
-		if nvmRoot != "" {
-			currentVersion := os.Getenv("NVM_SYMLINK")
-			if currentVersion != "" {
-				npmLoader := filepath.Clean(filepath.Join(currentVersion, "node_modules", "@github", "copilot", "npm-loader.js"))
-				if _, err := os.Stat(npmLoader); err == nil { // #nosec G703 -- path from known env var
-					return &CopilotPath{Path: npmLoader, IsNode: true}, nil
-				}
+		if os.Getenv("NVM_HOME") != "" {
+			if currentVersion := os.Getenv("NVM_SYMLINK"); currentVersion != "" {
+				node(filepath.Join(currentVersion, "node_modules", "@github", "copilot", "npm-loader.js"))
 			}
 		}
-		// Check appdata nvm location
-		nvmPatterns := []string{
-			filepath.Join(appData, "nvm", "*", "node_modules", "@github", "copilot", "npm-loader.js"),
-		}
-		for _, pattern := range nvmPatterns {
-			matches, _ := filepath.Glob(pattern)
-			if len(matches) > 0 {
-				// Use the newest version (last in sorted order)
-				return &CopilotPath{Path: matches[len(matches)-1], IsNode: true}, nil
-			}
-		}
-
-		// Check Program Files nodejs location
-		npmLoader := filepath.Join("C:", "Program Files", "nodejs", "node_modules", "@github", "copilot", "npm-loader.js")
-		if _, err := os.Stat(npmLoader); err == nil {
-			return &CopilotPath{Path: npmLoader, IsNode: true}, nil
-		}
 
-		// Priority list of known installation locations (.exe files)
-		candidates := []string{
-			// Direct installation
-			filepath.Join(home, "AppData", "Local", "Programs", "copilot-cli", "copilot.exe"),
+		// Check appdata nvm location, newest version (last in sorted order) first
+		matches, _ := filepath.Glob(filepath.Join(appData, "nvm", "*", "node_modules", "@github", "copilot", "npm-loader.js"))
+		for i := len(matches) - 1; i >= 0; i-- {
+			node(matches[i])
 		}
 
-		// Check WinGet installation path with glob
-		wingetPattern := filepath.Join(home, "AppData", "Local", "Microsoft", "WinGet", "Packages", "GitHub.Copilot_*", "copilot.exe")
-		matches, _ := filepath.Glob(wingetPattern)
-		candidates = append(candidates, matches...)
-
-		for _, candidate := range candidates {
-			candidate = filepath.Clean(candidate)
-			if _, err := os.Stat(candidate); err == nil { // #nosec G703 -- known install paths
-				return &CopilotPath{Path: candidate, IsNode: false}, nil
-			}
-		}
-	} else {
-		// macOS/Linux
-		home, _ := os.UserHomeDir()
+		// Check Program Files nodejs location
+		node(filepath.Join("C:", "Program Files", "nodejs", "node_modules", "@github", "copilot", "npm-loader.js"))
 
-		// Check npm global node_modules first (most reliable)
-		npmGlobalPaths := []string{
-			// Standard npm global location
-			"/usr/local/lib/node_modules/@github/copilot/npm-loader.js",
-			"/usr/lib/node_modules/@github/copilot/npm-loader.js",
-			// User-local npm global
-			filepath.Join(home, ".npm-global", "lib", "node_modules", "@github", "copilot", "npm-loader.js"),
-		}
+		// Known installation locations (.exe files): direct installation, then WinGet
+		binary(filepath.Join(home, "AppData", "Local", "Programs", "copilot-cli", "copilot.exe"))
+		wingetMatches, _ := filepath.Glob(filepath.Join(home, "AppData", "Local", "Microsoft", "WinGet", "Packages", "GitHub.Copilot_*", "copilot.exe"))
+		binary(wingetMatches...)
 
-		// nvm locations - check current node version via NVM_BIN
-		nvmBin := os.Getenv("NVM_BIN")
-		if nvmBin != "" {
-			// NVM_BIN is like ~/.nvm/versions/node/v20.x.x/bin, node_modules is sibling to bin
-			nodeVersionDir := filepath.Dir(nvmBin)
-			npmLoader := filepath.Join(nodeVersionDir, "lib", "node_modules", "@github", "copilot", "npm-loader.js")
-			npmGlobalPaths = append([]string{npmLoader}, npmGlobalPaths...)
-		}
+		return candidates
+	}
 
-		// fnm (Fast Node Manager) locations
-		fnmDir := os.Getenv("FNM_MULTISHELL_PATH")
-		if fnmDir != "" {
-			npmLoader := filepath.Join(fnmDir, "lib", "node_modules", "@github", "copilot", "npm-loader.js")
-			npmGlobalPaths = append([]string{npmLoader}, npmGlobalPaths...)
-		}
+	// macOS/Linux
+	home, _ := os.UserHomeDir()
 
-		// Homebrew on macOS
-		if runtime.GOOS == "darwin" {
-			brewPaths := []string{
-				"/opt/homebrew/lib/node_modules/@github/copilot/npm-loader.js",
-				"/usr/local/lib/node_modules/@github/copilot/npm-loader.js",
-			}
-			npmGlobalPaths = append(npmGlobalPaths, brewPaths...)
-		}
+	// fnm (Fast Node Manager) locations
+	if fnmDir := os.Getenv("FNM_MULTISHELL_PATH"); fnmDir != "" {
+		node(filepath.Join(fnmDir, "lib", "node_modules", "@github", "copilot", "npm-loader.js"))
+	}
 
-		for _, npmLoader := range npmGlobalPaths {
-			npmLoader = filepath.Clean(npmLoader)
-			if _, err := os.Stat(npmLoader); err == nil { // #nosec G703 -- known npm paths
-				return &CopilotPath{Path: npmLoader, IsNode: true}, nil
-			}
-		}
+	// nvm locations - check current node version via NVM_BIN
+	if nvmBin := os.Getenv("NVM_BIN"); nvmBin != "" {
+		// NVM_BIN is like ~/.nvm/versions/node/v20.x.x/bin, node_modules is sibling to bin
+		node(filepath.Join(filepath.Dir(nvmBin), "lib", "node_modules", "@github", "copilot", "npm-loader.js"))
+	}
 
-		// Binary candidates
-		candidates := []string{
-			"/usr/local/bin/copilot",
-			filepath.Join(home, ".local", "bin", "copilot"),
-		}
+	// Check npm global node_modules (most reliable)
+	node(
+		// Standard npm global location
+		"/usr/local/lib/node_modules/@github/copilot/npm-loader.js",
+		"/usr/lib/node_modules/@github/copilot/npm-loader.js",
+		// User-local npm global
+		filepath.Join(home, ".npm-global", "lib", "node_modules", "@github", "copilot", "npm-loader.js"),
+	)
 
-		for _, candidate := range candidates {
-			if _, err := os.Stat(candidate); err == nil {
-				return &CopilotPath{Path: candidate, IsNode: false}, nil
-			}
-		}
+	// Homebrew on macOS
+	if runtime.GOOS == "darwin" {
+		node(
+			"/opt/homebrew/lib/node_modules/@github/copilot/npm-loader.js",
+			"/usr/local/lib/node_modules/@github/copilot/npm-loader.js",
+		)
 	}
 
-	// Fall back to PATH lookup (may find .cmd/.bat but that's last resort)
-	// Skip .ps1 files - they cause file lock issues on Windows and aren't cross-platform
-	if path, err := exec.LookPath("copilot"); err == nil {
-		if !strings.HasSuffix(strings.ToLower(path), ".ps1") {
-			return &CopilotPath{Path: path, IsNode: false}, nil
-		}
-	}
+	// Binary candidates
+	binary(
+		"/usr/local/bin/copilot",
+		filepath.Join(home, ".local", "bin", "copilot"),
+	)
 
-	installHint := "npm install -g @github/copilot"
-	if runtime.GOOS == "windows" {
-		installHint = "winget install GitHub.Copilot"
-	}
-	return nil, fmt.Errorf("GitHub Copilot CLI not found. Install with: %s", installHint)
+	return candidates
 }
 
 // IsCopilotInstalled checks if Copilot CLI is available
@@ -364,15 +416,21 @@ func IsCopilotInstalled() bool {
 	return err == nil
 }
 
+// defaultAgent returns the agent to launch, falling back to azure-manager
+func defaultAgent(agent string) string {
+	if agent == "" {
+		return "azure-manager"
+	}
+	return agent
+}
+
 func buildArgs(opts Options) []string {
 	args := make([]string, 0, 10)
 
 	// Agent (default to azure-manager)
-	agent := opts.Agent
-	if agent == "" {
-		agent = "azure-manager"
+	if !opts.NoAgent {
+		args = append(args, "--agent", defaultAgent(opts.Agent))
 	}
-	args = append(args, "--agent", agent)
 
 	// Prompt
 	if opts.Prompt != "" {
@@ -390,6 +448,16 @@ func buildArgs(opts Options) []string {
 	// Auto-approve
 	if opts.Yolo {
 		args = append(args, "--yolo")
+	} else if opts.AllowAllTools {
+		args = append(args, "--allow-all-tools")
+	}
+
+	// Tool permissions (deny rules take precedence in Copilot CLI)
+	for _, tool := range opts.AllowTools {
+		args = append(args, "--allow-tool", tool)
+	}
+	for _, tool := range opts.DenyTools {
+		args = append(args, "--deny-tool", tool)
 	}
 
 	// Model
@@ -402,6 +470,14 @@ func buildArgs(opts Options) []string {
 		args = append(args, "--add-dir", dir)
 	}
 
+	// Project-scoped MCP servers
+	if opts.AdditionalMCPConfig != "" {
+		args = append(args, "--additional-mcp-config", "@"+opts.AdditionalMCPConfig)
+	}
+	for _, name := range opts.DisableMCPServers {
+		args = append(args, "--disable-mcp-server", name)
+	}
+
 	// Verbose
 	if opts.Verbose {
 		args = append(args, "--verbose")
@@ -440,6 +516,10 @@ func buildEnv(opts Options) []string {
 			env = append(env, fmt.Sprintf("AZD_SERVICE_DETAILS=%s", strings.Join(serviceDetails, ";")))
 		}
 
+		if len(opts.ProjectContext.Skills) > 0 {
+			env = append(env, fmt.Sprintf("AZD_SUGGESTED_SKILLS=%s", strings.Join(opts.ProjectContext.Skills, ",")))
+		}
+
 		// Include Azure account info
 		if opts.ProjectContext.AzureAccount != nil {
 			acct := opts.ProjectContext.AzureAccount
@@ -471,203 +551,106 @@ func buildEnv(opts Options) []string {
 			}
 		}
 
-		// Include environment variables (AZURE_* from azd env)
+		// Include environment variables (AZURE_* from azd env). Secrets are
+		// withheld from the session unless --show-secrets is set.
+		r := redact.Load()
 		for k, v := range opts.ProjectContext.Environment {
-			if strings.HasPrefix(k, "AZURE_") {
-				env = append(env, fmt.Sprintf("%s=%s", k, v))
+			if !strings.HasPrefix(k, "AZURE_") {
+				continue
+			}
+			if !r.Show && r.IsSecret(k, v) {
+				continue
 			}
+			env = append(env, fmt.Sprintf("%s=%s", k, v))
 		}
 	}
 
 	return env
 }
 
-// ConfigureMCPServer ensures Azure and azd MCP servers are configured in ~/.copilot/mcp-config.json
-// This is for REGISTERING external MCP servers that GitHub Copilot CLI will use
-func ConfigureMCPServer() error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-
-	copilotDir := filepath.Join(home, ".copilot")
-	configPath := filepath.Join(copilotDir, "mcp-config.json")
-
-	// Create .copilot directory if needed
-	if err := fileutil.EnsureDir(copilotDir); err != nil {
-		return fmt.Errorf("failed to create .copilot directory: %w", err)
-	}
-
-	// Required MCP servers
-	requiredServers := map[string]string{
-		"azure": `{
-      "type": "local",
-      "command": "npx",
-      "args": ["-y", "@azure/mcp@latest", "server", "start"],
-      "tools": ["*"]
-    }`,
-		"azd": `{
-      "type": "local",
-      "command": "azd",
-      "args": ["mcp", "server"],
-      "tools": ["*"]
-    }`,
-		"microsoft-learn": `{
-      "type": "local",
-      "command": "npx",
-      "args": ["-y", "@anthropic/mcp-microsoft-learn@latest"],
-      "tools": ["*"]
-    }`,
-		"context7": `{
-      "type": "local",
-      "command": "npx",
-      "args": ["-y", "@upstash/context7-mcp@latest"],
-      "tools": ["*"]
-    }`,
-		"azd-app": `{
-      "type": "local",
-      "command": "azd",
-      "args": ["copilot", "mcp", "serve"],
-      "tools": ["*"]
-    }`,
-		"playwright": `{
-      "type": "local",
-      "command": "npx",
-      "args": ["-y", "@playwright/mcp@latest"],
-      "tools": ["*"]
-    }`,
-	}
-
-	// Read existing config
-	existingConfig, err := os.ReadFile(configPath) //nolint:gosec // G304: configPath is constructed from home directory
-
-	// Check which servers are missing
-	var missingServers []string
-	for name := range requiredServers {
-		if err != nil || !strings.Contains(string(existingConfig), `"`+name+`"`) {
-			missingServers = append(missingServers, name)
-		}
-	}
+// RequiredMCPServers returns the names of the MCP servers azd copilot registers, sorted.
+func RequiredMCPServers() []string {
+	return mcpconfig.Builtins()
+}
 
-	// All servers present
-	if len(missingServers) == 0 {
-		return nil
-	}
-
-	// If no existing config, create new one
-	if err != nil || len(existingConfig) < 10 {
-		mcpConfig := `{
-  "mcpServers": {
-    "azure": {
-      "type": "local",
-      "command": "npx",
-      "args": ["-y", "@azure/mcp@latest", "server", "start"],
-      "tools": ["*"]
-    },
-    "azd": {
-      "type": "local",
-      "command": "azd",
-      "args": ["mcp", "server"],
-      "tools": ["*"]
-    },
-    "microsoft-learn": {
-      "type": "local",
-      "command": "npx",
-      "args": ["-y", "@anthropic/mcp-microsoft-learn@latest"],
-      "tools": ["*"]
-    },
-    "context7": {
-      "type": "local",
-      "command": "npx",
-      "args": ["-y", "@upstash/context7-mcp@latest"],
-      "tools": ["*"]
-    },
-    "azd-app": {
-      "type": "local",
-      "command": "azd",
-      "args": ["copilot", "mcp", "serve"],
-      "tools": ["*"]
-    },
-    "playwright": {
-      "type": "local",
-      "command": "npx",
-      "args": ["-y", "@playwright/mcp@latest"],
-      "tools": ["*"]
-    }
-  }
+// MCPConfigPath returns the path to ~/.copilot/mcp-config.json.
+func MCPConfigPath() (string, error) {
+	return mcpconfig.DefaultPath()
 }
-`
-		if err := fileutil.AtomicWriteFile(configPath, []byte(mcpConfig), 0644); err != nil {
-			return fmt.Errorf("failed to write mcp-config.json: %w", err)
-		}
-		return nil
-	}
 
-	// Existing config exists - merge in missing servers
-	var config map[string]interface{}
-	if err := json.Unmarshal(existingConfig, &config); err != nil {
-		fmt.Printf("   Note: Add these MCP servers to ~/.copilot/mcp-config.json: %s\n", strings.Join(missingServers, ", "))
-		return nil //nolint:nilerr // intentionally return nil when config cannot be parsed; user is notified via stdout
+// CheckMCPConfig parses ~/.copilot/mcp-config.json and returns the required
+// servers it does not define, excluding ones the user disabled or removed.
+// It returns an error if the file is missing or invalid.
+func CheckMCPConfig() ([]string, error) {
+	configPath, err := MCPConfigPath()
+	if err != nil {
+		return nil, err
 	}
-
-	servers, ok := config["mcpServers"].(map[string]interface{})
-	if !ok {
-		servers = make(map[string]interface{})
-		config["mcpServers"] = servers
+	if _, err := os.Stat(configPath); err != nil {
+		return nil, err
 	}
 
-	for _, name := range missingServers {
-		var serverConfig interface{}
-		if err := json.Unmarshal([]byte(requiredServers[name]), &serverConfig); err != nil {
-			continue
-		}
-		servers[name] = serverConfig
+	store, err := mcpconfig.OpenAt(configPath)
+	if err != nil {
+		return nil, err
 	}
+	return store.Missing(), nil
+}
 
-	updated, err := json.MarshalIndent(config, "", "  ")
+// ConfigureMCPServer ensures Azure and azd MCP servers are configured in ~/.copilot/mcp-config.json
+// This is for REGISTERING external MCP servers that GitHub Copilot CLI will use
+func ConfigureMCPServer() error {
+	store, err := mcpconfig.Open()
 	if err != nil {
-		return fmt.Errorf("failed to marshal mcp-config.json: %w", err)
+		return err
 	}
+	_, err = store.Sync()
+	return err
+}
 
-	if err := fileutil.AtomicWriteFile(configPath, append(updated, '\n'), 0644); err != nil {
-		return fmt.Errorf("failed to write mcp-config.json: %w", err)
-	}
+// Extension describes an azd extension that azd copilot depends on
+type Extension struct {
+	ID     string
+	Name   string
+	Source string
+}
 
-	return nil
+// RequiredExtensions lists the azd extensions azd copilot installs on demand
+var RequiredExtensions = []Extension{
+	{ID: "jongio.azd.app", Name: "App Extension", Source: "app"},
+	{ID: "jongio.azd.exec", Name: "Exec Extension", Source: "azd-exec"},
 }
 
-// EnsureExtensionsInstalled checks and installs required azd extensions
-func EnsureExtensionsInstalled() error {
-	extensions := []struct {
-		id     string
-		name   string
-		source string
-	}{
-		{"jongio.azd.app", "App Extension", "app"},
-		{"jongio.azd.exec", "Exec Extension", "azd-exec"},
-	}
-
-	for _, ext := range extensions {
-		// Check if extension is installed using a quick timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		cmd := exec.CommandContext(ctx, "azd", "extension", "show", ext.id) //nolint:gosec // G204: command is hardcoded "azd"
-		cmd.Stdout = nil
-		cmd.Stderr = nil
-		err := cmd.Run()
-		cancel()
+// IsExtensionInstalled reports whether an azd extension is installed, using a quick timeout
+func IsExtensionInstalled(ctx context.Context, id string) bool {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "azd", "extension", "show", id) //nolint:gosec // G204: command is hardcoded "azd"
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	return cmd.Run() == nil
+}
 
+// EnsureExtensionsInstalled checks and installs required azd extensions.
+// It returns the IDs of extensions it installed; failures are joined into err.
+func EnsureExtensionsInstalled(ctx context.Context) ([]string, error) {
+	var installed []string
+	var errs []error
+	for _, ext := range RequiredExtensions {
+		if IsExtensionInstalled(ctx, ext.ID) {
+			continue
+		}
+		// Extension not installed or check timed out, try to install
+		installCtx, installCancel := context.WithTimeout(ctx, 60*time.Second)
+		installCmd := exec.CommandContext(installCtx, "azd", "extension", "install", ext.ID, "--source", ext.Source, "--no-prompt") //nolint:gosec // G204: command is hardcoded "azd"
+		out, err := installCmd.CombinedOutput()
+		installCancel()
 		if err != nil {
-			// Extension not installed or check timed out, try to install
-			fmt.Printf("📦 Installing %s...\n", ext.name)
-			installCtx, installCancel := context.WithTimeout(context.Background(), 60*time.Second)
-			installCmd := exec.CommandContext(installCtx, "azd", "extension", "install", ext.id, "--source", ext.source, "--no-prompt") //nolint:gosec // G204: command is hardcoded "azd"
-			installCmd.Stdout = nil
-			installCmd.Stderr = nil
-			// Silently skip errors - extension might already be installed or source not configured
-			_ = installCmd.Run()
-			installCancel()
+			errs = append(errs, fmt.Errorf("failed to install %s: %w: %s", ext.ID, err, strings.TrimSpace(string(out))))
+			continue
 		}
+		installed = append(installed, ext.ID)
 	}
 
-	return nil
+	return installed, errors.Join(errs...)
 }
@@ -4,9 +4,15 @@
 package copilot
 
 import (
+	"errors"
 	"os"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/audit"
+	"github.com/jongio/azd-copilot/cli/src/internal/redact"
 )
 
 func TestOptions_Defaults(t *testing.T) {
@@ -98,6 +104,28 @@ func TestBuildArgs(t *testing.T) {
 			},
 			contains: []string{"--verbose"},
 		},
+		{
+			name: "with tool permissions",
+			opts: Options{
+				AllowTools: []string{"shell(azd provision --preview)"},
+				DenyTools:  []string{"shell(az group delete)"},
+			},
+			contains: []string{
+				"--allow-tool", "shell(azd provision --preview)",
+				"--deny-tool", "shell(az group delete)",
+			},
+		},
+		{
+			name: "with project MCP servers",
+			opts: Options{
+				AdditionalMCPConfig: "/tmp/session.json",
+				DisableMCPServers:   []string{"playwright"},
+			},
+			contains: []string{
+				"--additional-mcp-config", "@/tmp/session.json",
+				"--disable-mcp-server", "playwright",
+			},
+		},
 		{
 			name: "full options",
 			opts: Options{
@@ -233,6 +261,19 @@ func TestBuildEnv(t *testing.T) {
 				"AZD_HAS_BICEP=true",
 			},
 		},
+		{
+			name: "with suggested skills",
+			opts: Options{
+				ProjectContext: &ProjectContext{
+					Name:   "myproject",
+					Path:   "/path",
+					Skills: []string{"azure-functions", "avm-bicep-rules"},
+				},
+			},
+			contains: []string{
+				"AZD_SUGGESTED_SKILLS=azure-functions,avm-bicep-rules",
+			},
+		},
 		{
 			name: "with azure environment vars",
 			opts: Options{
@@ -296,6 +337,33 @@ func TestBuildEnv_ExcludesNonAzureVars(t *testing.T) {
 	}
 }
 
+func TestBuildEnv_WithholdsSecrets(t *testing.T) {
+	t.Chdir(t.TempDir())
+	opts := Options{
+		ProjectContext: &ProjectContext{
+			Environment: map[string]string{
+				"AZURE_LOCATION":              "eastus",
+				"AZURE_STORAGE_KEY":           "abc123",
+				"AZURE_STORAGE_CONNECTION":    "AccountName=st;AccountKey=abc123==",
+				"AZURE_COSMOS_ACCOUNT_SECRET": "abc123",
+			},
+		},
+	}
+
+	env := strings.Join(buildEnv(opts), "\n")
+	if !strings.Contains(env, "AZURE_LOCATION=eastus") {
+		t.Errorf("buildEnv() dropped a plain value:\n%s", env)
+	}
+	if strings.Contains(env, "abc123") {
+		t.Errorf("buildEnv() forwarded a secret:\n%s", env)
+	}
+
+	t.Setenv(redact.ShowSecretsEnv, "true")
+	if env := strings.Join(buildEnv(opts), "\n"); !strings.Contains(env, "AZURE_STORAGE_KEY=abc123") {
+		t.Errorf("buildEnv() with --show-secrets should forward secrets:\n%s", env)
+	}
+}
+
 func TestCopilotPath_Fields(t *testing.T) {
 	cp := CopilotPath{
 		Path:   "/usr/local/bin/copilot",
@@ -451,3 +519,75 @@ func TestPlatformSpecificPaths(t *testing.T) {
 		// Check for /dev/tty path in launchViaConsole logic
 	}
 }
+
+func TestParseCLIVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"0.0.354\n", "0.0.354"},
+		{"GitHub Copilot CLI 1.2.3\nCommit: abc123\n", "1.2.3"},
+		{"1.0.0-beta.2", "1.0.0-beta.2"},
+		{"unknown", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseCLIVersion(tt.output); got != tt.want {
+			t.Errorf("parseCLIVersion(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestCheckMCPConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	if _, err := CheckMCPConfig(); err == nil {
+		t.Error("CheckMCPConfig() should fail when the config does not exist")
+	}
+
+	if err := ConfigureMCPServer(); err != nil {
+		t.Fatalf("ConfigureMCPServer() error = %v", err)
+	}
+	missing, err := CheckMCPConfig()
+	if err != nil {
+		t.Fatalf("CheckMCPConfig() error = %v", err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("CheckMCPConfig() missing = %v, want none", missing)
+	}
+}
+
+func TestRecordLaunch(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("azure.yaml", []byte("name: app\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{Command: "azd copilot build", Prompt: "build a todo app", Agent: "azure-manager", Yolo: true}
+	recordLaunch(opts, time.Now(), nil)
+	recordLaunch(Options{}, time.Now(), errors.New("exit status 1"))
+
+	events, err := audit.Read(audit.Filter{Kinds: []audit.Kind{audit.KindLaunch}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("audited %d launches, want 2", len(events))
+	}
+	e := events[0]
+	if e.Name != "azd copilot build" || e.Args["agent"] != "azure-manager" || e.Args["yolo"] != true {
+		t.Errorf("launch = %+v", e)
+	}
+	if e.Args["promptHash"] != audit.Hash(opts.Prompt) {
+		t.Errorf("promptHash = %v, want %s", e.Args["promptHash"], audit.Hash(opts.Prompt))
+	}
+	data, _ := os.ReadFile(audit.DefaultFile)
+	if strings.Contains(string(data), opts.Prompt) {
+		t.Error("audit log must not contain the prompt")
+	}
+	if events[1].Name != "azd copilot" || events[1].Outcome != audit.OutcomeError {
+		t.Errorf("failed launch = %+v", events[1])
+	}
+}
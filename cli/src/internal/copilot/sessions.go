@@ -0,0 +1,37 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package copilot
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LatestSessionID returns the Copilot CLI session most recently written at
+// or after since, or "" when there is none
+func LatestSessionID(since time.Time) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	entries, err := os.ReadDir(filepath.Join(home, ".copilot", "session-state"))
+	if err != nil {
+		return ""
+	}
+
+	var id string
+	var newest time.Time
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().Before(since) || !info.ModTime().After(newest) {
+			continue
+		}
+		id, newest = entry.Name(), info.ModTime()
+	}
+	return id
+}
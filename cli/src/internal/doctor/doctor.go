@@ -0,0 +1,263 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package doctor diagnoses the local environment azd copilot depends on.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/assets"
+	"github.com/jongio/azd-copilot/cli/src/internal/copilot"
+)
+
+// Status is the outcome of a single diagnostic check
+type Status string
+
+// StatusOK through StatusFail represent check outcomes.
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Check is the result of a single diagnostic
+type Check struct {
+	Name   string      `json:"name"`
+	Status Status      `json:"status"`
+	Detail string      `json:"detail"`
+	Fix    string      `json:"fix,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+}
+
+// Report holds the results of all diagnostics
+type Report struct {
+	Checks []Check `json:"checks"`
+}
+
+// Failures returns the number of failed checks
+func (r *Report) Failures() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == StatusFail {
+			n++
+		}
+	}
+	return n
+}
+
+// toolVersion runs `<name> --version` and returns the trimmed first line.
+// It is a variable so tests can stub out process execution.
+var toolVersion = func(ctx context.Context, name string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, "--version").Output() //nolint:gosec // G204: name is one of a fixed set of tools
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// extensionInstalled reports whether an azd extension is installed.
+// It is a variable so tests can stub out process execution.
+var extensionInstalled = copilot.IsExtensionInstalled
+
+// Run executes every diagnostic check
+func Run(ctx context.Context) *Report {
+	report := &Report{}
+
+	cliCheck, copilotPath := checkCopilotCLI()
+	report.Checks = append(report.Checks, cliCheck)
+	report.Checks = append(report.Checks, checkNodeTools(ctx)...)
+	report.Checks = append(report.Checks,
+		checkCopilotVersion(ctx, copilotPath),
+		checkMCPConfig(),
+		checkAgents(),
+		checkSkills(),
+	)
+	report.Checks = append(report.Checks, checkExtensions(ctx)...)
+	report.Checks = append(report.Checks, checkConsole())
+
+	return report
+}
+
+func checkCopilotCLI() (Check, *copilot.CopilotPath) {
+	candidates, found := copilot.ProbeCopilotCLI()
+	check := Check{Name: "Copilot CLI", Data: candidates}
+
+	if found == nil {
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("not found in %d candidate locations", len(candidates))
+		check.Fix = fmt.Sprintf("Install with: %s", copilot.InstallHint())
+		return check, nil
+	}
+
+	check.Status = StatusOK
+	check.Detail = found.Path
+	if found.IsNode {
+		check.Detail += " (via node)"
+	}
+	return check, found
+}
+
+func checkNodeTools(ctx context.Context) []Check {
+	checks := make([]Check, 0, 3)
+	for _, tool := range []string{"node", "npm", "npx"} {
+		check := Check{Name: tool}
+		version, err := toolVersion(ctx, tool)
+		if err != nil {
+			// npx-based MCP servers need node, but a standalone Copilot binary does not
+			check.Status = StatusWarn
+			check.Detail = fmt.Sprintf("not available: %v", err)
+			check.Fix = "Install Node.js 22+ from https://nodejs.org (required for npx-based MCP servers)"
+		} else {
+			check.Status = StatusOK
+			check.Detail = version
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func checkCopilotVersion(ctx context.Context, copilotPath *copilot.CopilotPath) Check {
+	check := Check{Name: "Copilot CLI version"}
+	if copilotPath == nil {
+		check.Status = StatusFail
+		check.Detail = "skipped: Copilot CLI not found"
+		check.Fix = fmt.Sprintf("Install with: %s", copilot.InstallHint())
+		return check
+	}
+
+	version, err := copilot.DetectCLIVersion(ctx, copilotPath)
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		check.Fix = "Reinstall with: npm install -g @github/copilot@latest"
+		return check
+	}
+
+	if missing := copilot.Unsupported(version); len(missing) > 0 {
+		flags := make([]string, 0, len(missing))
+		for _, r := range missing {
+			flags = append(flags, fmt.Sprintf("%s (%s+)", r.Flag, r.MinVersion))
+		}
+		check.Status = StatusWarn
+		check.Detail = fmt.Sprintf("%s does not support %s", version, strings.Join(flags, ", "))
+		check.Fix = "Upgrade with: npm install -g @github/copilot@latest"
+		check.Data = missing
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Detail = version
+	return check
+}
+
+func checkMCPConfig() Check {
+	check := Check{Name: "MCP config"}
+	configPath, err := copilot.MCPConfigPath()
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		return check
+	}
+
+	missing, err := copilot.CheckMCPConfig()
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("%s does not exist", configPath)
+		check.Fix = "Run: azd copilot mcp configure"
+	case err != nil:
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		check.Fix = fmt.Sprintf("Fix the JSON syntax in %s, or move it aside and run: azd copilot mcp configure", configPath)
+	case len(missing) > 0:
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("missing servers: %s", strings.Join(missing, ", "))
+		check.Fix = "Run: azd copilot mcp configure"
+		check.Data = missing
+	default:
+		check.Status = StatusOK
+		check.Detail = fmt.Sprintf("%s defines all %d required servers", configPath, len(copilot.RequiredMCPServers()))
+	}
+	return check
+}
+
+func checkAgents() Check {
+	status, err := assets.CheckAgents()
+	return installCheck("Agents", status, err)
+}
+
+func checkSkills() Check {
+	status, err := assets.CheckSkills()
+	return installCheck("Skills", status, err)
+}
+
+func installCheck(name string, status *assets.InstallStatus, err error) Check {
+	check := Check{Name: name}
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		return check
+	}
+
+	check.Data = status
+	switch {
+	case len(status.Missing) == status.Files:
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("not installed in %s", status.Dir)
+		check.Fix = "Run: azd copilot setup --force"
+	case !status.UpToDate():
+		check.Status = StatusWarn
+		check.Detail = fmt.Sprintf("%d missing, %d differ from embedded versions in %s", len(status.Missing), len(status.Modified), status.Dir)
+		check.Fix = "Run: azd copilot setup --force"
+		if len(status.Modified) > 0 {
+			// Setup preserves local edits, so they must be moved or deleted first
+			check.Fix = "Move local edits to ~/.azd/copilot/custom, delete the edited files, then run: azd copilot setup --force"
+		}
+	default:
+		check.Status = StatusOK
+		check.Detail = fmt.Sprintf("%d files match embedded versions", status.Files)
+	}
+	return check
+}
+
+func checkExtensions(ctx context.Context) []Check {
+	checks := make([]Check, 0, len(copilot.RequiredExtensions))
+	for _, ext := range copilot.RequiredExtensions {
+		check := Check{Name: ext.ID}
+		if extensionInstalled(ctx, ext.ID) {
+			check.Status = StatusOK
+			check.Detail = "installed"
+		} else {
+			check.Status = StatusWarn
+			check.Detail = "not installed"
+			check.Fix = fmt.Sprintf("Run: azd extension install %s --source %s", ext.ID, ext.Source)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func checkConsole() Check {
+	check := Check{Name: "Terminal"}
+	name, err := copilot.CheckConsole()
+	if err != nil {
+		// Non-interactive use (-p) still works without a console
+		check.Status = StatusWarn
+		check.Detail = fmt.Sprintf("%s is not usable: %v", name, err)
+		check.Fix = "Run azd copilot from an interactive terminal, or use -p for non-interactive prompts"
+		return check
+	}
+	check.Status = StatusOK
+	check.Detail = fmt.Sprintf("%s is usable", name)
+	return check
+}
@@ -0,0 +1,154 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package doctor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/assets"
+)
+
+func setHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	return home
+}
+
+func writeMCPConfig(t *testing.T, home, content string) {
+	t.Helper()
+	dir := filepath.Join(home, ".copilot")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mcp-config.json"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckMCPConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Status
+	}{
+		{"missing file", "", StatusFail},
+		{"invalid json", `{"mcpServers": {`, StatusFail},
+		{"missing servers", `{"mcpServers": {"azure": {}}}`, StatusFail},
+		{
+			"all servers",
+			`{"mcpServers": {"azure": {}, "azd": {}, "microsoft-learn": {}, "context7": {}, "azd-app": {}, "playwright": {}}}`,
+			StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := setHome(t)
+			if tt.content != "" {
+				writeMCPConfig(t, home, tt.content)
+			}
+
+			check := checkMCPConfig()
+			if check.Status != tt.want {
+				t.Errorf("checkMCPConfig().Status = %q, want %q (%s)", check.Status, tt.want, check.Detail)
+			}
+			if check.Status == StatusFail && check.Fix == "" {
+				t.Error("failed check should suggest a fix")
+			}
+		})
+	}
+}
+
+func TestCheckAgents(t *testing.T) {
+	setHome(t)
+
+	if check := checkAgents(); check.Status != StatusFail {
+		t.Errorf("checkAgents() before install = %q, want fail", check.Status)
+	}
+
+	report, err := assets.InstallAgents()
+	if err != nil {
+		t.Fatalf("InstallAgents() error = %v", err)
+	}
+	if check := checkAgents(); check.Status != StatusOK {
+		t.Errorf("checkAgents() after install = %q, want ok (%s)", check.Status, check.Detail)
+	}
+
+	if err := os.WriteFile(filepath.Join(report.Dir, "azure-manager.md"), []byte("edited"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	check := checkAgents()
+	if check.Status != StatusWarn {
+		t.Errorf("checkAgents() after local edit = %q, want warn", check.Status)
+	}
+	if check.Fix == "" {
+		t.Error("warn check should suggest a fix")
+	}
+}
+
+func TestCheckNodeTools(t *testing.T) {
+	orig := toolVersion
+	defer func() { toolVersion = orig }()
+	toolVersion = func(ctx context.Context, name string) (string, error) {
+		if name == "npx" {
+			return "", errors.New("executable file not found")
+		}
+		return "v22.0.0", nil
+	}
+
+	checks := checkNodeTools(context.Background())
+	if len(checks) != 3 {
+		t.Fatalf("checkNodeTools() returned %d checks, want 3", len(checks))
+	}
+	if checks[0].Status != StatusOK || checks[0].Detail != "v22.0.0" {
+		t.Errorf("node check = %+v, want ok v22.0.0", checks[0])
+	}
+	if checks[2].Status != StatusWarn || checks[2].Fix == "" {
+		t.Errorf("npx check = %+v, want warn with fix", checks[2])
+	}
+}
+
+func TestCheckExtensions(t *testing.T) {
+	orig := extensionInstalled
+	defer func() { extensionInstalled = orig }()
+	extensionInstalled = func(ctx context.Context, id string) bool {
+		return id == "jongio.azd.app"
+	}
+
+	checks := checkExtensions(context.Background())
+	if len(checks) != 2 {
+		t.Fatalf("checkExtensions() returned %d checks, want 2", len(checks))
+	}
+	if checks[0].Status != StatusOK {
+		t.Errorf("%s = %q, want ok", checks[0].Name, checks[0].Status)
+	}
+	if checks[1].Status != StatusWarn || checks[1].Fix == "" {
+		t.Errorf("%s = %+v, want warn with fix", checks[1].Name, checks[1])
+	}
+}
+
+func TestCheckCopilotVersion_NotFound(t *testing.T) {
+	check := checkCopilotVersion(context.Background(), nil)
+	if check.Status != StatusFail {
+		t.Errorf("checkCopilotVersion(nil).Status = %q, want fail", check.Status)
+	}
+}
+
+func TestReport_Failures(t *testing.T) {
+	r := &Report{Checks: []Check{
+		{Status: StatusOK},
+		{Status: StatusWarn},
+		{Status: StatusFail},
+		{Status: StatusFail},
+	}}
+	if got := r.Failures(); got != 2 {
+		t.Errorf("Failures() = %d, want 2", got)
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package mcpconfig
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultCheckTimeout bounds how long a single server may take to start,
+// initialize, and list its tools
+const DefaultCheckTimeout = 30 * time.Second
+
+// CheckStatus is the outcome of a server health check
+type CheckStatus string
+
+// CheckOK through CheckSkipped represent health check outcomes.
+const (
+	CheckOK      CheckStatus = "ok"
+	CheckFail    CheckStatus = "fail"
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// CheckResult describes one server health check
+type CheckResult struct {
+	Name            string        `json:"name"`
+	Source          string        `json:"source"`
+	Status          CheckStatus   `json:"status"`
+	Latency         time.Duration `json:"-"`
+	LatencyMS       int64         `json:"latencyMs"`
+	ServerName      string        `json:"serverName,omitempty"`
+	ServerVersion   string        `json:"serverVersion,omitempty"`
+	ProtocolVersion string        `json:"protocolVersion,omitempty"`
+	ToolCount       int           `json:"toolCount"`
+	Tools           []string      `json:"tools,omitempty"`
+	Error           string        `json:"error,omitempty"`
+}
+
+// SelfName is the check entry for this extension's own MCP server
+const SelfName = "azd-copilot"
+
+// SelfEntry returns an entry that runs 'mcp serve' from the given
+// executable, so the extension's own server is checked even when the
+// azd-app entry is removed or routed through a different azd install
+func SelfEntry(executable string) Entry {
+	return Entry{
+		Name:    SelfName,
+		Source:  SourceSelf,
+		Enabled: true,
+		Server:  Server{Type: "local", Command: executable, Args: []string{"mcp", "serve"}},
+	}
+}
+
+// CheckAll checks the named entries (every entry when names is empty) in
+// parallel and returns the results sorted by name. Disabled entries are
+// skipped unless named explicitly.
+func CheckAll(ctx context.Context, entries []Entry, names []string, timeout time.Duration) ([]CheckResult, error) {
+	known := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		known[e.Name] = true
+	}
+	for _, name := range names {
+		if !known[name] {
+			return nil, fmt.Errorf("MCP server %q is not configured", name)
+		}
+	}
+
+	results := make([]CheckResult, 0, len(entries))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		if len(names) > 0 && !slices.Contains(names, e.Name) {
+			continue
+		}
+		if !e.Enabled && len(names) == 0 {
+			note := "disabled"
+			if e.Note != "" {
+				note = e.Note
+			}
+			mu.Lock()
+			results = append(results, CheckResult{Name: e.Name, Source: e.Source, Status: CheckSkipped, Error: note})
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func(e Entry) {
+			defer wg.Done()
+			r := Check(ctx, e.Name, e.Server, timeout)
+			r.Source = e.Source
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+		}(e)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results, nil
+}
+
+// Check starts a server, performs the MCP initialize handshake, and lists
+// its tools. Local servers are spawned over stdio and stopped afterwards;
+// remote servers are reached over streamable HTTP or SSE.
+func Check(ctx context.Context, name string, srv Server, timeout time.Duration) (result CheckResult) {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result = CheckResult{Name: name, Status: CheckFail}
+	start := time.Now()
+	defer func() {
+		result.Latency = time.Since(start)
+		result.LatencyMS = result.Latency.Milliseconds()
+	}()
+
+	c, stderr, err := connect(ctx, srv)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer func() { _ = c.Close() }()
+
+	fail := func(step string, err error) CheckResult {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("timed out after %s", timeout)
+		}
+		result.Error = fmt.Sprintf("%s failed: %v", step, err)
+		if tail := stderr.String(); tail != "" {
+			result.Error += ": " + tail
+		}
+		return result
+	}
+
+	initResult, err := c.Initialize(ctx, mcp.InitializeRequest{
+		Params: mcp.InitializeParams{
+			ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
+			ClientInfo:      mcp.Implementation{Name: "azd-copilot", Version: "check"},
+		},
+	})
+	if err != nil {
+		return fail("initialize", err)
+	}
+	result.ServerName = initResult.ServerInfo.Name
+	result.ServerVersion = initResult.ServerInfo.Version
+	result.ProtocolVersion = initResult.ProtocolVersion
+
+	tools, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return fail("tools/list", err)
+	}
+	for _, t := range tools.Tools {
+		result.Tools = append(result.Tools, t.Name)
+	}
+	sort.Strings(result.Tools)
+	result.ToolCount = len(result.Tools)
+	result.Status = CheckOK
+	return result
+}
+
+// connect creates and starts a client for a server definition. The stdio
+// process is bound to ctx so it is killed when the check times out.
+func connect(ctx context.Context, srv Server) (*client.Client, *stderrTail, error) {
+	tail := &stderrTail{done: make(chan struct{})}
+
+	switch {
+	case srv.URL != "":
+		var c *client.Client
+		var err error
+		if srv.Type == "sse" {
+			c, err = client.NewSSEMCPClient(srv.URL, transport.WithHeaders(srv.Headers))
+		} else {
+			c, err = client.NewStreamableHttpClient(srv.URL, transport.WithHTTPHeaders(srv.Headers))
+		}
+		if err != nil {
+			return nil, tail, err
+		}
+		close(tail.done)
+		if err := c.Start(ctx); err != nil {
+			return nil, tail, fmt.Errorf("failed to connect to %s: %w", srv.URL, err)
+		}
+		return c, tail, nil
+
+	case srv.Command != "":
+		env := make([]string, 0, len(srv.Env))
+		for k, v := range srv.Env {
+			env = append(env, k+"="+v)
+		}
+		t := transport.NewStdioWithOptions(srv.Command, env, srv.Args, transport.WithCommandLogger(quietLogger{}))
+		if err := t.Start(ctx); err != nil {
+			close(tail.done)
+			return nil, tail, fmt.Errorf("failed to start %s: %w", srv.Summary(), err)
+		}
+		go tail.drain(t.Stderr())
+		return client.NewClient(t), tail, nil
+
+	default:
+		close(tail.done)
+		return nil, tail, fmt.Errorf("server has no command or url")
+	}
+}
+
+// quietLogger discards transport logs; a server exiting mid-check is
+// reported in the result rather than logged
+type quietLogger struct{}
+
+func (quietLogger) Infof(string, ...any)  {}
+func (quietLogger) Errorf(string, ...any) {}
+
+// stderrTail keeps the last few lines a server wrote to stderr so a failed
+// handshake can show why the server exited
+type stderrTail struct {
+	mu    sync.Mutex
+	lines []string
+	done  chan struct{}
+}
+
+const stderrTailLines = 3
+
+func (s *stderrTail) drain(r io.Reader) {
+	defer close(s.done)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		s.mu.Lock()
+		s.lines = append(s.lines, line)
+		if len(s.lines) > stderrTailLines {
+			s.lines = s.lines[1:]
+		}
+		s.mu.Unlock()
+	}
+}
+
+// String returns the captured lines, giving a server that just exited a
+// moment to flush its output
+func (s *stderrTail) String() string {
+	select {
+	case <-s.done:
+	case <-time.After(200 * time.Millisecond):
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return strings.Join(s.lines, " | ")
+}
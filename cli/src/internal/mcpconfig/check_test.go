@@ -0,0 +1,119 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package mcpconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fakeServerEnv makes the test binary act as a stand-in stdio MCP server
+const fakeServerEnv = "AZD_COPILOT_FAKE_MCP_SERVER"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(fakeServerEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "ok":
+		s := server.NewMCPServer("fake-mcp", "1.2.3")
+		for _, name := range []string{"search", "fetch"} {
+			s.AddTool(mcp.NewTool(name), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("ok"), nil
+			})
+		}
+		if err := server.ServeStdio(s); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "hang":
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	case "crash":
+		fmt.Fprintln(os.Stderr, "fatal: missing API key")
+		os.Exit(1)
+	}
+}
+
+func fakeServer(mode string) Server {
+	return Server{Type: "local", Command: os.Args[0], Env: map[string]string{fakeServerEnv: mode}}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		srv       Server
+		status    CheckStatus
+		errSubstr string
+	}{
+		{"ok", fakeServer("ok"), CheckOK, ""},
+		{"hang", fakeServer("hang"), CheckFail, "timed out"},
+		{"crash", fakeServer("crash"), CheckFail, "missing API key"},
+		{"missing command", Server{Command: "azd-copilot-no-such-server"}, CheckFail, "failed to start"},
+		{"empty", Server{}, CheckFail, "no command or url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Check(context.Background(), tt.name, tt.srv, 2*time.Second)
+			if r.Status != tt.status {
+				t.Fatalf("Status = %s, want %s (error: %s)", r.Status, tt.status, r.Error)
+			}
+			if tt.errSubstr != "" && !strings.Contains(r.Error, tt.errSubstr) {
+				t.Errorf("Error = %q, want it to contain %q", r.Error, tt.errSubstr)
+			}
+			if tt.status != CheckOK {
+				return
+			}
+			if r.ServerName != "fake-mcp" || r.ServerVersion != "1.2.3" {
+				t.Errorf("server = %s %s, want fake-mcp 1.2.3", r.ServerName, r.ServerVersion)
+			}
+			if r.ToolCount != 2 || strings.Join(r.Tools, ",") != "fetch,search" {
+				t.Errorf("tools = %d %v, want 2 [fetch search]", r.ToolCount, r.Tools)
+			}
+			if r.Latency <= 0 {
+				t.Error("Latency should be recorded")
+			}
+		})
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	entries := []Entry{
+		{Name: "b", Source: SourceUser, Enabled: true, Server: fakeServer("ok")},
+		{Name: "a", Source: SourceProject, Enabled: true, Server: fakeServer("crash")},
+		{Name: "c", Source: SourceBuiltin, Enabled: false, Server: fakeServer("ok")},
+	}
+
+	results, err := CheckAll(context.Background(), entries, nil, 5*time.Second)
+	if err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Name+":"+string(r.Status)+":"+r.Source)
+	}
+	want := fmt.Sprintf("a:fail:%s b:ok:%s c:skipped:%s", SourceProject, SourceUser, SourceBuiltin)
+	if strings.Join(got, " ") != want {
+		t.Errorf("CheckAll() = %v, want %s", got, want)
+	}
+
+	// A disabled server is checked when named explicitly
+	results, err = CheckAll(context.Background(), entries, []string{"c"}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Status != CheckOK {
+		t.Errorf("CheckAll(c) = %+v, want one ok result", results)
+	}
+
+	if _, err := CheckAll(context.Background(), entries, []string{"nope"}, time.Second); err == nil {
+		t.Error("CheckAll() with an unknown server should fail")
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package mcpconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-core/fileutil"
+)
+
+// LockPathEnv overrides the lock file location, e.g. to share one lock
+// file across machines that must reproduce the same scenario baseline
+const LockPathEnv = "AZD_COPILOT_MCP_LOCK"
+
+// LockEntry records the resolved package version for a server
+type LockEntry struct {
+	Package  string    `json:"package"`
+	Version  string    `json:"version"`
+	Resolved time.Time `json:"resolved"`
+}
+
+// Lock pins npx-based MCP servers to exact package versions
+type Lock struct {
+	Servers map[string]LockEntry `json:"servers"`
+}
+
+// LockChange describes a version change made by Pin
+type LockChange struct {
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	From    string `json:"from,omitempty"`
+	To      string `json:"to"`
+}
+
+// LockPathFor returns the lock file used for a config file
+func LockPathFor(configPath string) string {
+	if p := os.Getenv(LockPathEnv); p != "" {
+		return p
+	}
+	return strings.TrimSuffix(configPath, ".json") + ".lock.json"
+}
+
+func loadLock(path string) (*Lock, error) {
+	lock := &Lock{Servers: map[string]LockEntry{}}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is derived from the config path or set by the user
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if lock.Servers == nil {
+		lock.Servers = map[string]LockEntry{}
+	}
+	return lock, nil
+}
+
+// Package and version resolution shell out to npm. They are variables so
+// tests can run without network access.
+var (
+	// resolveVersion returns the current published version of an npm package
+	resolveVersion = func(ctx context.Context, pkg string) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "npm", "view", pkg, "version").Output() //nolint:gosec // G204: pkg comes from the built-in server definitions
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", pkg, err)
+		}
+		version := strings.TrimSpace(string(out))
+		if version == "" {
+			return "", fmt.Errorf("npm returned no version for %s", pkg)
+		}
+		return version, nil
+	}
+
+	// cachePackage downloads a package into the npm cache for offline use
+	cachePackage = func(ctx context.Context, spec string) error {
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		defer cancel()
+		if out, err := exec.CommandContext(ctx, "npm", "cache", "add", spec).CombinedOutput(); err != nil { //nolint:gosec // G204: spec comes from the lock file
+			return fmt.Errorf("failed to cache %s: %w: %s", spec, err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
+	// isCached reports whether a package can be installed from the npm cache alone
+	isCached = func(ctx context.Context, spec string) bool {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "npm", "exec", "--offline", "--yes", "--package", spec, "--", "node", "--version") //nolint:gosec // G204: spec comes from the lock file
+		return cmd.Run() == nil
+	}
+)
+
+// npxPackage returns the package a built-in npx server runs, without its version
+func npxPackage(def json.RawMessage) (string, bool) {
+	var srv Server
+	if err := json.Unmarshal(def, &srv); err != nil || srv.Command != "npx" {
+		return "", false
+	}
+	for _, arg := range srv.Args {
+		if pkg, ok := strings.CutSuffix(arg, "@latest"); ok {
+			return pkg, true
+		}
+	}
+	return "", false
+}
+
+// Pinnable returns the built-in servers whose package versions can be locked
+func Pinnable() []string {
+	var names []string
+	for _, name := range Builtins() {
+		if _, ok := npxPackage(json.RawMessage(builtinServers[name])); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Locked returns the lock entry for a server, if pinned
+func (s *Store) Locked(name string) (LockEntry, bool) {
+	e, ok := s.lock.Servers[name]
+	return e, ok
+}
+
+// Pin resolves and records package versions for the named servers (all
+// pinnable servers when names is empty), caches the packages for offline
+// use, and syncs the config. Without force, servers that are already
+// pinned keep their version.
+func (s *Store) Pin(ctx context.Context, names []string, force bool) ([]LockChange, error) {
+	pinnable := Pinnable()
+	if len(names) == 0 {
+		names = pinnable
+	}
+
+	var changes []LockChange
+	var errs []error
+	for _, name := range names {
+		if !slices.Contains(pinnable, name) {
+			errs = append(errs, fmt.Errorf("%q is not a built-in npx server", name))
+			continue
+		}
+		current, pinned := s.lock.Servers[name]
+		if pinned && !force {
+			continue
+		}
+
+		pkg, _ := npxPackage(json.RawMessage(builtinServers[name]))
+		version, err := resolveVersion(ctx, pkg)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := cachePackage(ctx, pkg+"@"+version); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if pinned && current.Version == version {
+			continue
+		}
+
+		s.lock.Servers[name] = LockEntry{Package: pkg, Version: version, Resolved: time.Now().UTC()}
+		changes = append(changes, LockChange{Name: name, Package: pkg, From: current.Version, To: version})
+	}
+
+	if len(changes) > 0 {
+		if err := fileutil.AtomicWriteJSON(s.LockPath, s.lock); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", s.LockPath, err)
+		}
+		if _, err := s.Sync(); err != nil {
+			return changes, err
+		}
+	}
+	return changes, errors.Join(errs...)
+}
+
+// CheckOffline verifies that every enabled npx server can start from the
+// npm cache. It returns an error naming each missing package.
+func (s *Store) CheckOffline(ctx context.Context) error {
+	var missing []string
+	for _, name := range Pinnable() {
+		if _, present := s.servers[name]; !present || !s.Owned(name) {
+			continue
+		}
+		pkg, _ := npxPackage(json.RawMessage(builtinServers[name]))
+		spec := pkg + "@latest"
+		if e, ok := s.lock.Servers[name]; ok {
+			spec = pkg + "@" + e.Version
+		}
+		if !isCached(ctx, spec) {
+			missing = append(missing, spec)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("offline mode: MCP packages are not in the npm cache: %s; run 'azd copilot mcp update' while online to pin and cache them", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// desired returns the definition Sync writes for a built-in server:
+// the embedded definition with the locked version and offline flag applied
+func (s *Store) desired(name string) json.RawMessage {
+	def := json.RawMessage(builtinServers[name])
+	e, pinned := s.lock.Servers[name]
+	if !pinned && !s.Offline {
+		return def
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(def, &fields); err != nil {
+		return def
+	}
+	rawArgs, _ := fields["args"].([]interface{})
+	args := make([]interface{}, 0, len(rawArgs)+1)
+	for _, a := range rawArgs {
+		arg, _ := a.(string)
+		if s.Offline && arg == "-y" {
+			args = append(args, "--offline")
+		}
+		if pinned && arg == e.Package+"@latest" {
+			arg = e.Package + "@" + e.Version
+		}
+		args = append(args, arg)
+	}
+	fields["args"] = args
+
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return def
+	}
+	return out
+}
@@ -0,0 +1,214 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package mcpconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubNPM replaces npm calls with a fake registry of package versions and
+// a fake npm cache.
+func stubNPM(t *testing.T, registry map[string]string) map[string]bool {
+	t.Helper()
+	origResolve, origCache, origCached := resolveVersion, cachePackage, isCached
+	t.Cleanup(func() { resolveVersion, cachePackage, isCached = origResolve, origCache, origCached })
+
+	npmCache := map[string]bool{}
+	resolveVersion = func(ctx context.Context, pkg string) (string, error) {
+		v, ok := registry[pkg]
+		if !ok {
+			return "", errors.New("E404 " + pkg)
+		}
+		return v, nil
+	}
+	cachePackage = func(ctx context.Context, spec string) error {
+		npmCache[spec] = true
+		return nil
+	}
+	isCached = func(ctx context.Context, spec string) bool {
+		return npmCache[spec]
+	}
+	return npmCache
+}
+
+var testRegistry = map[string]string{
+	"@azure/mcp":                     "1.0.0",
+	"@anthropic/mcp-microsoft-learn": "0.3.0",
+	"@upstash/context7-mcp":          "2.1.0",
+	"@playwright/mcp":                "0.0.40",
+}
+
+func serverArgs(t *testing.T, s *Store, name string) []string {
+	t.Helper()
+	def, ok := s.Get(name)
+	if !ok {
+		t.Fatalf("%s not configured", name)
+	}
+	var srv Server
+	if err := json.Unmarshal(def, &srv); err != nil {
+		t.Fatal(err)
+	}
+	return srv.Args
+}
+
+func TestPinnable(t *testing.T) {
+	got := strings.Join(Pinnable(), ",")
+	if got != "azure,context7,microsoft-learn,playwright" {
+		t.Errorf("Pinnable() = %s", got)
+	}
+}
+
+func TestPin(t *testing.T) {
+	registry := map[string]string{}
+	for k, v := range testRegistry {
+		registry[k] = v
+	}
+	npmCache := stubNPM(t, registry)
+	path := writeConfig(t, "")
+	s := mustOpen(t, path)
+	if _, err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := s.Pin(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Pin() error = %v", err)
+	}
+	if len(changes) != len(Pinnable()) {
+		t.Errorf("Pin() changed %d servers, want %d", len(changes), len(Pinnable()))
+	}
+	if !npmCache["@playwright/mcp@0.0.40"] {
+		t.Error("Pin() should cache pinned packages")
+	}
+
+	s = mustOpen(t, path)
+	if args := serverArgs(t, s, "playwright"); !strings.Contains(strings.Join(args, " "), "@playwright/mcp@0.0.40") {
+		t.Errorf("playwright args = %v, want pinned version", args)
+	}
+	if !s.Owned("playwright") {
+		t.Error("pinned playwright should remain owned")
+	}
+	if e, ok := s.Locked("playwright"); !ok || e.Version != "0.0.40" {
+		t.Errorf("Locked(playwright) = %+v, want 0.0.40", e)
+	}
+
+	// Without force, pinned versions are kept even when upstream publishes
+	registry["@playwright/mcp"] = "0.0.41"
+	if changes, _ := s.Pin(context.Background(), nil, false); len(changes) != 0 {
+		t.Errorf("Pin() without force changed %v", changes)
+	}
+
+	// update bumps deliberately
+	changes, err = s.Pin(context.Background(), []string{"playwright"}, true)
+	if err != nil {
+		t.Fatalf("Pin(force) error = %v", err)
+	}
+	if len(changes) != 1 || changes[0].From != "0.0.40" || changes[0].To != "0.0.41" {
+		t.Errorf("Pin(force) = %+v, want 0.0.40 -> 0.0.41", changes)
+	}
+	if args := serverArgs(t, mustOpen(t, path), "playwright"); !strings.Contains(strings.Join(args, " "), "@playwright/mcp@0.0.41") {
+		t.Errorf("playwright args = %v, want updated version", args)
+	}
+
+	if _, err := s.Pin(context.Background(), []string{"azd"}, true); err == nil {
+		t.Error("Pin() of a non-npx server should fail")
+	}
+}
+
+func TestPin_ResolveFailureKeepsConfig(t *testing.T) {
+	stubNPM(t, map[string]string{"@azure/mcp": "1.0.0"})
+	path := writeConfig(t, "")
+	s := mustOpen(t, path)
+	if _, err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := s.Pin(context.Background(), nil, false)
+	if err == nil {
+		t.Fatal("Pin() should report packages it could not resolve")
+	}
+	if len(changes) != 1 || changes[0].Name != "azure" {
+		t.Errorf("Pin() changes = %+v, want only azure", changes)
+	}
+	if args := serverArgs(t, mustOpen(t, path), "context7"); !strings.Contains(strings.Join(args, " "), "@latest") {
+		t.Errorf("unresolved context7 args = %v, want @latest", args)
+	}
+}
+
+func TestLockPathEnv(t *testing.T) {
+	custom := filepath.Join(t.TempDir(), "baseline.lock.json")
+	t.Setenv(LockPathEnv, custom)
+	stubNPM(t, testRegistry)
+
+	s := mustOpen(t, writeConfig(t, ""))
+	if s.LockPath != custom {
+		t.Errorf("LockPath = %s, want %s", s.LockPath, custom)
+	}
+	if _, err := s.Pin(context.Background(), []string{"azure"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(custom); err != nil {
+		t.Errorf("lock not written to %s: %v", custom, err)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	npmCache := stubNPM(t, testRegistry)
+	path := writeConfig(t, "")
+	s := mustOpen(t, path)
+	if _, err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Pin(context.Background(), []string{"azure", "playwright"}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	s = mustOpen(t, path)
+	s.Offline = true
+	if _, err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	args := strings.Join(serverArgs(t, mustOpen(t, path), "azure"), " ")
+	if !strings.HasPrefix(args, "--offline -y @azure/mcp@1.0.0") {
+		t.Errorf("offline azure args = %q", args)
+	}
+	if args := serverArgs(t, mustOpen(t, path), "azd"); strings.Contains(strings.Join(args, " "), "--offline") {
+		t.Errorf("non-npx servers should not get --offline: %v", args)
+	}
+
+	// context7 and microsoft-learn are unpinned and not cached
+	err := s.CheckOffline(context.Background())
+	if err == nil {
+		t.Fatal("CheckOffline() should fail when packages are missing from the cache")
+	}
+	for _, want := range []string{"@upstash/context7-mcp@latest", "mcp update"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("CheckOffline() error %q should mention %s", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "@azure/mcp") {
+		t.Errorf("CheckOffline() error %q should not list cached packages", err)
+	}
+
+	npmCache["@upstash/context7-mcp@latest"] = true
+	npmCache["@anthropic/mcp-microsoft-learn@latest"] = true
+	if err := s.CheckOffline(context.Background()); err != nil {
+		t.Errorf("CheckOffline() error = %v, want nil when every package is cached", err)
+	}
+
+	// Going back online restores the online definitions
+	s = mustOpen(t, path)
+	if _, err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if args := serverArgs(t, mustOpen(t, path), "azure"); strings.Contains(strings.Join(args, " "), "--offline") {
+		t.Errorf("online azure args = %v, want no --offline", args)
+	}
+}
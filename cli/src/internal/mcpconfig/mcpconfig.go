@@ -0,0 +1,501 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package mcpconfig reads and merges the Copilot CLI MCP server configuration
+// (~/.copilot/mcp-config.json) without disturbing user-defined servers.
+//
+// Entries written by azd copilot are tracked in a state file next to the
+// config. An entry is owned by azd copilot only while its definition still
+// matches the one recorded there, so any user edit turns it into a
+// user-owned entry that is never overwritten.
+package mcpconfig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-core/fileutil"
+)
+
+// ConfigFile is the Copilot CLI MCP config file name
+const ConfigFile = "mcp-config.json"
+
+// builtinServers are the MCP servers azd copilot registers with Copilot CLI
+var builtinServers = map[string]string{
+	"azure": `{
+      "type": "local",
+      "command": "npx",
+      "args": ["-y", "@azure/mcp@latest", "server", "start"],
+      "tools": ["*"]
+    }`,
+	"azd": `{
+      "type": "local",
+      "command": "azd",
+      "args": ["mcp", "server"],
+      "tools": ["*"]
+    }`,
+	"microsoft-learn": `{
+      "type": "local",
+      "command": "npx",
+      "args": ["-y", "@anthropic/mcp-microsoft-learn@latest"],
+      "tools": ["*"]
+    }`,
+	"context7": `{
+      "type": "local",
+      "command": "npx",
+      "args": ["-y", "@upstash/context7-mcp@latest"],
+      "tools": ["*"]
+    }`,
+	"azd-app": `{
+      "type": "local",
+      "command": "azd",
+      "args": ["copilot", "mcp", "serve"],
+      "tools": ["*"]
+    }`,
+	"playwright": `{
+      "type": "local",
+      "command": "npx",
+      "args": ["-y", "@playwright/mcp@latest"],
+      "tools": ["*"]
+    }`,
+}
+
+// Builtins returns the names of the MCP servers azd copilot registers, sorted
+func Builtins() []string {
+	names := make([]string, 0, len(builtinServers))
+	for name := range builtinServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Builtin returns the embedded definition of a built-in server
+func Builtin(name string) (json.RawMessage, bool) {
+	def, ok := builtinServers[name]
+	if !ok {
+		return nil, false
+	}
+	return json.RawMessage(def), true
+}
+
+// Server is the decoded form of an MCP server definition
+type Server struct {
+	Type    string            `json:"type,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Tools   []string          `json:"tools,omitempty"`
+}
+
+// Summary returns a one-line description of how the server is started
+func (s Server) Summary() string {
+	if s.URL != "" {
+		return s.URL
+	}
+	return strings.TrimSpace(s.Command + " " + strings.Join(s.Args, " "))
+}
+
+// DefaultPath returns the path to ~/.copilot/mcp-config.json
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".copilot", ConfigFile), nil
+}
+
+// state records which entries azd copilot owns and which it must leave out
+type state struct {
+	Owned    map[string]string          `json:"owned"`              // name -> hash of the definition azd copilot wrote
+	Disabled map[string]json.RawMessage `json:"disabled,omitempty"` // definitions removed by 'mcp disable'
+	Removed  []string                   `json:"removed,omitempty"`  // built-ins the user removed; never re-added
+}
+
+// Store is an MCP config file plus its ownership state and version lock
+type Store struct {
+	Path      string
+	StatePath string
+	LockPath  string
+	Offline   bool // Run npx servers from the npm cache only
+
+	top     map[string]json.RawMessage
+	servers map[string]json.RawMessage
+	state   state
+	lock    *Lock
+}
+
+// StatePathFor returns the ownership state file used for a config file
+func StatePathFor(configPath string) string {
+	return strings.TrimSuffix(configPath, ".json") + ".azd-copilot.json"
+}
+
+// Open loads ~/.copilot/mcp-config.json and its ownership state
+func Open() (*Store, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return OpenAt(path)
+}
+
+// OpenAt loads the config at path. A missing file is treated as empty;
+// a file that is not valid JSON is an error so it is never overwritten.
+func OpenAt(path string) (*Store, error) {
+	s := &Store{
+		Path:      path,
+		StatePath: StatePathFor(path),
+		LockPath:  LockPathFor(path),
+		top:       map[string]json.RawMessage{},
+		servers:   map[string]json.RawMessage{},
+		state:     state{Owned: map[string]string{}, Disabled: map[string]json.RawMessage{}},
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is the Copilot CLI config location
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	case len(bytes.TrimSpace(data)) > 0:
+		if err := json.Unmarshal(data, &s.top); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if raw, ok := s.top["mcpServers"]; ok && string(raw) != "null" {
+			if err := json.Unmarshal(raw, &s.servers); err != nil {
+				return nil, fmt.Errorf("failed to parse mcpServers in %s: %w", path, err)
+			}
+		}
+	}
+
+	stateData, err := os.ReadFile(s.StatePath) //nolint:gosec // G304: state path is derived from the config path
+	if err == nil {
+		if err := json.Unmarshal(stateData, &s.state); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", s.StatePath, err)
+		}
+		if s.state.Owned == nil {
+			s.state.Owned = map[string]string{}
+		}
+		if s.state.Disabled == nil {
+			s.state.Disabled = map[string]json.RawMessage{}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", s.StatePath, err)
+	}
+
+	if s.lock, err = loadLock(s.LockPath); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Save writes the config and state, backing up the previous config first
+func (s *Store) Save() error {
+	if err := fileutil.EnsureDir(filepath.Dir(s.Path)); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(s.Path), err)
+	}
+
+	if existing, err := os.ReadFile(s.Path); err == nil { //nolint:gosec // G304: path is the Copilot CLI config location
+		if err := fileutil.AtomicWriteFile(s.Path+".bak", existing, 0o600); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", s.Path, err)
+		}
+	}
+
+	servers, err := json.Marshal(s.servers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mcpServers: %w", err)
+	}
+	s.top["mcpServers"] = servers
+
+	data, err := json.MarshalIndent(s.top, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", ConfigFile, err)
+	}
+	if err := fileutil.AtomicWriteFile(s.Path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.Path, err)
+	}
+
+	if err := fileutil.AtomicWriteJSON(s.StatePath, s.state); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.StatePath, err)
+	}
+	return nil
+}
+
+// Names returns the names of configured servers, sorted
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.servers))
+	for name := range s.servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the raw definition of a configured server
+func (s *Store) Get(name string) (json.RawMessage, bool) {
+	def, ok := s.servers[name]
+	return def, ok
+}
+
+// Owned reports whether azd copilot owns the configured entry, meaning
+// it still matches the definition azd copilot last wrote
+func (s *Store) Owned(name string) bool {
+	def, ok := s.servers[name]
+	if !ok {
+		return false
+	}
+	recorded, ok := s.state.Owned[name]
+	return ok && recorded == hash(def)
+}
+
+// Ownership of a configured server
+const (
+	SourceBuiltin  = "built-in"
+	SourceModified = "built-in (modified)"
+	SourceUser     = "user"
+	SourceProject  = "project"
+	SourceSelf     = "self"
+)
+
+// Entry describes a configured or disabled server
+type Entry struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	Enabled bool   `json:"enabled"`
+	Note    string `json:"note,omitempty"`
+	Server  Server `json:"server"`
+}
+
+// List returns every configured and disabled server, sorted by name
+func (s *Store) List() []Entry {
+	entries := make([]Entry, 0, len(s.servers)+len(s.state.Disabled))
+	add := func(name string, def json.RawMessage, enabled bool) {
+		var srv Server
+		_ = json.Unmarshal(def, &srv) // unknown shapes are listed without details
+		entries = append(entries, Entry{Name: name, Source: s.source(name, def), Enabled: enabled, Server: srv})
+	}
+	for name, def := range s.servers {
+		add(name, def, true)
+	}
+	for name, def := range s.state.Disabled {
+		add(name, def, false)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+func (s *Store) source(name string, def json.RawMessage) string {
+	if _, builtin := builtinServers[name]; !builtin {
+		return SourceUser
+	}
+	recorded, owned := s.state.Owned[name]
+	if owned && recorded == hash(def) {
+		return SourceBuiltin
+	}
+	if !owned && equalJSON(def, s.desired(name)) {
+		return SourceBuiltin
+	}
+	if owned {
+		return SourceModified
+	}
+	return SourceUser
+}
+
+// Change actions reported by Plan and Sync
+const (
+	ActionAdd       = "add"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+	ActionSkipUser  = "skip (user-owned)"
+	ActionDisabled  = "skip (disabled)"
+	ActionRemoved   = "skip (removed)"
+)
+
+// Change describes what syncing a built-in server does
+type Change struct {
+	Name    string          `json:"name"`
+	Action  string          `json:"action"`
+	Current json.RawMessage `json:"current,omitempty"`
+	Desired json.RawMessage `json:"desired"`
+}
+
+// Plan computes the changes Sync would make without modifying the store.
+// Desired definitions reflect the version lock and offline mode.
+func (s *Store) Plan() []Change {
+	changes := make([]Change, 0, len(builtinServers))
+	for _, name := range Builtins() {
+		desired := s.desired(name)
+		current, present := s.servers[name]
+		change := Change{Name: name, Current: current, Desired: desired}
+
+		_, disabled := s.state.Disabled[name]
+		recorded, owned := s.state.Owned[name]
+		switch {
+		case slices.Contains(s.state.Removed, name):
+			change.Action = ActionRemoved
+		case disabled:
+			change.Action = ActionDisabled
+		case !present:
+			change.Action = ActionAdd
+		case equalJSON(current, desired):
+			change.Action = ActionUnchanged
+		case owned && recorded == hash(current):
+			change.Action = ActionUpdate
+		default:
+			change.Action = ActionSkipUser
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// Sync adds missing built-in servers and upgrades the ones azd copilot owns.
+// User-owned, disabled, and removed entries are left alone. The config is
+// only written when something changed.
+func (s *Store) Sync() ([]Change, error) {
+	changes := s.Plan()
+	dirty := false
+	for _, c := range changes {
+		switch c.Action {
+		case ActionAdd, ActionUpdate:
+			s.servers[c.Name] = c.Desired
+			s.state.Owned[c.Name] = hash(c.Desired)
+			dirty = true
+		case ActionUnchanged:
+			// Claim entries written before ownership was tracked
+			if s.state.Owned[c.Name] != hash(c.Current) {
+				s.state.Owned[c.Name] = hash(c.Current)
+				dirty = true
+			}
+		}
+	}
+	if !dirty {
+		return changes, nil
+	}
+	return changes, s.Save()
+}
+
+// Add configures a server. Existing entries are only replaced with force.
+// Adding a built-in name without a definition restores the embedded one.
+func (s *Store) Add(name string, def json.RawMessage, force bool) error {
+	if def == nil {
+		if _, ok := builtinServers[name]; !ok {
+			return fmt.Errorf("%q is not a built-in server; provide --command or --url", name)
+		}
+		def = s.desired(name)
+	}
+	if !json.Valid(def) {
+		return fmt.Errorf("invalid definition for %q", name)
+	}
+	if _, exists := s.servers[name]; exists && !force {
+		return fmt.Errorf("server %q already exists (use --force to replace it)", name)
+	}
+	if _, disabled := s.state.Disabled[name]; disabled && !force {
+		return fmt.Errorf("server %q is disabled (use 'mcp enable %s' or --force)", name, name)
+	}
+
+	s.servers[name] = def
+	delete(s.state.Disabled, name)
+	s.state.Removed = slices.DeleteFunc(s.state.Removed, func(n string) bool { return n == name })
+	if _, ok := builtinServers[name]; ok && equalJSON(def, s.desired(name)) {
+		s.state.Owned[name] = hash(def)
+	} else {
+		delete(s.state.Owned, name)
+	}
+	return s.Save()
+}
+
+// Remove deletes a server. Removed built-ins are not re-added by Sync
+// until they are added again.
+func (s *Store) Remove(name string) error {
+	_, present := s.servers[name]
+	_, disabled := s.state.Disabled[name]
+	if !present && !disabled {
+		return fmt.Errorf("server %q is not configured", name)
+	}
+
+	delete(s.servers, name)
+	delete(s.state.Disabled, name)
+	delete(s.state.Owned, name)
+	if _, builtin := builtinServers[name]; builtin && !slices.Contains(s.state.Removed, name) {
+		s.state.Removed = append(s.state.Removed, name)
+	}
+	return s.Save()
+}
+
+// Disable removes a server from the config while keeping its definition
+// so Enable can restore it
+func (s *Store) Disable(name string) error {
+	def, present := s.servers[name]
+	if !present {
+		if _, disabled := s.state.Disabled[name]; disabled {
+			return nil
+		}
+		return fmt.Errorf("server %q is not configured", name)
+	}
+
+	s.state.Disabled[name] = def
+	delete(s.servers, name)
+	return s.Save()
+}
+
+// Enable restores a disabled server
+func (s *Store) Enable(name string) error {
+	def, disabled := s.state.Disabled[name]
+	if !disabled {
+		if _, present := s.servers[name]; present {
+			return nil
+		}
+		return fmt.Errorf("server %q is not disabled", name)
+	}
+
+	s.servers[name] = def
+	delete(s.state.Disabled, name)
+	return s.Save()
+}
+
+// Missing returns the built-in servers the config does not define,
+// excluding ones the user disabled or removed
+func (s *Store) Missing() []string {
+	var missing []string
+	for _, c := range s.Plan() {
+		if c.Action == ActionAdd {
+			missing = append(missing, c.Name)
+		}
+	}
+	return missing
+}
+
+// hash returns a digest of a definition, independent of formatting
+func hash(def json.RawMessage) string {
+	sum := sha256.Sum256(canonical(def))
+	return hex.EncodeToString(sum[:])
+}
+
+func equalJSON(a, b json.RawMessage) bool {
+	return bytes.Equal(canonical(a), canonical(b))
+}
+
+// canonical re-encodes JSON so key order and whitespace do not matter
+func canonical(def json.RawMessage) []byte {
+	var v interface{}
+	if err := json.Unmarshal(def, &v); err != nil {
+		return def
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return def
+	}
+	return out
+}
@@ -0,0 +1,284 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package mcpconfig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ConfigFile)
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func mustOpen(t *testing.T, path string) *Store {
+	t.Helper()
+	s, err := OpenAt(path)
+	if err != nil {
+		t.Fatalf("OpenAt() error = %v", err)
+	}
+	return s
+}
+
+func actions(changes []Change) map[string]string {
+	m := make(map[string]string, len(changes))
+	for _, c := range changes {
+		m[c.Name] = c.Action
+	}
+	return m
+}
+
+func TestOpenAt_InvalidJSON(t *testing.T) {
+	path := writeConfig(t, `{"mcpServers": {`)
+	if _, err := OpenAt(path); err == nil {
+		t.Fatal("OpenAt() should fail on invalid JSON")
+	}
+}
+
+func TestSync_NewConfig(t *testing.T) {
+	path := writeConfig(t, "")
+
+	changes, err := mustOpen(t, path).Sync()
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	for name, action := range actions(changes) {
+		if action != ActionAdd {
+			t.Errorf("%s action = %q, want add", name, action)
+		}
+	}
+
+	s := mustOpen(t, path)
+	if got := len(s.Names()); got != len(Builtins()) {
+		t.Errorf("configured %d servers, want %d", got, len(Builtins()))
+	}
+	for _, name := range Builtins() {
+		if !s.Owned(name) {
+			t.Errorf("%s should be owned after Sync()", name)
+		}
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Error("no backup should be written when the config did not exist")
+	}
+}
+
+func TestSync_PreservesUserEntries(t *testing.T) {
+	path := writeConfig(t, `{
+  "theme": "dark",
+  "mcpServers": {
+    "azure": {"type": "local", "command": "my-azure-mcp", "args": [], "tools": ["*"]},
+    "github": {"type": "http", "url": "https://example.com/mcp", "tools": ["*"]}
+  }
+}`)
+
+	changes, err := mustOpen(t, path).Sync()
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if got := actions(changes)["azure"]; got != ActionSkipUser {
+		t.Errorf("azure action = %q, want %q", got, ActionSkipUser)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		t.Fatalf("written config is invalid: %v", err)
+	}
+	if string(top["theme"]) != `"dark"` {
+		t.Errorf("unrelated top-level keys not preserved: %s", top["theme"])
+	}
+
+	s := mustOpen(t, path)
+	def, _ := s.Get("azure")
+	if !strings.Contains(string(def), "my-azure-mcp") {
+		t.Errorf("user-owned azure entry was overwritten: %s", def)
+	}
+	if _, ok := s.Get("github"); !ok {
+		t.Error("user-defined github server was dropped")
+	}
+	if s.Owned("azure") || s.Owned("github") {
+		t.Error("user entries must not be owned")
+	}
+
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		t.Fatalf("backup not written: %v", err)
+	}
+	if !strings.Contains(string(backup), `"theme": "dark"`) {
+		t.Errorf("backup does not match the original config: %s", backup)
+	}
+}
+
+func TestSync_UpgradesOwnedEntries(t *testing.T) {
+	path := writeConfig(t, "")
+	s := mustOpen(t, path)
+	if _, err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate an older embedded definition that azd copilot wrote
+	old := json.RawMessage(`{"type": "local", "command": "npx", "args": ["-y", "@playwright/mcp@0.0.1"], "tools": ["*"]}`)
+	s.servers["playwright"] = old
+	s.state.Owned["playwright"] = hash(old)
+	// And a built-in the user edited afterwards
+	s.servers["context7"] = json.RawMessage(`{"type": "local", "command": "npx", "args": ["-y", "context7-fork"], "tools": ["*"]}`)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = mustOpen(t, path)
+	plan := actions(s.Plan())
+	if plan["playwright"] != ActionUpdate {
+		t.Errorf("playwright action = %q, want update", plan["playwright"])
+	}
+	if plan["context7"] != ActionSkipUser {
+		t.Errorf("context7 action = %q, want %q", plan["context7"], ActionSkipUser)
+	}
+	if _, err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = mustOpen(t, path)
+	def, _ := s.Get("playwright")
+	if !equalJSON(def, json.RawMessage(builtinServers["playwright"])) {
+		t.Errorf("owned playwright entry not upgraded: %s", def)
+	}
+	def, _ = s.Get("context7")
+	if !strings.Contains(string(def), "context7-fork") {
+		t.Errorf("modified context7 entry was overwritten: %s", def)
+	}
+}
+
+func TestSync_ClaimsMatchingLegacyEntries(t *testing.T) {
+	path := writeConfig(t, `{"mcpServers": {"azd": `+builtinServers["azd"]+`}}`)
+	s := mustOpen(t, path)
+	if s.Owned("azd") {
+		t.Fatal("azd should not be owned before Sync()")
+	}
+	if _, err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if !mustOpen(t, path).Owned("azd") {
+		t.Error("azd matching the embedded definition should be claimed by Sync()")
+	}
+}
+
+func TestAddRemoveEnableDisable(t *testing.T) {
+	path := writeConfig(t, "")
+	s := mustOpen(t, path)
+	if _, err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	custom := json.RawMessage(`{"type": "local", "command": "my-mcp", "tools": ["*"]}`)
+	if err := s.Add("custom", custom, false); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := s.Add("custom", custom, false); err == nil {
+		t.Error("Add() of an existing server without force should fail")
+	}
+	if err := s.Add("not-builtin", nil, false); err == nil {
+		t.Error("Add() without a definition should fail for non built-ins")
+	}
+
+	// Disabling keeps the definition out of the config until re-enabled
+	if err := s.Disable("custom"); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+	s = mustOpen(t, path)
+	if _, ok := s.Get("custom"); ok {
+		t.Error("disabled server should not be in the config")
+	}
+	if err := s.Enable("custom"); err != nil {
+		t.Fatalf("Enable() error = %v", err)
+	}
+	if def, ok := mustOpen(t, path).Get("custom"); !ok || !equalJSON(def, custom) {
+		t.Errorf("enabled server = %s, want %s", def, custom)
+	}
+
+	// Disabled built-ins are not re-added by Sync
+	s = mustOpen(t, path)
+	if err := s.Disable("playwright"); err != nil {
+		t.Fatal(err)
+	}
+	if got := actions(s.Plan())["playwright"]; got != ActionDisabled {
+		t.Errorf("playwright action = %q, want %q", got, ActionDisabled)
+	}
+	if missing := s.Missing(); len(missing) != 0 {
+		t.Errorf("Missing() = %v, want none when built-ins are disabled", missing)
+	}
+
+	// Removed built-ins stay removed until added again
+	if err := s.Remove("azure"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	s = mustOpen(t, path)
+	if _, ok := s.Get("azure"); ok {
+		t.Error("removed built-in was re-added by Sync()")
+	}
+	if err := s.Add("azure", nil, false); err != nil {
+		t.Fatalf("Add() of a built-in error = %v", err)
+	}
+	if !mustOpen(t, path).Owned("azure") {
+		t.Error("re-added built-in should be owned")
+	}
+
+	if err := s.Remove("missing"); err == nil {
+		t.Error("Remove() of an unknown server should fail")
+	}
+}
+
+func TestList(t *testing.T) {
+	path := writeConfig(t, `{"mcpServers": {"azure": {"type": "local", "command": "custom-azure"}, "zzz": {"type": "http", "url": "https://example.com"}}}`)
+	s := mustOpen(t, path)
+	if _, err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Disable("playwright"); err != nil {
+		t.Fatal(err)
+	}
+
+	byName := map[string]Entry{}
+	for _, e := range mustOpen(t, path).List() {
+		byName[e.Name] = e
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		enabled bool
+		summary string
+	}{
+		{"azd", SourceBuiltin, true, "azd mcp server"},
+		{"azure", SourceUser, true, "custom-azure"},
+		{"playwright", SourceBuiltin, false, "npx -y @playwright/mcp@latest"},
+		{"zzz", SourceUser, true, "https://example.com"},
+	}
+	for _, tt := range tests {
+		e, ok := byName[tt.name]
+		if !ok {
+			t.Errorf("List() missing %s", tt.name)
+			continue
+		}
+		if e.Source != tt.source || e.Enabled != tt.enabled || e.Server.Summary() != tt.summary {
+			t.Errorf("%s = {%s %v %q}, want {%s %v %q}", tt.name, e.Source, e.Enabled, e.Server.Summary(), tt.source, tt.enabled, tt.summary)
+		}
+	}
+}
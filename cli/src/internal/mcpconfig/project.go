@@ -0,0 +1,117 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package mcpconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+// Session holds the project-scoped MCP configuration for one Copilot CLI
+// session. The global config is never modified; project servers are written
+// to a temporary file passed with --additional-mcp-config.
+type Session struct {
+	ConfigFile string   // Temporary config with project servers; empty when there are none
+	Servers    []string // Project server names, sorted
+	Disable    []string // Servers to pass to --disable-mcp-server
+}
+
+// PrepareSession validates project MCP settings and writes the per-session
+// config. Callers must Close the session once Copilot CLI exits.
+func PrepareSession(settings *spec.MCPSettings) (*Session, error) {
+	session := &Session{}
+	if settings == nil {
+		return session, nil
+	}
+
+	for _, name := range settings.Disable {
+		if name == "" {
+			return nil, fmt.Errorf("%s: empty server name in mcp.disable", spec.MetadataFile)
+		}
+	}
+	session.Disable = append(session.Disable, settings.Disable...)
+	sort.Strings(session.Disable)
+
+	if len(settings.Servers) == 0 {
+		return session, nil
+	}
+
+	for name, def := range settings.Servers {
+		var fields map[string]json.RawMessage
+		if name == "" || json.Unmarshal(def, &fields) != nil {
+			return nil, fmt.Errorf("%s: mcp.servers.%s must be a JSON object", spec.MetadataFile, name)
+		}
+		if _, hasCommand := fields["command"]; !hasCommand {
+			if _, hasURL := fields["url"]; !hasURL {
+				return nil, fmt.Errorf("%s: mcp.servers.%s needs a command or url", spec.MetadataFile, name)
+			}
+		}
+		session.Servers = append(session.Servers, name)
+	}
+	sort.Strings(session.Servers)
+
+	data, err := json.MarshalIndent(map[string]interface{}{"mcpServers": settings.Servers}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal project MCP servers: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "azd-copilot-mcp-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session MCP config: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to write session MCP config: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to write session MCP config: %w", err)
+	}
+	session.ConfigFile = f.Name()
+
+	return session, nil
+}
+
+// Close removes the per-session config file
+func (s *Session) Close() {
+	if s.ConfigFile != "" {
+		_ = os.Remove(s.ConfigFile)
+	}
+}
+
+// WithProject overlays project settings on the global entries: servers the
+// project disables are marked disabled, and project servers are appended.
+// A project server with the same name as a global one replaces it.
+func WithProject(entries []Entry, settings *spec.MCPSettings) []Entry {
+	if settings == nil {
+		return entries
+	}
+
+	merged := make([]Entry, 0, len(entries)+len(settings.Servers))
+	for _, e := range entries {
+		if _, overridden := settings.Servers[e.Name]; overridden {
+			continue
+		}
+		for _, name := range settings.Disable {
+			if e.Name == name && e.Enabled {
+				e.Enabled = false
+				e.Note = "disabled by " + spec.MetadataFile
+			}
+		}
+		merged = append(merged, e)
+	}
+
+	for name, def := range settings.Servers {
+		var srv Server
+		_ = json.Unmarshal(def, &srv) // validated by PrepareSession at launch
+		merged = append(merged, Entry{Name: name, Source: SourceProject, Enabled: true, Server: srv})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name < merged[j].Name })
+	return merged
+}
@@ -0,0 +1,138 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package mcpconfig
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+func TestPrepareSession(t *testing.T) {
+	t.Run("no settings", func(t *testing.T) {
+		session, err := PrepareSession(nil)
+		if err != nil {
+			t.Fatalf("PrepareSession(nil) error = %v", err)
+		}
+		if session.ConfigFile != "" || len(session.Disable) != 0 {
+			t.Errorf("PrepareSession(nil) = %+v, want empty", session)
+		}
+	})
+
+	t.Run("servers and disable", func(t *testing.T) {
+		settings := &spec.MCPSettings{
+			Servers: map[string]json.RawMessage{
+				"docs": json.RawMessage(`{"type": "http", "url": "https://docs.internal/mcp", "tools": ["*"]}`),
+				"db":   json.RawMessage(`{"type": "local", "command": "db-mcp", "tools": ["*"]}`),
+			},
+			Disable: []string{"playwright"},
+		}
+		session, err := PrepareSession(settings)
+		if err != nil {
+			t.Fatalf("PrepareSession() error = %v", err)
+		}
+		defer session.Close()
+
+		if len(session.Servers) != 2 || session.Servers[0] != "db" {
+			t.Errorf("Servers = %v, want [db docs]", session.Servers)
+		}
+		if len(session.Disable) != 1 || session.Disable[0] != "playwright" {
+			t.Errorf("Disable = %v, want [playwright]", session.Disable)
+		}
+
+		data, err := os.ReadFile(session.ConfigFile)
+		if err != nil {
+			t.Fatalf("session config not written: %v", err)
+		}
+		var cfg struct {
+			MCPServers map[string]Server `json:"mcpServers"`
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			t.Fatalf("session config is invalid: %v", err)
+		}
+		if cfg.MCPServers["docs"].URL != "https://docs.internal/mcp" {
+			t.Errorf("session config = %s", data)
+		}
+
+		session.Close()
+		if _, err := os.Stat(session.ConfigFile); !os.IsNotExist(err) {
+			t.Error("Close() should remove the session config")
+		}
+	})
+
+	t.Run("invalid server", func(t *testing.T) {
+		for _, def := range []string{`"not-an-object"`, `{"type": "local"}`} {
+			settings := &spec.MCPSettings{Servers: map[string]json.RawMessage{"bad": json.RawMessage(def)}}
+			if _, err := PrepareSession(settings); err == nil {
+				t.Errorf("PrepareSession(%s) should fail", def)
+			}
+		}
+	})
+}
+
+func TestPrepareSession_DoesNotTouchGlobalConfig(t *testing.T) {
+	path := writeConfig(t, "")
+	if _, err := mustOpen(t, path).Sync(); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := PrepareSession(&spec.MCPSettings{
+		Servers: map[string]json.RawMessage{"docs": json.RawMessage(`{"url": "https://docs.internal/mcp"}`)},
+		Disable: []string{"playwright"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.Close()
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Error("PrepareSession() modified the global config")
+	}
+}
+
+func TestWithProject(t *testing.T) {
+	entries := []Entry{
+		{Name: "azure", Source: SourceBuiltin, Enabled: true},
+		{Name: "playwright", Source: SourceBuiltin, Enabled: true},
+	}
+	settings := &spec.MCPSettings{
+		Servers: map[string]json.RawMessage{
+			"azure": json.RawMessage(`{"command": "azure-fork"}`),
+			"docs":  json.RawMessage(`{"url": "https://docs.internal/mcp"}`),
+		},
+		Disable: []string{"playwright"},
+	}
+
+	merged := WithProject(entries, settings)
+	if len(merged) != 3 {
+		t.Fatalf("WithProject() returned %d entries, want 3", len(merged))
+	}
+	byName := map[string]Entry{}
+	for _, e := range merged {
+		byName[e.Name] = e
+	}
+	if e := byName["azure"]; e.Source != SourceProject || e.Server.Command != "azure-fork" {
+		t.Errorf("azure = %+v, want project override", e)
+	}
+	if e := byName["playwright"]; e.Enabled || e.Note == "" {
+		t.Errorf("playwright = %+v, want disabled by project", e)
+	}
+	if e := byName["docs"]; e.Source != SourceProject || !e.Enabled {
+		t.Errorf("docs = %+v, want enabled project server", e)
+	}
+
+	if got := WithProject(entries, nil); len(got) != len(entries) {
+		t.Errorf("WithProject(nil) = %v, want entries unchanged", got)
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package policy
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// ActionConfirm marks an MCP tool call that runs only after the user confirms it.
+const ActionConfirm Action = "confirm"
+
+// DefaultWorkflowDeny lists azd commands the run_workflow MCP tool refuses
+// in addition to a policy's own, unless the policy sets noDefaults.
+var DefaultWorkflowDeny = []string{
+	"down --purge",
+}
+
+// DefaultWorkflowConfirm lists azd commands the run_workflow MCP tool runs
+// only after confirmation in addition to a policy's own, unless the policy
+// sets noDefaults.
+var DefaultWorkflowConfirm = []string{
+	"down",
+	"up",
+	"provision",
+	"deploy",
+}
+
+// DefaultEnvKeyConfirm lists environment keys the set_environment_value MCP
+// tool changes only after confirmation in addition to a policy's own, unless
+// the policy sets noDefaults.
+var DefaultEnvKeyConfirm = []string{
+	"AZURE_ENV_NAME",
+	"AZURE_LOCATION",
+	"AZURE_RESOURCE_GROUP",
+	"AZURE_SUBSCRIPTION_ID",
+	"AZURE_TENANT_ID",
+}
+
+// ToolRules limits the calls an MCP tool accepts. An empty Allow list
+// allows everything that is not denied.
+type ToolRules struct {
+	Allow   []string `yaml:"allow,omitempty" json:"allow,omitempty"`
+	Deny    []string `yaml:"deny,omitempty" json:"deny,omitempty"`
+	Confirm []string `yaml:"confirm,omitempty" json:"confirm,omitempty"`
+	// NoDefaults drops the built-in deny and confirm lists, leaving only the
+	// policy's own.
+	NoDefaults bool `yaml:"noDefaults,omitempty" json:"noDefaults,omitempty"`
+}
+
+// MCPRules guard the azd-copilot MCP tools that change state.
+type MCPRules struct {
+	// Workflows are azd command patterns for run_workflow, e.g. "down --purge".
+	// Positional words match as a prefix; flags match anywhere in the step.
+	Workflows ToolRules `yaml:"workflows,omitempty" json:"workflows"`
+	// EnvKeys are key globs for set_environment_value, e.g. "AZURE_*".
+	EnvKeys ToolRules `yaml:"envKeys,omitempty" json:"envKeys"`
+}
+
+func (r *ToolRules) merge(other ToolRules) {
+	r.Allow = append(r.Allow, other.Allow...)
+	r.Deny = append(r.Deny, other.Deny...)
+	r.Confirm = append(r.Confirm, other.Confirm...)
+	r.NoDefaults = r.NoDefaults || other.NoDefaults
+}
+
+func (r ToolRules) isEmpty() bool {
+	return len(r.Allow) == 0 && len(r.Deny) == 0 && len(r.Confirm) == 0 && !r.NoDefaults
+}
+
+// withDefaults returns the built-in patterns followed by the policy's own,
+// or only the policy's own when it sets NoDefaults
+func (r ToolRules) withDefaults(defaults, own []string) []string {
+	if r.NoDefaults {
+		return own
+	}
+	return append(slices.Clone(defaults), own...)
+}
+
+// DecideWorkflowStep evaluates one run_workflow step (azd arguments without
+// "azd"). Deny rules, including shell command deny rules for "azd ...",
+// take precedence; steps outside a non-empty allowlist are denied; steps
+// matching a confirm or destructive pattern need confirmation. Arguments
+// that read two ways are denied or confirmed when either reading is, and
+// allowed only when both are.
+func (p *Policy) DecideWorkflowStep(args []string) Decision {
+	d := Decision{Action: ActionAllow, Destructive: matchesAnyAzdCommand(p.DestructivePatterns(), args) != ""}
+
+	var rules ToolRules
+	if p != nil {
+		rules = p.MCP.Workflows
+		if rule := matchesAnyAzdCommand(p.Deny.Commands, args); rule != "" {
+			d.Action, d.Rule = ActionDeny, rule
+			return d
+		}
+	}
+	deny := rules.withDefaults(DefaultWorkflowDeny, rules.Deny)
+	confirm := rules.withDefaults(DefaultWorkflowConfirm, rules.Confirm)
+
+	if rule := matchesAnyArgs(deny, args); rule != "" {
+		d.Action, d.Rule = ActionDeny, rule
+		return d
+	}
+	if len(rules.Allow) > 0 {
+		i := slices.IndexFunc(rules.Allow, func(p string) bool { return matchArgsStrictly(p, args) })
+		if i < 0 {
+			d.Action, d.Rule = ActionDeny, "not in mcp.workflows.allow"
+			return d
+		}
+		d.Rule = rules.Allow[i]
+	}
+	if rule := matchesAnyArgs(confirm, args); rule != "" {
+		d.Action, d.Rule = ActionConfirm, rule
+	} else if d.Destructive {
+		d.Action = ActionConfirm
+	}
+	return d
+}
+
+// DecideEnvKey evaluates a set_environment_value key.
+func (p *Policy) DecideEnvKey(key string) Decision {
+	d := Decision{Action: ActionAllow}
+	var rules ToolRules
+	if p != nil {
+		rules = p.MCP.EnvKeys
+	}
+	confirm := rules.withDefaults(DefaultEnvKeyConfirm, rules.Confirm)
+
+	if rule := matchesAnyKey(rules.Deny, key); rule != "" {
+		d.Action, d.Rule = ActionDeny, rule
+		return d
+	}
+	if len(rules.Allow) > 0 {
+		rule := matchesAnyKey(rules.Allow, key)
+		if rule == "" {
+			d.Action, d.Rule = ActionDeny, "not in mcp.envKeys.allow"
+			return d
+		}
+		d.Rule = rule
+	}
+	if rule := matchesAnyKey(confirm, key); rule != "" {
+		d.Action, d.Rule = ActionConfirm, rule
+	}
+	return d
+}
+
+// azdValueFlags are azd flags that take their value as the next argument,
+// e.g. "-e prod". The value is not a positional word.
+var azdValueFlags = map[string]bool{
+	"-C": true, "--cwd": true,
+	"-e": true, "--environment": true,
+	"-o": true, "--output": true,
+	"-l": true, "--location": true,
+	"-t": true, "--template": true,
+	"-b": true, "--branch": true,
+	"--subscription":   true,
+	"--from-package":   true,
+	"--trace-log-file": true,
+	"--trace-log-url":  true,
+}
+
+// azdBoolFlags are azd flags that never take a separate value.
+var azdBoolFlags = map[string]bool{
+	"-h": true, "--help": true,
+	"--debug":     true,
+	"--docs":      true,
+	"--no-prompt": true,
+	"--all":       true,
+	"--force":     true,
+	"--purge":     true,
+	"--preview":   true,
+}
+
+// MatchArgs reports whether azd arguments match pattern. Positional words
+// of the pattern must prefix the positional words of args (a leading "azd"
+// is ignored); each flag in the pattern must appear somewhere in args, with
+// or without a value ("--purge" matches "--purge=true"). A word after an
+// unknown flag may be that flag's value, so it matches when either reading
+// does.
+func MatchArgs(pattern string, args []string) bool {
+	readings, flags := argReadings(args)
+	return slices.ContainsFunc(readings, func(positional []string) bool {
+		return matchReading(pattern, positional, flags)
+	})
+}
+
+// matchArgsStrictly is MatchArgs for allowlists: args match only when
+// every reading does.
+func matchArgsStrictly(pattern string, args []string) bool {
+	readings, flags := argReadings(args)
+	for _, positional := range readings {
+		if !matchReading(pattern, positional, flags) {
+			return false
+		}
+	}
+	return true
+}
+
+// argReadings splits azd arguments into the flags they set and their
+// positional words. Known value flags consume the next argument. When a
+// word follows an unknown flag, a second reading treats it as the flag's
+// value.
+func argReadings(args []string) ([][]string, map[string]bool) {
+	var positional, unambiguous []string
+	flags := map[string]bool{}
+	ambiguous := false
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if !strings.HasPrefix(a, "-") {
+			positional = append(positional, a)
+			unambiguous = append(unambiguous, a)
+			continue
+		}
+		name, _, hasValue := strings.Cut(a, "=")
+		flags[name] = true
+		if hasValue || azdBoolFlags[name] || i+1 >= len(args) || strings.HasPrefix(args[i+1], "-") {
+			continue
+		}
+		if azdValueFlags[name] {
+			i++
+			continue
+		}
+		// The next word is this flag's value or a positional word
+		ambiguous = true
+		i++
+		positional = append(positional, args[i])
+	}
+	if ambiguous {
+		return [][]string{positional, unambiguous}, flags
+	}
+	return [][]string{positional}, flags
+}
+
+func matchReading(pattern string, positional []string, flags map[string]bool) bool {
+	words := strings.Fields(pattern)
+	if len(words) > 0 && words[0] == "azd" {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return false
+	}
+
+	i := 0
+	for _, w := range words {
+		if strings.HasPrefix(w, "-") {
+			if !flags[w] {
+				return false
+			}
+			continue
+		}
+		if i >= len(positional) {
+			return false
+		}
+		if trimmed, ok := strings.CutSuffix(w, "*"); ok {
+			if !strings.HasPrefix(positional[i], trimmed) {
+				return false
+			}
+		} else if positional[i] != w {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+func matchesAnyArgs(patterns []string, args []string) string {
+	for _, p := range patterns {
+		if MatchArgs(p, args) {
+			return p
+		}
+	}
+	return ""
+}
+
+// matchesAnyAzdCommand returns the first shell command pattern that matches
+// an azd step. "azd ..." patterns also match as arguments, so global flags
+// such as --cwd before the subcommand do not hide it.
+func matchesAnyAzdCommand(patterns []string, args []string) string {
+	command := "azd " + strings.Join(args, " ")
+	for _, p := range patterns {
+		if MatchCommand(p, command) {
+			return p
+		}
+		if words := strings.Fields(p); len(words) > 1 && words[0] == "azd" && MatchArgs(p, args) {
+			return p
+		}
+	}
+	return ""
+}
+
+func matchesAnyKey(globs []string, key string) string {
+	for _, g := range globs {
+		if matched, err := path.Match(g, key); err == nil && matched {
+			return g
+		}
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package policy
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchArgs(t *testing.T) {
+	tests := []struct {
+		pattern string
+		args    string
+		want    bool
+	}{
+		{"down --purge", "down --force --purge", true},
+		{"down --purge", "down --purge=true", true},
+		{"down --purge", "down --force", false},
+		{"azd down", "down", true},
+		{"env *", "env set KEY value", true},
+		{"env set", "env get-values", false},
+		{"provision", "provision --preview", true},
+		{"provision", "deploy", false},
+		{"", "down", false},
+		{"down --purge", "--cwd . down --purge", true},
+		{"down --purge", "-e prod down --purge --force", true},
+		{"down", "--environment=prod down", true},
+		{"down", "--no-prompt down", true},
+		{"down", "--unknown value down", true},
+		{"down", "--unknown down", true},
+	}
+	for _, tt := range tests {
+		if got := MatchArgs(tt.pattern, strings.Fields(tt.args)); got != tt.want {
+			t.Errorf("MatchArgs(%q, %q) = %v, want %v", tt.pattern, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestDecideWorkflowStep(t *testing.T) {
+	custom := &Policy{
+		Deny: Rules{Commands: []string{"azd env delete"}},
+		MCP: MCPRules{Workflows: ToolRules{
+			Allow:   []string{"provision", "deploy", "env *", "show"},
+			Confirm: []string{"deploy"},
+		}},
+	}
+
+	tests := []struct {
+		name   string
+		pol    *Policy
+		args   string
+		action Action
+	}{
+		{"default deny", nil, "down --force --purge", ActionDeny},
+		{"default confirm", nil, "provision", ActionConfirm},
+		{"destructive needs confirm", &Policy{MCP: MCPRules{Workflows: ToolRules{Confirm: []string{"deploy"}}}}, "down", ActionConfirm},
+		{"default allow", nil, "show", ActionAllow},
+		{"allowlist", custom, "env get-values", ActionAllow},
+		{"allowlist keeps default confirm", custom, "provision", ActionConfirm},
+		{"custom deny keeps default deny", &Policy{MCP: MCPRules{Workflows: ToolRules{Deny: []string{"env delete"}}}}, "down --purge", ActionDeny},
+		{"no defaults", &Policy{MCP: MCPRules{Workflows: ToolRules{Deny: []string{"env delete"}, NoDefaults: true}}}, "provision", ActionAllow},
+		{"outside allowlist", custom, "down", ActionDeny},
+		{"custom confirm", custom, "deploy api", ActionConfirm},
+		{"shell deny applies", custom, "env delete dev", ActionDeny},
+		{"deny after cwd", nil, "--cwd . down --purge", ActionDeny},
+		{"deny after environment", nil, "-e prod down --purge --force", ActionDeny},
+		{"deny after unknown flag", nil, "--unknown x down --purge", ActionDeny},
+		{"confirm after environment", nil, "-e prod provision", ActionConfirm},
+		{"shell deny after cwd", custom, "--cwd . env delete dev", ActionDeny},
+		{"allowlist after environment", custom, "-e prod show", ActionAllow},
+		{"ambiguous step outside allowlist", custom, "--unknown show down", ActionDeny},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pol.DecideWorkflowStep(strings.Fields(tt.args)); got.Action != tt.action {
+				t.Errorf("DecideWorkflowStep(%q) = %+v, want %s", tt.args, got, tt.action)
+			}
+		})
+	}
+
+	// Global flags before the subcommand do not hide destructive steps
+	for _, args := range [][]string{{"--cwd", ".", "down", "--purge"}, {"-e", "prod", "down", "--purge", "--force"}} {
+		if d := (&Policy{}).DecideWorkflowStep(args); d.Action != ActionDeny || !d.Destructive {
+			t.Errorf("DecideWorkflowStep(%q) = %+v, want a destructive deny", args, d)
+		}
+	}
+}
+
+func TestDecideEnvKey(t *testing.T) {
+	custom := &Policy{MCP: MCPRules{EnvKeys: ToolRules{
+		Allow: []string{"APP_*", "AZURE_LOCATION"},
+		Deny:  []string{"APP_SECRET*"},
+	}}}
+
+	tests := []struct {
+		pol    *Policy
+		key    string
+		action Action
+	}{
+		{nil, "AZURE_SUBSCRIPTION_ID", ActionConfirm},
+		{nil, "API_URL", ActionAllow},
+		{custom, "APP_NAME", ActionAllow},
+		{custom, "APP_SECRET_KEY", ActionDeny},
+		{custom, "AZURE_LOCATION", ActionConfirm},
+		{custom, "OTHER", ActionDeny},
+		{custom, "AZURE_SUBSCRIPTION_ID", ActionDeny},
+		{&Policy{MCP: MCPRules{EnvKeys: ToolRules{Confirm: []string{"APP_*"}}}}, "AZURE_TENANT_ID", ActionConfirm},
+		{&Policy{MCP: MCPRules{EnvKeys: ToolRules{Confirm: []string{"APP_*"}, NoDefaults: true}}}, "AZURE_TENANT_ID", ActionAllow},
+	}
+	for _, tt := range tests {
+		if got := tt.pol.DecideEnvKey(tt.key); got.Action != tt.action {
+			t.Errorf("DecideEnvKey(%q) = %+v, want %s", tt.key, got, tt.action)
+		}
+	}
+}
+
+func TestLoad_MCPRules(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	project := t.TempDir()
+	t.Chdir(project)
+
+	writeFile(t, filepath.Join(home, ".azd", "copilot", "policy.yaml"), "mcp:\n  workflows:\n    deny: [\"down\"]\n")
+	writeFile(t, filepath.Join(project, ProjectFile), "mcp:\n  envKeys:\n    deny: [\"AZURE_*\"]\n")
+
+	p, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if p.IsEmpty() {
+		t.Error("policy with only mcp rules should not be empty")
+	}
+	if p.DecideWorkflowStep([]string{"down"}).Action != ActionDeny {
+		t.Error("user mcp.workflows.deny should be merged")
+	}
+	if p.DecideEnvKey("AZURE_LOCATION").Action != ActionDeny {
+		t.Error("project mcp.envKeys.deny should be merged")
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package policy loads tool permission policies and translates them into
+// GitHub Copilot CLI --allow-tool/--deny-tool arguments.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile is the policy file location relative to the project root.
+const ProjectFile = ".azd/copilot/policy.yaml"
+
+// DefaultDestructive lists command patterns treated as destructive when a
+// policy does not declare its own.
+var DefaultDestructive = []string{
+	"azd down",
+	"az group delete",
+	"az resource delete",
+	"az deployment group delete",
+	"git push --force",
+	"rm -rf",
+}
+
+// Action is the outcome of evaluating a command or edit against a policy.
+type Action string
+
+// ActionAllow through ActionAsk represent policy decisions.
+const (
+	ActionAllow Action = "allow"
+	ActionDeny  Action = "deny"
+	ActionAsk   Action = "ask" // Not covered by the policy; Copilot prompts the user
+)
+
+// Rules is a set of command, edit, and raw tool patterns.
+type Rules struct {
+	// Commands are shell command prefixes, e.g. "azd provision --preview".
+	// A trailing "*" matches any suffix of the last word.
+	Commands []string `yaml:"commands,omitempty" json:"commands,omitempty"`
+	// Edits are path globs relative to the project, e.g. "src/**".
+	Edits []string `yaml:"edits,omitempty" json:"edits,omitempty"`
+	// Tools are raw Copilot CLI tool specs passed through unchanged, e.g. "azure" or "shell(git status)".
+	Tools []string `yaml:"tools,omitempty" json:"tools,omitempty"`
+}
+
+// Policy declares which tools Copilot may use without asking.
+type Policy struct {
+	Allow       Rules    `yaml:"allow,omitempty" json:"allow"`
+	Deny        Rules    `yaml:"deny,omitempty" json:"deny"`
+	Destructive []string `yaml:"destructive,omitempty" json:"destructive,omitempty"`
+	MCP         MCPRules `yaml:"mcp,omitempty" json:"mcp"`
+	Sources     []string `yaml:"-" json:"sources,omitempty"`
+}
+
+// Decision describes how a policy treats a single command or edit.
+type Decision struct {
+	Action      Action `json:"action"`
+	Rule        string `json:"rule,omitempty"`
+	Destructive bool   `json:"destructive"`
+}
+
+// Args holds the Copilot CLI arguments derived from a policy.
+type Args struct {
+	AllowTools []string `json:"allowTools,omitempty"`
+	DenyTools  []string `json:"denyTools,omitempty"`
+	// Notes explain rules the Copilot CLI cannot enforce directly.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// UserFile returns the user-level policy path (~/.azd/copilot/policy.yaml).
+func UserFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".azd", "copilot", "policy.yaml"), nil
+}
+
+// Load returns the effective policy. When explicitPath is set only that file
+// is read; otherwise the user policy and the project policy are merged.
+// Missing files are skipped, so Load returns an empty policy when none exist.
+func Load(explicitPath string) (*Policy, error) {
+	if explicitPath != "" {
+		return LoadFile(explicitPath)
+	}
+
+	merged := &Policy{}
+	var paths []string
+	if userPath, err := UserFile(); err == nil {
+		paths = append(paths, userPath)
+	}
+	paths = append(paths, ProjectFile)
+
+	for _, p := range paths {
+		pol, err := LoadFile(p)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		merged.merge(pol)
+	}
+	return merged, nil
+}
+
+// LoadFile reads a single policy file.
+func LoadFile(p string) (*Policy, error) {
+	data, err := os.ReadFile(p) //nolint:gosec // G304: policy path is user-configured
+	if err != nil {
+		return nil, err
+	}
+	var pol Policy
+	if err := yaml.Unmarshal(data, &pol); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", p, err)
+	}
+	pol.Sources = []string{p}
+	return &pol, nil
+}
+
+func (p *Policy) merge(other *Policy) {
+	p.Allow.Commands = append(p.Allow.Commands, other.Allow.Commands...)
+	p.Allow.Edits = append(p.Allow.Edits, other.Allow.Edits...)
+	p.Allow.Tools = append(p.Allow.Tools, other.Allow.Tools...)
+	p.Deny.Commands = append(p.Deny.Commands, other.Deny.Commands...)
+	p.Deny.Edits = append(p.Deny.Edits, other.Deny.Edits...)
+	p.Deny.Tools = append(p.Deny.Tools, other.Deny.Tools...)
+	p.Destructive = append(p.Destructive, other.Destructive...)
+	p.MCP.Workflows.merge(other.MCP.Workflows)
+	p.MCP.EnvKeys.merge(other.MCP.EnvKeys)
+	p.Sources = append(p.Sources, other.Sources...)
+}
+
+// IsEmpty reports whether the policy declares no rules at all.
+func (p *Policy) IsEmpty() bool {
+	return p == nil || (len(p.Allow.Commands) == 0 && len(p.Allow.Edits) == 0 && len(p.Allow.Tools) == 0 &&
+		len(p.Deny.Commands) == 0 && len(p.Deny.Edits) == 0 && len(p.Deny.Tools) == 0 &&
+		len(p.Destructive) == 0 && p.MCP.Workflows.isEmpty() && p.MCP.EnvKeys.isEmpty())
+}
+
+// DestructivePatterns returns the declared destructive patterns, or
+// DefaultDestructive when the policy declares none.
+func (p *Policy) DestructivePatterns() []string {
+	if p == nil || len(p.Destructive) == 0 {
+		return DefaultDestructive
+	}
+	return p.Destructive
+}
+
+// Translate converts the policy into Copilot CLI tool arguments.
+func (p *Policy) Translate() Args {
+	var a Args
+	if p == nil {
+		return a
+	}
+
+	for _, c := range p.Allow.Commands {
+		a.AllowTools = append(a.AllowTools, shellTool(c))
+	}
+	a.AllowTools = append(a.AllowTools, p.Allow.Tools...)
+
+	for _, c := range p.Deny.Commands {
+		a.DenyTools = append(a.DenyTools, shellTool(c))
+	}
+	a.DenyTools = append(a.DenyTools, p.Deny.Tools...)
+
+	// Copilot CLI can only allow or deny the write tool as a whole, so path
+	// scoped rules never widen into a global grant: writes keep asking for
+	// confirmation and the notes say why.
+	if len(p.Allow.Edits) > 0 {
+		a.Notes = append(a.Notes, fmt.Sprintf("edits to %s still ask for confirmation: Copilot CLI cannot scope write approval to paths (add \"write\" to allow.tools to approve every edit)", strings.Join(p.Allow.Edits, ", ")))
+	}
+	if len(p.Deny.Edits) > 0 {
+		a.Notes = append(a.Notes, p.UnenforcedEdits(false))
+	}
+
+	for _, d := range p.DestructivePatterns() {
+		if p.DecideCommand(d).Action == ActionAllow {
+			a.Notes = append(a.Notes, fmt.Sprintf("destructive command %q is auto-approved; a before_destructive checkpoint is saved at launch", d))
+		}
+	}
+
+	return a
+}
+
+// UnenforcedEdits explains that deny.edits rules are not enforced, or
+// returns "" when the policy has none. Copilot CLI can only deny the write
+// tool as a whole, so denied paths only keep writes from being
+// auto-approved, and yolo mode approves them anyway.
+func (p *Policy) UnenforcedEdits(yolo bool) string {
+	if p == nil || len(p.Deny.Edits) == 0 {
+		return ""
+	}
+	paths := strings.Join(p.Deny.Edits, ", ")
+	if yolo {
+		return fmt.Sprintf("edits to %s are not blocked: Copilot CLI cannot deny writes by path and --yolo approves every file edit", paths)
+	}
+	return fmt.Sprintf("edits to %s are not blocked: Copilot CLI cannot deny writes by path, so every file edit asks for confirmation instead", paths)
+}
+
+// AutoApprovedDestructive returns the destructive patterns that will run
+// without confirmation, either because the policy allows them or because
+// yolo mode approves everything that is not denied.
+func (p *Policy) AutoApprovedDestructive(yolo bool) []string {
+	var out []string
+	for _, d := range p.DestructivePatterns() {
+		action := p.DecideCommand(d).Action
+		if action == ActionAllow || (yolo && action != ActionDeny) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// DecideCommand evaluates a shell command. Deny rules take precedence.
+func (p *Policy) DecideCommand(command string) Decision {
+	d := Decision{Action: ActionAsk, Destructive: matchesAnyCommand(p.DestructivePatterns(), command) != ""}
+	if p == nil {
+		return d
+	}
+	if rule := matchesAnyCommand(p.Deny.Commands, command); rule != "" {
+		d.Action, d.Rule = ActionDeny, rule
+		return d
+	}
+	if rule := matchesAnyCommand(p.Allow.Commands, command); rule != "" {
+		d.Action, d.Rule = ActionAllow, rule
+	}
+	return d
+}
+
+// DecideEdit evaluates a file edit. Deny rules take precedence.
+func (p *Policy) DecideEdit(file string) Decision {
+	d := Decision{Action: ActionAsk}
+	if p == nil {
+		return d
+	}
+	file = path.Clean(filepath.ToSlash(file))
+	for _, g := range p.Deny.Edits {
+		if MatchPath(g, file) {
+			d.Action, d.Rule = ActionDeny, g
+			return d
+		}
+	}
+	for _, g := range p.Allow.Edits {
+		if MatchPath(g, file) {
+			d.Action, d.Rule = ActionAllow, g
+			return d
+		}
+	}
+	return d
+}
+
+// MatchCommand reports whether command starts with the words of pattern.
+// A trailing "*" on the pattern's last word matches any suffix.
+func MatchCommand(pattern, command string) bool {
+	pw := strings.Fields(pattern)
+	cw := strings.Fields(command)
+	if len(pw) == 0 || len(cw) < len(pw) {
+		return false
+	}
+	for i, w := range pw {
+		if i == len(pw)-1 && strings.HasSuffix(w, "*") {
+			return strings.HasPrefix(cw[i], strings.TrimSuffix(w, "*"))
+		}
+		if cw[i] != w {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchPath reports whether file matches glob. A trailing "/**" or "/"
+// matches everything beneath the directory; other globs use path.Match.
+func MatchPath(glob, file string) bool {
+	glob = filepath.ToSlash(glob)
+	if dir, ok := strings.CutSuffix(glob, "/**"); ok {
+		return file == dir || strings.HasPrefix(file, dir+"/")
+	}
+	if dir, ok := strings.CutSuffix(glob, "/"); ok {
+		return file == dir || strings.HasPrefix(file, dir+"/")
+	}
+	matched, err := path.Match(glob, file)
+	return err == nil && matched
+}
+
+func matchesAnyCommand(patterns []string, command string) string {
+	for _, p := range patterns {
+		if MatchCommand(p, command) {
+			return p
+		}
+	}
+	return ""
+}
+
+// shellTool formats a command pattern as a Copilot CLI shell tool spec.
+// Copilot uses "cmd:*" for prefix wildcards.
+func shellTool(command string) string {
+	command = strings.TrimSpace(command)
+	if trimmed, ok := strings.CutSuffix(command, "*"); ok {
+		return fmt.Sprintf("shell(%s:*)", strings.TrimSpace(trimmed))
+	}
+	return fmt.Sprintf("shell(%s)", command)
+}
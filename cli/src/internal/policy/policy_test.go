@@ -0,0 +1,256 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testPolicy() *Policy {
+	return &Policy{
+		Allow: Rules{
+			Commands: []string{"azd provision --preview", "git status"},
+			Edits:    []string{"src/**"},
+		},
+		Deny: Rules{
+			Commands: []string{"az group delete"},
+			Edits:    []string{".github/**"},
+		},
+	}
+}
+
+func TestMatchCommand(t *testing.T) {
+	tests := []struct {
+		pattern string
+		command string
+		want    bool
+	}{
+		{"az group delete", "az group delete -n rg-test --yes", true},
+		{"az group delete", "az group list", false},
+		{"azd provision --preview", "azd provision --preview", true},
+		{"azd provision --preview", "azd provision", false},
+		{"git push*", "git push-all", true},
+		{"git", "gitk", false},
+		{"", "anything", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchCommand(tt.pattern, tt.command); got != tt.want {
+			t.Errorf("MatchCommand(%q, %q) = %v, want %v", tt.pattern, tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		glob string
+		file string
+		want bool
+	}{
+		{"src/**", "src/api/main.go", true},
+		{"src/**", "src", true},
+		{"src/**", "srcs/main.go", false},
+		{".github/", ".github/workflows/ci.yml", true},
+		{"*.md", "README.md", true},
+		{"*.md", "docs/README.md", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchPath(tt.glob, tt.file); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.glob, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestDecideCommand(t *testing.T) {
+	p := testPolicy()
+
+	tests := []struct {
+		command     string
+		action      Action
+		destructive bool
+	}{
+		{"azd provision --preview", ActionAllow, false},
+		{"az group delete -n rg", ActionDeny, true},
+		{"azd deploy", ActionAsk, false},
+		{"azd down --purge", ActionAsk, true},
+	}
+
+	for _, tt := range tests {
+		d := p.DecideCommand(tt.command)
+		if d.Action != tt.action {
+			t.Errorf("DecideCommand(%q).Action = %q, want %q", tt.command, d.Action, tt.action)
+		}
+		if d.Destructive != tt.destructive {
+			t.Errorf("DecideCommand(%q).Destructive = %v, want %v", tt.command, d.Destructive, tt.destructive)
+		}
+	}
+}
+
+func TestDecideCommand_DenyWins(t *testing.T) {
+	p := &Policy{
+		Allow: Rules{Commands: []string{"az"}},
+		Deny:  Rules{Commands: []string{"az group delete"}},
+	}
+
+	if d := p.DecideCommand("az group delete -n rg"); d.Action != ActionDeny {
+		t.Errorf("deny rule should take precedence, got %q", d.Action)
+	}
+	if d := p.DecideCommand("az group list"); d.Action != ActionAllow {
+		t.Errorf("allow rule should apply, got %q", d.Action)
+	}
+}
+
+func TestDecideEdit(t *testing.T) {
+	p := testPolicy()
+
+	if d := p.DecideEdit("src/app.ts"); d.Action != ActionAllow {
+		t.Errorf("DecideEdit(src/app.ts) = %q, want allow", d.Action)
+	}
+	if d := p.DecideEdit(".github/workflows/ci.yml"); d.Action != ActionDeny {
+		t.Errorf("DecideEdit(.github/...) = %q, want deny", d.Action)
+	}
+	if d := p.DecideEdit("README.md"); d.Action != ActionAsk {
+		t.Errorf("DecideEdit(README.md) = %q, want ask", d.Action)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	args := testPolicy().Translate()
+
+	for _, want := range []string{"shell(azd provision --preview)", "shell(git status)"} {
+		if !slices.Contains(args.AllowTools, want) {
+			t.Errorf("AllowTools missing %q, got %v", want, args.AllowTools)
+		}
+	}
+	if !slices.Contains(args.DenyTools, "shell(az group delete)") {
+		t.Errorf("DenyTools missing az group delete, got %v", args.DenyTools)
+	}
+	// Path scoped edit rules can't be expressed, so writes keep asking
+	if slices.Contains(args.AllowTools, "write") {
+		t.Errorf("write should not be auto-approved for path scoped edits, got %v", args.AllowTools)
+	}
+	if len(args.Notes) < 2 {
+		t.Errorf("expected notes explaining the allowed and denied edit paths, got %v", args.Notes)
+	}
+}
+
+func TestTranslate_WildcardAndRawTools(t *testing.T) {
+	p := &Policy{
+		Allow: Rules{Commands: []string{"git *"}, Tools: []string{"azure"}, Edits: []string{"src/**"}},
+	}
+	args := p.Translate()
+
+	for _, want := range []string{"shell(git:*)", "azure"} {
+		if !slices.Contains(args.AllowTools, want) {
+			t.Errorf("AllowTools missing %q, got %v", want, args.AllowTools)
+		}
+	}
+	// An edit rule for src/ must not approve writes everywhere
+	if slices.Contains(args.AllowTools, "write") {
+		t.Errorf("allow.edits %v widened into a global write grant: %v", p.Allow.Edits, args.AllowTools)
+	}
+	if !slices.ContainsFunc(args.Notes, func(n string) bool { return strings.Contains(n, "src/**") }) {
+		t.Errorf("Notes = %v, want one explaining that src/** edits still ask", args.Notes)
+	}
+}
+
+func TestUnenforcedEdits(t *testing.T) {
+	if w := (&Policy{Allow: Rules{Edits: []string{"src/**"}}}).UnenforcedEdits(true); w != "" {
+		t.Errorf("UnenforcedEdits() without denied edits = %q, want empty", w)
+	}
+
+	p := &Policy{Deny: Rules{Edits: []string{".github/**"}}}
+	if w := p.UnenforcedEdits(false); !strings.Contains(w, ".github/**") || !strings.Contains(w, "asks for confirmation") {
+		t.Errorf("UnenforcedEdits(false) = %q", w)
+	}
+	if w := p.UnenforcedEdits(true); !strings.Contains(w, "--yolo approves every file edit") {
+		t.Errorf("UnenforcedEdits(true) = %q", w)
+	}
+	if !slices.Contains(p.Translate().Notes, p.UnenforcedEdits(false)) {
+		t.Error("Translate() should note the unenforced edit rules")
+	}
+}
+
+func TestAutoApprovedDestructive(t *testing.T) {
+	p := &Policy{
+		Allow:       Rules{Commands: []string{"azd down"}},
+		Deny:        Rules{Commands: []string{"az group delete"}},
+		Destructive: []string{"azd down", "az group delete", "rm -rf"},
+	}
+
+	got := p.AutoApprovedDestructive(false)
+	if !slices.Equal(got, []string{"azd down"}) {
+		t.Errorf("AutoApprovedDestructive(false) = %v, want [azd down]", got)
+	}
+
+	got = p.AutoApprovedDestructive(true)
+	if !slices.Equal(got, []string{"azd down", "rm -rf"}) {
+		t.Errorf("AutoApprovedDestructive(true) = %v, want [azd down rm -rf]", got)
+	}
+}
+
+func TestLoad_MergesUserAndProject(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	project := t.TempDir()
+	t.Chdir(project)
+
+	writeFile(t, filepath.Join(home, ".azd", "copilot", "policy.yaml"), "deny:\n  commands: [\"az group delete\"]\n")
+	writeFile(t, filepath.Join(project, ProjectFile), "allow:\n  commands: [\"azd provision --preview\"]\n")
+
+	p, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(p.Sources) != 2 {
+		t.Errorf("Sources = %v, want 2 entries", p.Sources)
+	}
+	if p.DecideCommand("az group delete").Action != ActionDeny {
+		t.Error("user deny rule should be merged")
+	}
+	if p.DecideCommand("azd provision --preview").Action != ActionAllow {
+		t.Error("project allow rule should be merged")
+	}
+}
+
+func TestLoad_NoFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Chdir(t.TempDir())
+
+	p, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !p.IsEmpty() {
+		t.Errorf("Load() with no files should be empty, got %+v", p)
+	}
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	writeFile(t, path, "allow: [not, a, map")
+
+	if _, err := LoadFile(path); err == nil {
+		t.Error("LoadFile() should fail on invalid YAML")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package redact detects secrets in environment values and free text so
+// they are not shown on the console, returned by MCP tools, or persisted.
+package redact
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/spec"
+)
+
+// Mask replaces redacted values
+const Mask = "[redacted]"
+
+// ShowSecretsEnv disables redaction of displayed values when "true". It is
+// set by --show-secrets so MCP servers started for the session inherit it.
+const ShowSecretsEnv = "AZD_COPILOT_SHOW_SECRETS"
+
+// KeyPatterns are key globs, matched case-insensitively, whose values are secrets
+var KeyPatterns = []string{
+	"*_KEY",
+	"*PASSWORD*",
+	"*CONNECTION_STRING*",
+	"*SECRET*",
+	"*_TOKEN",
+}
+
+var (
+	// jwtPattern matches JSON Web Tokens (header.payload.signature)
+	jwtPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+	// credentialPattern matches credentials embedded in connection strings
+	credentialPattern = regexp.MustCompile(`(?i)\b(AccountKey|SharedAccessKey|Password|Pwd)=([^;"'\s]+)`)
+	// sasPattern matches the signature of a shared access signature token
+	sasPattern = regexp.MustCompile(`(?i)([?&;]|\b)(sig=)([A-Za-z0-9%+/=]+)`)
+	// assignmentPattern matches KEY=value pairs in free text
+	assignmentPattern = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)=([^\s;"']+)`)
+)
+
+// Redactor applies the built-in rules plus the project's allow and deny lists
+type Redactor struct {
+	// Show disables redaction in Value, Map, and Text for --show-secrets.
+	// IsSecret still reports secrets.
+	Show bool
+
+	allow []string
+	deny  []string
+}
+
+// New returns a redactor with the given project rules
+func New(rules *spec.SecretRules) *Redactor {
+	r := &Redactor{}
+	if rules != nil {
+		r.allow = upperAll(rules.Allow)
+		r.deny = upperAll(rules.Deny)
+	}
+	return r
+}
+
+// Load returns a redactor for the current project, honoring ShowSecretsEnv
+func Load() *Redactor {
+	r := LoadForStorage()
+	r.Show = os.Getenv(ShowSecretsEnv) == "true"
+	return r
+}
+
+// LoadForStorage returns a redactor for the current project that ignores
+// ShowSecretsEnv, for data that is written to disk. When the project's rules
+// cannot be read, every KEY=value is treated as a secret.
+func LoadForStorage() *Redactor {
+	m, err := spec.LoadMetadata()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; redacting all values\n", err)
+		return &Redactor{deny: []string{"*"}}
+	}
+	return New(m.Secrets)
+}
+
+// IsSecret reports whether the value stored under key is a secret
+func (r *Redactor) IsSecret(key, value string) bool {
+	key = strings.ToUpper(key)
+	if matchAny(r.allow, key) {
+		return false
+	}
+	if matchAny(r.deny, key) || matchAny(KeyPatterns, key) {
+		return true
+	}
+	return jwtPattern.MatchString(value) || credentialPattern.MatchString(value) || isSAS(value)
+}
+
+// Value returns value, or Mask when it is a secret
+func (r *Redactor) Value(key, value string) string {
+	if r.Show || value == "" || !r.IsSecret(key, value) {
+		return value
+	}
+	return Mask
+}
+
+// Map returns a copy of values with secrets masked
+func (r *Redactor) Map(values map[string]string) map[string]string {
+	out := make(map[string]string, len(values))
+	for k, v := range values {
+		out[k] = r.Value(k, v)
+	}
+	return out
+}
+
+// Text masks secrets embedded in free text: tokens, connection string
+// credentials, SAS signatures, and KEY=value pairs with secret keys.
+func (r *Redactor) Text(s string) string {
+	if r.Show || s == "" {
+		return s
+	}
+	s = jwtPattern.ReplaceAllString(s, Mask)
+	s = credentialPattern.ReplaceAllString(s, "${1}="+Mask)
+	s = sasPattern.ReplaceAllString(s, "${1}${2}"+Mask)
+	return assignmentPattern.ReplaceAllStringFunc(s, func(pair string) string {
+		key, value, _ := strings.Cut(pair, "=")
+		if value == Mask || !r.IsSecret(key, "") {
+			return pair
+		}
+		return key + "=" + Mask
+	})
+}
+
+// isSAS reports whether value looks like a shared access signature
+func isSAS(value string) bool {
+	lower := strings.ToLower(value)
+	return strings.Contains(lower, "sig=") && (strings.Contains(lower, "sv=") || strings.Contains(lower, "se="))
+}
+
+func matchAny(globs []string, key string) bool {
+	for _, g := range globs {
+		if matched, err := path.Match(g, key); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func upperAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToUpper(v)
+	}
+	return out
+}
@@ -0,0 +1,226 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package setup runs the one-time steps azd copilot needs before launching
+// Copilot CLI and records their results in the setup cache.
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/assets"
+	"github.com/jongio/azd-copilot/cli/src/internal/cache"
+	"github.com/jongio/azd-copilot/cli/src/internal/copilot"
+	"github.com/jongio/azd-copilot/cli/src/internal/mcpconfig"
+)
+
+// Status is the outcome of a setup step
+type Status string
+
+// StatusOK through StatusFail represent step outcomes.
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Step is the result of a single setup step
+type Step struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Result describes a setup run
+type Result struct {
+	Cached      bool                 `json:"cached"`
+	Steps       []Step               `json:"steps,omitempty"`
+	AssetDirs   []string             `json:"assetDirs"`
+	CopilotPath *copilot.CopilotPath `json:"-"`
+	CLIVersion  string               `json:"cliVersion,omitempty"`
+}
+
+// Failed reports whether any step failed
+func (r *Result) Failed() bool {
+	for _, s := range r.Steps {
+		if s.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Options configures a setup run
+type Options struct {
+	Version string // Extension version the cache is keyed by
+	Force   bool   // Ignore the cache and rerun every step
+	Offline bool   // Configure MCP servers to run from the npm cache; skip network steps
+}
+
+// Step implementations are variables so tests can avoid touching the
+// real Copilot CLI, MCP config, and azd extensions.
+var (
+	findCopilot      = copilot.FindCopilotCLI
+	detectVersion    = copilot.DetectCLIVersion
+	configureMCP     = configureMCPServers
+	ensureExtensions = copilot.EnsureExtensionsInstalled
+)
+
+// Run performs setup, returning immediately when the cache shows setup
+// already completed for this extension version and embedded assets.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	agentsDir, err := assets.AgentsDir()
+	if err != nil {
+		return nil, err
+	}
+	skillsDir, err := assets.SkillsDir()
+	if err != nil {
+		return nil, err
+	}
+	assetsHash, err := assets.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	c, _ := cache.Load()
+	if !opts.Force && c != nil && c.Current(opts.Version, assetsHash) && c.MCPOffline == opts.Offline && pathsExist(c.CLIPath, agentsDir, skillsDir) && c.CLIStamp == copilot.CLIStamp(c.CLIPath) {
+		return &Result{
+			Cached:      true,
+			AssetDirs:   []string{agentsDir, skillsDir},
+			CopilotPath: &copilot.CopilotPath{Path: c.CLIPath, IsNode: c.CLIIsNode},
+			CLIVersion:  c.CLIVersion,
+		}, nil
+	}
+
+	c = &cache.SetupCache{Version: opts.Version, AssetsHash: assetsHash, MCPOffline: opts.Offline}
+	result := &Result{}
+
+	// Copilot CLI
+	copilotPath, err := findCopilot()
+	if err != nil {
+		result.add("Copilot CLI", StatusFail, err.Error())
+	} else {
+		result.CopilotPath = copilotPath
+		c.CLIPath = copilotPath.Path
+		c.CLIStamp = copilot.CLIStamp(copilotPath.Path)
+		c.CLIIsNode = copilotPath.IsNode
+		if version, err := detectVersion(ctx, copilotPath); err != nil {
+			result.add("Copilot CLI", StatusWarn, fmt.Sprintf("%s (version unknown: %v)", copilotPath.Path, err))
+		} else {
+			result.CLIVersion = version
+			c.CLIVersion = version
+			result.add("Copilot CLI", StatusOK, fmt.Sprintf("%s (%s)", copilotPath.Path, version))
+		}
+	}
+
+	// MCP servers
+	pinned, err := configureMCP(ctx, opts.Offline)
+	switch {
+	case errors.Is(err, errPinFailed):
+		// The config was written; only pinning failed, so servers run unpinned
+		c.MCPConfigured = true
+		result.add("MCP servers", StatusWarn, err.Error())
+	case err != nil:
+		result.add("MCP servers", StatusFail, err.Error())
+	default:
+		c.MCPConfigured = true
+		detail := fmt.Sprintf("%d required servers configured", len(copilot.RequiredMCPServers()))
+		if len(pinned) > 0 {
+			detail += fmt.Sprintf(", %d pinned", len(pinned))
+		}
+		if opts.Offline {
+			detail += " (offline)"
+		}
+		result.add("MCP servers", StatusOK, detail)
+	}
+
+	// Agents and skills
+	if report, err := assets.InstallAgents(); err != nil {
+		result.add("Agents", StatusFail, err.Error())
+	} else {
+		c.AgentsInstalled = true
+		result.AssetDirs = append(result.AssetDirs, report.Dir)
+		result.add("Agents", StatusOK, fmt.Sprintf("%d installed to %s (%s)", report.Count, report.Dir, report.Summary()))
+	}
+	if report, err := assets.InstallSkills(); err != nil {
+		result.add("Skills", StatusFail, err.Error())
+	} else {
+		c.SkillsInstalled = true
+		result.AssetDirs = append(result.AssetDirs, report.Dir)
+		result.add("Skills", StatusOK, fmt.Sprintf("%d installed to %s (%s)", report.Count, report.Dir, report.Summary()))
+	}
+	// Best effort: the history only feeds 'assets budget' version diffs
+	_ = assets.RecordBudget()
+
+	// azd extensions; a failed install is reported but not retried on every
+	// launch, since it usually means the extension source is not configured
+	c.ExtensionsChecked = true
+	var installed []string
+	if !opts.Offline {
+		installed, err = ensureExtensions(ctx)
+	}
+	switch {
+	case opts.Offline:
+		result.add("Extensions", StatusWarn, "skipped in offline mode")
+	case err != nil:
+		result.add("Extensions", StatusWarn, err.Error())
+	case len(installed) > 0:
+		result.add("Extensions", StatusOK, fmt.Sprintf("installed %s", strings.Join(installed, ", ")))
+	default:
+		result.add("Extensions", StatusOK, "all required extensions installed")
+	}
+
+	if result.CopilotPath != nil {
+		if err := cache.Save(c); err != nil {
+			result.add("Setup cache", StatusWarn, fmt.Sprintf("failed to save: %v", err))
+		}
+	}
+
+	return result, nil
+}
+
+// errPinFailed marks MCP setup errors where the config was written but
+// package versions could not be pinned
+var errPinFailed = errors.New("MCP servers run unpinned")
+
+// configureMCPServers syncs the built-in MCP servers and, when online,
+// pins any unpinned npx packages so sessions are reproducible
+func configureMCPServers(ctx context.Context, offline bool) ([]mcpconfig.LockChange, error) {
+	store, err := mcpconfig.Open()
+	if err != nil {
+		return nil, err
+	}
+	store.Offline = offline
+	if _, err := store.Sync(); err != nil {
+		return nil, err
+	}
+	if offline {
+		return nil, nil
+	}
+
+	pinned, err := store.Pin(ctx, nil, false)
+	if err != nil {
+		return pinned, fmt.Errorf("%w: %v", errPinFailed, err)
+	}
+	return pinned, nil
+}
+
+func (r *Result) add(name string, status Status, detail string) {
+	r.Steps = append(r.Steps, Step{Name: name, Status: status, Detail: detail})
+}
+
+func pathsExist(paths ...string) bool {
+	for _, p := range paths {
+		if p == "" {
+			return false
+		}
+		if _, err := os.Stat(p); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package setup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jongio/azd-copilot/cli/src/internal/copilot"
+	"github.com/jongio/azd-copilot/cli/src/internal/mcpconfig"
+)
+
+// stubSteps replaces external setup steps and counts how often setup ran.
+func stubSteps(t *testing.T, copilotPath string) *int {
+	t.Helper()
+	origFind, origDetect, origMCP, origExt := findCopilot, detectVersion, configureMCP, ensureExtensions
+	t.Cleanup(func() {
+		findCopilot, detectVersion, configureMCP, ensureExtensions = origFind, origDetect, origMCP, origExt
+	})
+
+	runs := 0
+	findCopilot = func() (*copilot.CopilotPath, error) {
+		runs++
+		return &copilot.CopilotPath{Path: copilotPath}, nil
+	}
+	detectVersion = func(ctx context.Context, cp *copilot.CopilotPath) (string, error) {
+		return "0.0.360", nil
+	}
+	configureMCP = func(ctx context.Context, offline bool) ([]mcpconfig.LockChange, error) { return nil, nil }
+	ensureExtensions = func(ctx context.Context) ([]string, error) {
+		return nil, errors.New("extension source not configured")
+	}
+	return &runs
+}
+
+func TestRun(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	copilotPath := filepath.Join(home, "copilot")
+	if err := os.WriteFile(copilotPath, []byte(""), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	runs := stubSteps(t, copilotPath)
+	ctx := context.Background()
+
+	// First run performs every step
+	result, err := Run(ctx, Options{Version: "1.0.0"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Cached {
+		t.Error("first Run() should not be cached")
+	}
+	if result.Failed() {
+		t.Errorf("first Run() failed: %+v", result.Steps)
+	}
+	if len(result.AssetDirs) != 2 {
+		t.Errorf("AssetDirs = %v, want agents and skills", result.AssetDirs)
+	}
+	var extStep *Step
+	for i := range result.Steps {
+		if result.Steps[i].Name == "Extensions" {
+			extStep = &result.Steps[i]
+		}
+	}
+	if extStep == nil || extStep.Status != StatusWarn {
+		t.Errorf("Extensions step = %+v, want warn", extStep)
+	}
+
+	// Second run with the same version uses the cache
+	result, err = Run(ctx, Options{Version: "1.0.0"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.Cached || *runs != 1 {
+		t.Errorf("second Run() cached = %v, runs = %d; want cached with 1 run", result.Cached, *runs)
+	}
+	if result.CopilotPath == nil || result.CopilotPath.Path != copilotPath || result.CLIVersion != "0.0.360" {
+		t.Errorf("cached result = %+v, want recorded Copilot CLI", result)
+	}
+
+	// Force reruns setup
+	if result, _ = Run(ctx, Options{Version: "1.0.0", Force: true}); result.Cached || *runs != 2 {
+		t.Errorf("forced Run() cached = %v, runs = %d; want rerun", result.Cached, *runs)
+	}
+
+	// A version change reruns setup
+	if result, _ = Run(ctx, Options{Version: "1.1.0"}); result.Cached || *runs != 3 {
+		t.Errorf("Run() after upgrade cached = %v, runs = %d; want rerun", result.Cached, *runs)
+	}
+
+	// Switching to offline mode reruns setup
+	if result, _ = Run(ctx, Options{Version: "1.1.0", Offline: true}); result.Cached || *runs != 4 {
+		t.Errorf("Run() in offline mode cached = %v, runs = %d; want rerun", result.Cached, *runs)
+	}
+	if result, _ = Run(ctx, Options{Version: "1.1.0"}); result.Cached || *runs != 5 {
+		t.Errorf("Run() back online cached = %v, runs = %d; want rerun", result.Cached, *runs)
+	}
+
+	// Removing the installed assets reruns setup
+	if err := os.RemoveAll(result.AssetDirs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ = Run(ctx, Options{Version: "1.1.0"}); result.Cached || *runs != 6 {
+		t.Errorf("Run() after removing agents cached = %v, runs = %d; want rerun", result.Cached, *runs)
+	}
+
+	// Upgrading Copilot CLI in place reruns setup
+	if err := os.WriteFile(copilotPath, []byte("upgraded"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ = Run(ctx, Options{Version: "1.1.0"}); result.Cached || *runs != 7 {
+		t.Errorf("Run() after a Copilot CLI upgrade cached = %v, runs = %d; want rerun", result.Cached, *runs)
+	}
+}
+
+func TestResult_Failed(t *testing.T) {
+	r := &Result{Steps: []Step{{Status: StatusOK}, {Status: StatusWarn}}}
+	if r.Failed() {
+		t.Error("Failed() = true with only ok/warn steps")
+	}
+	r.Steps = append(r.Steps, Step{Status: StatusFail})
+	if !r.Failed() {
+		t.Error("Failed() = false with a failed step")
+	}
+}
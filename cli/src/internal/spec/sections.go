@@ -0,0 +1,239 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package spec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Section is a level-2 ("## ") section of the spec. The preamble before the
+// first section has an empty title.
+type Section struct {
+	Title   string `json:"title"`
+	Content string `json:"content"` // Body without the heading line
+}
+
+// RequiredSections must appear in every spec
+var RequiredSections = []string{"Overview", "Services", "Azure Resources", "Cost Estimate"}
+
+// Hash returns a content hash used to detect edits to the spec
+func Hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Parse splits a spec into its preamble and level-2 sections. Headings
+// inside fenced code blocks are ignored.
+func Parse(content string) []Section {
+	var sections []Section
+	current := Section{}
+	var body []string
+	inFence := false
+
+	flush := func() {
+		current.Content = strings.Join(body, "\n")
+		if current.Title != "" || strings.TrimSpace(current.Content) != "" {
+			sections = append(sections, current)
+		}
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if title, ok := sectionTitle(line); ok && !inFence {
+			flush()
+			current = Section{Title: title}
+			body = nil
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+	return sections
+}
+
+// FindSection returns the section with the given title, matched case-insensitively
+func FindSection(content, title string) (Section, bool) {
+	for _, s := range Parse(content) {
+		if s.Title != "" && strings.EqualFold(s.Title, strings.TrimSpace(title)) {
+			return s, true
+		}
+	}
+	return Section{}, false
+}
+
+// ReplaceSection replaces the body of one section, leaving the rest of the
+// spec byte-for-byte unchanged. A section that does not exist is appended.
+// It reports whether the section was added. The body may not start another
+// section or leave a code fence open, since either would change the
+// sections after it.
+func ReplaceSection(content, title, body string) (string, bool, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", false, fmt.Errorf("section title is required")
+	}
+	if strings.Contains(title, "\n") {
+		return "", false, fmt.Errorf("section title must be a single line")
+	}
+	if err := checkSectionBody(body); err != nil {
+		return "", false, err
+	}
+	body = "\n" + strings.Trim(body, "\n") + "\n"
+
+	lines := strings.Split(content, "\n")
+	start, end := -1, len(lines)
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		t, ok := sectionTitle(line)
+		if !ok || inFence {
+			continue
+		}
+		if start >= 0 {
+			end = i
+			break
+		}
+		if strings.EqualFold(t, title) {
+			start = i
+		}
+	}
+
+	if start < 0 {
+		updated := strings.TrimRight(content, "\n") + "\n\n## " + title + "\n" + body
+		return updated, true, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.Join(lines[:start+1], "\n"))
+	sb.WriteString("\n")
+	sb.WriteString(body)
+	if end < len(lines) {
+		sb.WriteString("\n")
+		sb.WriteString(strings.Join(lines[end:], "\n"))
+	}
+	return sb.String(), false, nil
+}
+
+// checkSectionBody rejects level-2 headings outside code fences and
+// unclosed fences in a section body
+func checkSectionBody(body string) error {
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if title, ok := sectionTitle(line); ok && !inFence {
+			return fmt.Errorf("section body must not contain the level-2 heading %q; use ### for subsections", "## "+title)
+		}
+	}
+	if inFence {
+		return fmt.Errorf("section body has an unclosed code fence")
+	}
+	return nil
+}
+
+// Issue is a problem found by Validate
+type Issue struct {
+	Severity string `json:"severity"` // error or warning
+	Section  string `json:"section,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Severity levels for validation issues
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+var placeholderPattern = regexp.MustCompile(`\[(project name|one paragraph|goal \d+|what this won't do|architecture diagram|prototype\|production|date)\]|\$X\.XX`)
+
+// Validate checks a spec for the structure build and the agents rely on
+func Validate(content string) []Issue {
+	var issues []Issue
+	add := func(severity, section, format string, args ...interface{}) {
+		issues = append(issues, Issue{Severity: severity, Section: section, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(content) == "" {
+		add(SeverityError, "", "spec is empty")
+		return issues
+	}
+	if !strings.HasPrefix(strings.TrimSpace(content), "# ") {
+		add(SeverityWarning, "", "spec should start with a level-1 heading")
+	}
+	if strings.Count(content, "```")%2 != 0 {
+		add(SeverityError, "", "unclosed code fence")
+	}
+
+	sections := Parse(content)
+	seen := map[string]bool{}
+	for _, s := range sections {
+		if s.Title == "" {
+			continue
+		}
+		key := strings.ToLower(s.Title)
+		if seen[key] {
+			add(SeverityError, s.Title, "duplicate section")
+		}
+		seen[key] = true
+		if strings.TrimSpace(s.Content) == "" {
+			add(SeverityWarning, s.Title, "section is empty")
+		}
+		if m := placeholderPattern.FindString(s.Content); m != "" {
+			add(SeverityWarning, s.Title, "template placeholder %s was not filled in", m)
+		}
+	}
+	for _, required := range RequiredSections {
+		if !seen[strings.ToLower(required)] {
+			add(SeverityError, required, "required section is missing")
+		}
+	}
+
+	if overview, ok := FindSection(content, "Overview"); ok {
+		if !strings.Contains(overview.Content, "**Name:**") {
+			add(SeverityWarning, overview.Title, "missing **Name:**")
+		}
+		mode := fieldValue(overview.Content, "**Mode:**")
+		if mode == "" {
+			add(SeverityWarning, overview.Title, "missing **Mode:**")
+		} else if mode != "prototype" && mode != "production" {
+			add(SeverityError, overview.Title, "mode %q must be prototype or production", mode)
+		}
+	}
+	return issues
+}
+
+// HasErrors reports whether any issue is an error
+func HasErrors(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func sectionTitle(line string) (string, bool) {
+	title, ok := strings.CutPrefix(strings.TrimRight(line, "\r"), "## ")
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSpace(title), true
+}
+
+func fieldValue(content, label string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), label); ok {
+			return strings.ToLower(strings.TrimSpace(v))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,181 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package spec
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleSpec = `# Application Specification
+
+> Generated by Azure Copilot CLI on 2026-01-01 10:00
+
+## Overview
+
+**Name:** inventory
+**Mode:** prototype
+
+## Architecture
+
+` + "```mermaid\ngraph TB\n## not a heading\n```" + `
+
+## Services
+
+| Service | Type |
+|---------|------|
+| api | api |
+
+## Azure Resources
+
+| Resource | Type |
+|----------|------|
+| app | Container App |
+
+## Cost Estimate
+
+Hand-edited by the user.
+`
+
+func TestParse(t *testing.T) {
+	sections := Parse(sampleSpec)
+	var titles []string
+	for _, s := range sections {
+		titles = append(titles, s.Title)
+	}
+	want := ",Overview,Architecture,Services,Azure Resources,Cost Estimate"
+	if got := strings.Join(titles, ","); got != want {
+		t.Errorf("Parse() titles = %q, want %q", got, want)
+	}
+
+	arch, ok := FindSection(sampleSpec, "architecture")
+	if !ok || !strings.Contains(arch.Content, "## not a heading") {
+		t.Errorf("headings in code fences should stay in the section: %+v", arch)
+	}
+}
+
+func TestReplaceSection(t *testing.T) {
+	updated, added, err := ReplaceSection(sampleSpec, "azure resources", "| Resource | Type |\n|---|---|\n| db | Cosmos DB |")
+	if err != nil {
+		t.Fatalf("ReplaceSection() error = %v", err)
+	}
+	if added {
+		t.Error("existing section reported as added")
+	}
+	if !strings.Contains(updated, "| db | Cosmos DB |") || strings.Contains(updated, "Container App") {
+		t.Errorf("section not replaced:\n%s", updated)
+	}
+	// Every other section is untouched
+	for _, title := range []string{"Overview", "Architecture", "Services", "Cost Estimate"} {
+		before, _ := FindSection(sampleSpec, title)
+		after, _ := FindSection(updated, title)
+		if before.Content != after.Content {
+			t.Errorf("%s changed:\n%q\n%q", title, before.Content, after.Content)
+		}
+	}
+
+	updated, added, err = ReplaceSection(sampleSpec, "Risks", "- vendor lock-in")
+	if err != nil || !added {
+		t.Fatalf("ReplaceSection() of a new section = added %v, err %v", added, err)
+	}
+	if !strings.HasSuffix(updated, "## Risks\n\n- vendor lock-in\n") {
+		t.Errorf("new section not appended:\n%s", updated)
+	}
+
+	if _, _, err := ReplaceSection(sampleSpec, " ", "x"); err == nil {
+		t.Error("ReplaceSection() with an empty title should fail")
+	}
+}
+
+func TestReplaceSection_RejectsHeadings(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"subsection", "### Storage\n- blob", false},
+		{"heading in a code fence", "```markdown\n## Example\n```", false},
+		{"level-2 heading", "- db\n\n## Injected\n- more", true},
+		{"unclosed fence", "```bash\nazd up", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, _, err := ReplaceSection(sampleSpec, "Services", tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReplaceSection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(Parse(updated)) != len(Parse(sampleSpec)) {
+				t.Errorf("ReplaceSection() changed the number of sections:\n%s", updated)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errors  bool
+		message string
+	}{
+		{"valid", sampleSpec, false, ""},
+		{"empty", "", true, "spec is empty"},
+		{"missing section", strings.Replace(sampleSpec, "## Services", "## Components", 1), true, "required section is missing"},
+		{"bad mode", strings.Replace(sampleSpec, "prototype", "demo", 1), true, "must be prototype or production"},
+		{"duplicate", sampleSpec + "\n## Services\n\nagain\n", true, "duplicate section"},
+		{"placeholder", strings.Replace(sampleSpec, "inventory", "[project name]", 1), false, "placeholder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := Validate(tt.content)
+			if HasErrors(issues) != tt.errors {
+				t.Errorf("HasErrors() = %v, want %v: %+v", HasErrors(issues), tt.errors, issues)
+			}
+			if tt.message == "" {
+				if len(issues) > 0 {
+					t.Errorf("Validate() = %+v, want no issues", issues)
+				}
+				return
+			}
+			found := false
+			for _, i := range issues {
+				found = found || strings.Contains(i.Message, tt.message)
+			}
+			if !found {
+				t.Errorf("Validate() = %+v, want an issue containing %q", issues, tt.message)
+			}
+		})
+	}
+}
+
+func TestStatAndApprove(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	info, err := Stat()
+	if err != nil || info.Exists {
+		t.Fatalf("Stat() without a spec = %+v, %v", info, err)
+	}
+
+	if err := Write(sampleSpec); err != nil {
+		t.Fatal(err)
+	}
+	if err := Approve(sampleSpec); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	info, err = Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Exists || !info.Approved || info.Hash != Hash(sampleSpec) || len(info.Sections) != 5 {
+		t.Errorf("Stat() = %+v, want an approved spec with 5 sections", info)
+	}
+
+	// Editing the spec clears approval until it is approved again
+	if err := Write(sampleSpec + "\nmore\n"); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ = Stat(); info.Approved {
+		t.Error("edited spec should not be reported as approved")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -26,9 +27,43 @@ const (
 
 // Metadata tracks locations of copilot-generated files
 type Metadata struct {
-	SpecFile       string   `json:"specFile"`
-	CheckpointDir  string   `json:"checkpointDir"`
-	GeneratedFiles []string `json:"generatedFiles,omitempty"`
+	SpecFile       string        `json:"specFile"`
+	CheckpointDir  string        `json:"checkpointDir"`
+	GeneratedFiles []string      `json:"generatedFiles,omitempty"`
+	ApprovedHash   string        `json:"approvedSpecHash,omitempty"` // Hash of the spec when the build was last approved
+	AuditFile      string        `json:"auditFile,omitempty"`        // Audit log location; defaults to docs/.copilot-audit.jsonl
+	MCP            *MCPSettings  `json:"mcp,omitempty"`
+	Secrets        *SecretRules  `json:"secrets,omitempty"`
+	Budget         *BudgetLimits `json:"budget,omitempty"`
+	Agents         *AssetToggles `json:"agents,omitempty"`
+	Skills         *AssetToggles `json:"skills,omitempty"`
+}
+
+// AssetToggles turns agents or skills off for the project. Disabled assets
+// are left out of the view sessions load.
+type AssetToggles struct {
+	Disable []string `json:"disable,omitempty"`
+}
+
+// BudgetLimits override the context budget thresholds for the project.
+// Zero values keep the defaults.
+type BudgetLimits struct {
+	FileTokens  int `json:"fileTokens,omitempty"`  // Largest single agent, skill, or reference file
+	AgentTokens int `json:"agentTokens,omitempty"` // Total loaded for one agent, including its skills
+}
+
+// SecretRules adjust secret detection for the project. Entries are key
+// globs such as "AZURE_*_ENDPOINT"; allow takes precedence over deny.
+type SecretRules struct {
+	Allow []string `json:"allow,omitempty"` // Keys never treated as secrets
+	Deny  []string `json:"deny,omitempty"`  // Keys always treated as secrets
+}
+
+// MCPSettings declares project-scoped MCP servers. They are added to the
+// user's global servers for sessions started in this project only.
+type MCPSettings struct {
+	Servers map[string]json.RawMessage `json:"servers,omitempty"` // Extra servers, same shape as mcp-config.json entries
+	Disable []string                   `json:"disable,omitempty"` // Global servers not loaded in this project
 }
 
 // DefaultMetadata returns the default metadata configuration
@@ -39,17 +74,53 @@ func DefaultMetadata() *Metadata {
 	}
 }
 
-// LoadMetadata loads metadata from the workspace root
+// LoadMetadata loads metadata from the workspace root. Fields the file
+// omits keep their defaults. When the file cannot be read or parsed the
+// defaults are returned with the error, so callers that only read settings
+// can carry on; callers that save must not.
 func LoadMetadata() (*Metadata, error) {
-	data, err := os.ReadFile(MetadataFile)
+	return LoadMetadataIn(".")
+}
+
+// LoadMetadataIn loads metadata from the project rooted at dir, like
+// LoadMetadata
+func LoadMetadataIn(dir string) (*Metadata, error) {
+	path := filepath.Join(dir, MetadataFile)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: metadata file in the project root
+	if os.IsNotExist(err) {
+		return DefaultMetadata(), nil
+	}
 	if err != nil {
-		return DefaultMetadata(), nil //nolint:nilerr // return defaults if metadata file not found
+		return DefaultMetadata(), fmt.Errorf("failed to read %s: %w", path, err)
 	}
-	var m Metadata
-	if err := json.Unmarshal(data, &m); err != nil {
-		return DefaultMetadata(), nil //nolint:nilerr // return defaults if metadata cannot be parsed
+	m := DefaultMetadata()
+	if err := json.Unmarshal(data, m); err != nil {
+		return DefaultMetadata(), fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// ProjectRoot returns the nearest directory at or above the working
+// directory that holds azure.yaml or the metadata file, relative to the
+// working directory. ok is false outside a project.
+func ProjectRoot() (root string, ok bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	root = "."
+	for {
+		for _, marker := range []string{"azure.yaml", MetadataFile} {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return root, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir, root = parent, filepath.Join(root, "..")
 	}
-	return &m, nil
 }
 
 // SaveMetadata saves metadata to the workspace root
@@ -124,6 +195,76 @@ func Read() (string, error) {
 	return string(content), nil
 }
 
+// Info summarizes the spec file and its approval state
+type Info struct {
+	Path         string     `json:"path"`
+	Exists       bool       `json:"exists"`
+	Hash         string     `json:"hash,omitempty"`
+	ApprovedHash string     `json:"approvedHash,omitempty"`
+	Approved     bool       `json:"approved"` // The spec is unchanged since it was approved
+	Sections     []string   `json:"sections,omitempty"`
+	ModTime      *time.Time `json:"modTime,omitempty"`
+}
+
+// Stat returns the spec path, content hash, and last approved hash
+func Stat() (*Info, error) {
+	m, err := LoadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{Path: m.SpecFile, ApprovedHash: m.ApprovedHash}
+
+	fi, err := os.Stat(m.SpecFile)
+	if os.IsNotExist(err) {
+		return info, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat spec: %w", err)
+	}
+	content, err := Read()
+	if err != nil {
+		return nil, err
+	}
+
+	info.Exists = true
+	modTime := fi.ModTime().UTC()
+	info.ModTime = &modTime
+	info.Hash = Hash(content)
+	info.Approved = info.ApprovedHash != "" && info.ApprovedHash == info.Hash
+	for _, sec := range Parse(content) {
+		if sec.Title != "" {
+			info.Sections = append(info.Sections, sec.Title)
+		}
+	}
+	return info, nil
+}
+
+// Approve records the hash of the given spec content as approved
+func Approve(content string) error {
+	m, err := LoadMetadata()
+	if err != nil {
+		return err
+	}
+	m.ApprovedHash = Hash(content)
+	return SaveMetadata(m)
+}
+
+// AddGeneratedFiles records files generated for the project, keeping the
+// list sorted and free of duplicates
+func AddGeneratedFiles(files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	m, err := LoadMetadata()
+	if err != nil {
+		return err
+	}
+	m.GeneratedFiles = append(m.GeneratedFiles, files...)
+	slices.Sort(m.GeneratedFiles)
+	m.GeneratedFiles = slices.Compact(m.GeneratedFiles)
+	return SaveMetadata(m)
+}
+
 // OpenInEditor opens the spec file in the default editor
 func OpenInEditor() error {
 	return editor.Open(GetSpecPath())
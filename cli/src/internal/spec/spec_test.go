@@ -6,6 +6,7 @@ package spec
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -55,6 +56,49 @@ func TestLoadMetadata_NotFound(t *testing.T) {
 	}
 }
 
+func TestLoadMetadata_Partial(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(MetadataFile, []byte(`{"approvedSpecHash": "abc"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadMetadata()
+	if err != nil {
+		t.Fatalf("LoadMetadata() error = %v", err)
+	}
+	defaults := DefaultMetadata()
+	if m.ApprovedHash != "abc" || m.SpecFile != defaults.SpecFile || m.CheckpointDir != defaults.CheckpointDir {
+		t.Errorf("LoadMetadata() = %+v, want the approved hash with default paths", m)
+	}
+}
+
+func TestLoadMetadata_Malformed(t *testing.T) {
+	t.Chdir(t.TempDir())
+	malformed := []byte(`{"specFile": "custom.md",`)
+	if err := os.WriteFile(MetadataFile, malformed, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadMetadata()
+	if err == nil {
+		t.Fatal("LoadMetadata() error = nil, want a parse error")
+	}
+	if m == nil || m.SpecFile != DefaultMetadata().SpecFile {
+		t.Errorf("LoadMetadata() = %+v, want defaults alongside the error", m)
+	}
+
+	// Writers refuse to replace a file they could not parse
+	if err := Approve("# Spec"); err == nil {
+		t.Error("Approve() error = nil, want the parse error")
+	}
+	if err := AddGeneratedFiles([]string{"azure.yaml"}); err == nil {
+		t.Error("AddGeneratedFiles() error = nil, want the parse error")
+	}
+	if data, _ := os.ReadFile(MetadataFile); string(data) != string(malformed) {
+		t.Errorf("%s was overwritten: %s", MetadataFile, data)
+	}
+}
+
 func TestGetSpecPath(t *testing.T) {
 	path := GetSpecPath()
 
@@ -368,3 +412,49 @@ func TestMetadata_Fields(t *testing.T) {
 		t.Errorf("Metadata.GeneratedFiles length = %d, want 2", len(m.GeneratedFiles))
 	}
 }
+
+func TestSaveMetadata_PreservesMCP(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if err := os.WriteFile(MetadataFile, []byte(`{
+  "specFile": "docs/spec.md",
+  "checkpointDir": "docs/checkpoints",
+  "mcp": {
+    "servers": {"docs": {"type": "http", "url": "https://docs.internal/mcp"}},
+    "disable": ["playwright"]
+  }
+}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadMetadata()
+	if err != nil {
+		t.Fatalf("LoadMetadata() error = %v", err)
+	}
+	m.GeneratedFiles = append(m.GeneratedFiles, "main.go")
+	if err := SaveMetadata(m); err != nil {
+		t.Fatalf("SaveMetadata() error = %v", err)
+	}
+
+	m, err = LoadMetadata()
+	if err != nil {
+		t.Fatalf("LoadMetadata() error = %v", err)
+	}
+	if m.MCP == nil || len(m.MCP.Servers) != 1 || len(m.MCP.Disable) != 1 {
+		t.Errorf("MCP settings not preserved: %+v", m.MCP)
+	}
+}
+
+func TestAddGeneratedFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	for _, files := range [][]string{{"src/b.go", "azure.yaml"}, {"src/a.go", "azure.yaml"}, nil} {
+		if err := AddGeneratedFiles(files); err != nil {
+			t.Fatalf("AddGeneratedFiles(%v) error = %v", files, err)
+		}
+	}
+	m, _ := LoadMetadata()
+	if want := []string{"azure.yaml", "src/a.go", "src/b.go"}; !slices.Equal(m.GeneratedFiles, want) {
+		t.Errorf("GeneratedFiles = %v, want %v", m.GeneratedFiles, want)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package stack detects a project's technology stack from azure.yaml,
+// Bicep files, and dependency manifests.
+package stack
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Signal kinds
+const (
+	KindHost       = "host"       // azure.yaml service host, e.g. "function"
+	KindLanguage   = "language"   // azure.yaml service language, e.g. "python"
+	KindResource   = "resource"   // Bicep resource type or AVM module path
+	KindDependency = "dependency" // package.json, go.mod, or requirements.txt dependency
+)
+
+// MaxDepth limits how deep Detect looks for Bicep and dependency files
+var MaxDepth = 4
+
+// skipDirs are never searched
+var skipDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, ".venv": true, "venv": true,
+	"__pycache__": true, "bin": true, "obj": true, "dist": true, "build": true, ".azure": true,
+}
+
+var (
+	// resourcePattern matches Bicep resource declarations: resource x 'Type@version'
+	resourcePattern = regexp.MustCompile(`^\s*resource\s+\w+\s+'([A-Za-z0-9.]+/[A-Za-z0-9./]+)@`)
+	// modulePattern matches public registry modules: module x 'br/public:avm/res/...:version'
+	modulePattern = regexp.MustCompile(`^\s*module\s+\w+\s+'br/public:([A-Za-z0-9./-]+):`)
+	// requirementPattern captures the package name of a requirements.txt line
+	requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)`)
+)
+
+// Signal is one fact about the stack and where it was found
+type Signal struct {
+	Kind   string `json:"kind"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // File relative to the project root
+}
+
+// Stack is the detected technology stack of a project
+type Stack struct {
+	Root         string   `json:"root"`
+	HasAzureYAML bool     `json:"hasAzureYaml"`
+	Signals      []Signal `json:"signals"`
+}
+
+// Values returns the distinct values of one kind of signal, sorted
+func (s *Stack) Values(kind string) []string {
+	seen := map[string]bool{}
+	var values []string
+	for _, sig := range s.Signals {
+		if sig.Kind == kind && !seen[sig.Value] {
+			seen[sig.Value] = true
+			values = append(values, sig.Value)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// Detect inspects the project at root. root "" means the current directory.
+func Detect(root string) (*Stack, error) {
+	if root == "" {
+		root = "."
+	}
+	s := &Stack{Root: root}
+	if err := s.readAzureYAML(); err != nil {
+		return nil, err
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable entries are skipped
+		}
+		rel, _ := filepath.Rel(root, path)
+		if d.IsDir() {
+			if path != root && (skipDirs[d.Name()] || strings.Count(filepath.ToSlash(rel), "/") >= MaxDepth) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case strings.HasSuffix(d.Name(), ".bicep"):
+			s.readBicep(path, rel)
+		case d.Name() == "package.json":
+			s.readPackageJSON(path, rel)
+		case d.Name() == "go.mod":
+			s.readGoMod(path, rel)
+		case d.Name() == "requirements.txt":
+			s.readRequirements(path, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan project: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Stack) add(kind, value, source string) {
+	if value != "" {
+		s.Signals = append(s.Signals, Signal{Kind: kind, Value: value, Source: source})
+	}
+}
+
+func (s *Stack) readAzureYAML() error {
+	data, err := os.ReadFile(filepath.Join(s.Root, "azure.yaml")) //nolint:gosec // G304: azure.yaml in the project root
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read azure.yaml: %w", err)
+	}
+	s.HasAzureYAML = true
+
+	var project struct {
+		Services map[string]struct {
+			Host     string `yaml:"host"`
+			Language string `yaml:"language"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		return nil // A malformed azure.yaml still marks an azd project
+	}
+	for _, name := range sortedKeys(project.Services) {
+		svc := project.Services[name]
+		s.add(KindHost, strings.ToLower(svc.Host), "azure.yaml")
+		s.add(KindLanguage, strings.ToLower(svc.Language), "azure.yaml")
+	}
+	return nil
+}
+
+func (s *Stack) readBicep(path, rel string) {
+	s.scanLines(path, func(line string) {
+		if m := resourcePattern.FindStringSubmatch(line); m != nil {
+			s.add(KindResource, m[1], rel)
+		} else if m := modulePattern.FindStringSubmatch(line); m != nil {
+			s.add(KindResource, m[1], rel)
+		}
+	})
+}
+
+func (s *Stack) readPackageJSON(path, rel string) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: file found while scanning the project
+	if err != nil {
+		return
+	}
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return
+	}
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies} {
+		for _, name := range sortedKeys(deps) {
+			s.add(KindDependency, name, rel)
+		}
+	}
+}
+
+func (s *Stack) readGoMod(path, rel string) {
+	inRequire := false
+	s.scanLines(path, func(line string) {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "require ("):
+			inRequire = true
+		case inRequire && line == ")":
+			inRequire = false
+		case strings.HasPrefix(line, "require "):
+			if fields := strings.Fields(strings.TrimPrefix(line, "require ")); len(fields) > 0 {
+				s.add(KindDependency, fields[0], rel)
+			}
+		case inRequire && line != "" && !strings.HasPrefix(line, "//"):
+			s.add(KindDependency, strings.Fields(line)[0], rel)
+		}
+	})
+}
+
+func (s *Stack) readRequirements(path, rel string) {
+	s.scanLines(path, func(line string) {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			return
+		}
+		if m := requirementPattern.FindStringSubmatch(line); m != nil {
+			s.add(KindDependency, strings.ToLower(m[1]), rel)
+		}
+	})
+}
+
+func (s *Stack) scanLines(path string, fn func(line string)) {
+	f, err := os.Open(path) //nolint:gosec // G304: file found while scanning the project
+	if err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package stack
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "azure.yaml"), "name: demo\nservices:\n  api:\n    host: function\n    language: python\n  web:\n    host: staticwebapp\n    language: ts\n")
+	writeFile(t, filepath.Join(root, "infra", "main.bicep"), "resource cosmos 'Microsoft.DocumentDB/databaseAccounts@2024-05-15' = {}\nmodule kv 'br/public:avm/res/key-vault/vault:0.11.0' = {}\n")
+	writeFile(t, filepath.Join(root, "api", "requirements.txt"), "# deps\nazure-functions==1.18\nAzure-Cosmos>=4.5\n-r common.txt\n")
+	writeFile(t, filepath.Join(root, "web", "package.json"), `{"dependencies":{"@azure/identity":"^4"},"devDependencies":{"vite":"^5"}}`)
+	writeFile(t, filepath.Join(root, "worker", "go.mod"), "module example.com/worker\n\ngo 1.22\n\nrequire github.com/Azure/azure-sdk-for-go/sdk/azidentity v1.5.0\n\nrequire (\n\tgithub.com/jackc/pgx/v5 v5.5.0\n\t// indirect below\n\tgolang.org/x/net v0.20.0 // indirect\n)\n")
+	// Dependencies of vendored code are ignored
+	writeFile(t, filepath.Join(root, "web", "node_modules", "x", "package.json"), `{"dependencies":{"left-pad":"1"}}`)
+
+	s, err := Detect(root)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if !s.HasAzureYAML {
+		t.Error("HasAzureYAML = false")
+	}
+
+	tests := []struct {
+		kind string
+		want []string
+	}{
+		{KindHost, []string{"function", "staticwebapp"}},
+		{KindLanguage, []string{"python", "ts"}},
+		{KindResource, []string{"Microsoft.DocumentDB/databaseAccounts", "avm/res/key-vault/vault"}},
+		{KindDependency, []string{
+			"@azure/identity", "azure-cosmos", "azure-functions",
+			"github.com/Azure/azure-sdk-for-go/sdk/azidentity", "github.com/jackc/pgx/v5", "golang.org/x/net", "vite",
+		}},
+	}
+	for _, tt := range tests {
+		if got := s.Values(tt.kind); !slices.Equal(got, tt.want) {
+			t.Errorf("Values(%s) = %v, want %v", tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestDetect_NoProject(t *testing.T) {
+	s, err := Detect(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.HasAzureYAML || len(s.Signals) != 0 {
+		t.Errorf("Detect() on an empty directory = %+v", s)
+	}
+}